@@ -0,0 +1,95 @@
+package catalog
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+// Author 解析后的作者信息
+type Author struct {
+	Name  string
+	Email string
+}
+
+// String 返回作者的规范化表示，形如 "Name <email>"
+func (a Author) String() string {
+	switch {
+	case a.Email == "":
+		return a.Name
+	case a.Name == "":
+		return "<" + a.Email + ">"
+	default:
+		return a.Name + " <" + a.Email + ">"
+	}
+}
+
+// ParseAuthor 解析作者字段，支持以下格式：
+//
+//	"Jane Doe <jane@x.com>"
+//	"jane@x.com"
+//	"Jane Doe"
+//
+// 名称中的多余空白会被合并，邮箱统一转为小写。邮箱存在但格式不合法时返回错误
+func ParseAuthor(raw string) (Author, error) {
+	raw = strings.TrimSpace(raw)
+
+	var name, email string
+	if start := strings.LastIndex(raw, "<"); start >= 0 && strings.HasSuffix(raw, ">") {
+		name = raw[:start]
+		email = raw[start+1 : len(raw)-1]
+	} else if strings.Contains(raw, "@") && !strings.ContainsAny(raw, " \t") {
+		email = raw
+	} else {
+		name = raw
+	}
+
+	author := Author{
+		Name:  strings.Join(strings.Fields(name), " "),
+		Email: strings.ToLower(strings.TrimSpace(email)),
+	}
+	if author.Email != "" {
+		if err := validateEmail(author.Email); err != nil {
+			return author, fmt.Errorf("invalid author email in %q: %w", raw, err)
+		}
+	}
+	return author, nil
+}
+
+// ParseAuthors 解析以逗号分隔的多位作者，空项会被忽略
+// 例如 "Jane Doe <jane@x.com>, John Roe" 返回两位作者
+func ParseAuthors(raw string) ([]Author, error) {
+	var authors []Author
+	for _, part := range strings.Split(raw, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		author, err := ParseAuthor(part)
+		if err != nil {
+			return nil, err
+		}
+		authors = append(authors, author)
+	}
+	return authors, nil
+}
+
+// NormalizeAuthor 返回作者字段的规范化形式，无法解析时退化为去除首尾空白的原值
+func NormalizeAuthor(raw string) string {
+	author, err := ParseAuthor(raw)
+	if err != nil {
+		return strings.TrimSpace(raw)
+	}
+	return author.String()
+}
+
+// validateEmail 校验邮箱格式，仅接受不带显示名的纯地址
+func validateEmail(email string) error {
+	addr, err := mail.ParseAddress(email)
+	if err != nil {
+		return err
+	}
+	if addr.Name != "" || addr.Address != email {
+		return fmt.Errorf("unexpected address form %q", email)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package catalog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAuthor(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want Author
+	}{
+		{"Jane Doe", Author{Name: "Jane Doe"}},
+		{"  Jane   Doe ", Author{Name: "Jane Doe"}},
+		{"Jane Doe <jane@x.com>", Author{Name: "Jane Doe", Email: "jane@x.com"}},
+		{"Jane Doe <Jane@X.com>", Author{Name: "Jane Doe", Email: "jane@x.com"}},
+		{"jane@x.com", Author{Email: "jane@x.com"}},
+		{"<jane@x.com>", Author{Email: "jane@x.com"}},
+		{"", Author{}},
+		{"   ", Author{}},
+	}
+	for _, tt := range tests {
+		got, err := ParseAuthor(tt.raw)
+		if err != nil {
+			t.Errorf("ParseAuthor(%q) failed: %v", tt.raw, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseAuthor(%q) = %+v, want %+v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestParseAuthorInvalidEmail(t *testing.T) {
+	for _, raw := range []string{"Jane <not an email>", "Jane <jane@>", "<@x.com>"} {
+		if _, err := ParseAuthor(raw); err == nil {
+			t.Errorf("ParseAuthor(%q) succeeded, want an invalid email error", raw)
+		}
+	}
+}
+
+func TestAuthorString(t *testing.T) {
+	tests := []struct {
+		author Author
+		want   string
+	}{
+		{Author{Name: "Jane Doe"}, "Jane Doe"},
+		{Author{Name: "Jane Doe", Email: "jane@x.com"}, "Jane Doe <jane@x.com>"},
+		{Author{Email: "jane@x.com"}, "<jane@x.com>"},
+		{Author{}, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.author.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.author, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeAuthor(t *testing.T) {
+	tests := []struct{ raw, want string }{
+		{"Jane   Doe", "Jane Doe"},
+		{" Jane Doe <JANE@x.com> ", "Jane Doe <jane@x.com>"},
+		{"jane@x.com", "<jane@x.com>"},
+		{"", ""},
+		// 无法解析时保留去除首尾空白的原值
+		{" Jane <not an email> ", "Jane <not an email>"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeAuthor(tt.raw); got != tt.want {
+			t.Errorf("NormalizeAuthor(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestParseAuthors(t *testing.T) {
+	got, err := ParseAuthors("Jane Doe <jane@x.com>, John Roe,, bob@x.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Author{{Name: "Jane Doe", Email: "jane@x.com"}, {Name: "John Roe"}, {Email: "bob@x.com"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseAuthors = %+v, want %+v", got, want)
+	}
+	if got, err := ParseAuthors(""); err != nil || len(got) != 0 {
+		t.Errorf("ParseAuthors(\"\") = %+v, %v, want no authors", got, err)
+	}
+}
@@ -0,0 +1,101 @@
+// Package catalog 定义生成的适配器目录条目及其辅助处理逻辑
+package catalog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/meloshub/meloshub/adapter"
+)
+
+// Entry 适配器目录中的一个条目
+// 在 adapter.Metadata 的基础上附加了由工具派生的字段，派生字段为空时不会输出
+type Entry struct {
+	adapter.Metadata `yaml:",inline"`
+
+	// Capabilities 适配器声明的功能开关
+	Capabilities map[string]bool `json:"capabilities,omitempty" yaml:"capabilities,omitempty"`
+	// Titles 本地化标题，键为语言代码，如 en、zh
+	Titles map[string]string `json:"titles,omitempty" yaml:"titles,omitempty"`
+
+	// AuthorName 规范化后的作者名称
+	AuthorName string `json:"author_name,omitempty" yaml:"author_name,omitempty"`
+	// AuthorEmail 规范化后的作者邮箱
+	AuthorEmail string `json:"author_email,omitempty" yaml:"author_email,omitempty"`
+	// ShortDescription 截断为指定长度的描述，供无法显示完整描述的界面使用，见 TruncateDescriptions
+	ShortDescription string `json:"short_description,omitempty" yaml:"short_description,omitempty"`
+}
+
+// NewEntry 根据扫描得到的条目填充派生字段
+func NewEntry(entry Entry) (Entry, error) {
+	author, err := ParseAuthor(entry.Author)
+	if err != nil {
+		return entry, err
+	}
+	entry.AuthorName = author.Name
+	entry.AuthorEmail = author.Email
+
+	return entry, nil
+}
+
+// Options 控制从扫描结果构建条目时的校验行为
+type Options struct {
+	// RequireAuthorEmail 要求每个适配器的作者字段都包含可解析的邮箱
+	RequireAuthorEmail bool
+}
+
+// NewEntries 批量填充扫描得到的条目的派生字段，并按 opts 进行校验
+func NewEntries(scanned []Entry, opts Options) ([]Entry, error) {
+	entries := make([]Entry, 0, len(scanned))
+	for _, raw := range scanned {
+		entry, err := NewEntry(raw)
+		if err != nil {
+			return nil, fmt.Errorf("adapter %s: %w", raw.Id, err)
+		}
+		if opts.RequireAuthorEmail && entry.AuthorEmail == "" {
+			return nil, fmt.Errorf("adapter %s: no parseable author email in %q", raw.Id, raw.Author)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// SortById 按 Id 对条目排序
+func SortById(entries []Entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Id < entries[j].Id
+	})
+}
+
+// CheckIdConflicts 检查条目中是否存在重复的 Id；ignoreCase 为 true 时只有大小写不同的 Id
+// （如 spotify 与 Spotify）同样视为冲突，因为它们在不区分大小写的文件系统或注册表中无法共存
+func CheckIdConflicts(entries []Entry, ignoreCase bool) error {
+	seen := make(map[string]string)
+	for _, entry := range entries {
+		key := IdKey(entry.Id, ignoreCase)
+		if first, ok := seen[key]; ok {
+			if first != entry.Id {
+				return fmt.Errorf("adapter Ids '%s' and '%s' found in the current scan differ only in case", first, entry.Id)
+			}
+			return fmt.Errorf("duplicate adapter Id '%s' found in the current scan", entry.Id)
+		}
+		seen[key] = entry.Id
+	}
+	return nil
+}
+
+// IdKey 返回用于比较 Id 的键，ignoreCase 为 true 时忽略大小写
+func IdKey(id string, ignoreCase bool) string {
+	if ignoreCase {
+		return strings.ToLower(id)
+	}
+	return id
+}
+
+// ReadFile 读取并解析元数据 YAML 文件，平铺列表与信封格式均可，见 ReadFileMeta
+// 文件不存在时返回的错误满足 errors.Is(err, os.ErrNotExist)
+func ReadFile(path string) ([]Entry, error) {
+	entries, _, err := ReadFileMeta(path)
+	return entries, err
+}
@@ -0,0 +1,55 @@
+package catalog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/meloshub/meloshub/adapter"
+)
+
+func idsEntries(ids ...string) []Entry {
+	entries := make([]Entry, 0, len(ids))
+	for _, id := range ids {
+		entries = append(entries, Entry{Metadata: adapter.Metadata{Id: id}})
+	}
+	return entries
+}
+
+func TestCheckIdConflicts(t *testing.T) {
+	tests := []struct {
+		name       string
+		ids        []string
+		ignoreCase bool
+		// wantErr 错误信息中应包含的内容，为空表示没有冲突
+		wantErr string
+	}{
+		{"distinct", []string{"spotify", "deezer"}, false, ""},
+		{"distinct ignoring case", []string{"spotify", "deezer"}, true, ""},
+		{"exact duplicate", []string{"spotify", "spotify"}, false, "duplicate adapter Id 'spotify'"},
+		{"exact duplicate ignoring case", []string{"spotify", "spotify"}, true, "duplicate adapter Id 'spotify'"},
+		{"case differs", []string{"Spotify", "spotify"}, false, ""},
+		{"case differs ignoring case", []string{"Spotify", "deezer", "spotify"}, true, "adapter Ids 'Spotify' and 'spotify' found in the current scan differ only in case"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckIdConflicts(idsEntries(tt.ids...), tt.ignoreCase)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("unexpected conflict: %v", err)
+			case tt.wantErr != "" && err == nil:
+				t.Errorf("no conflict reported, want %q", tt.wantErr)
+			case tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr):
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIdKey(t *testing.T) {
+	if got := IdKey("Spotify", false); got != "Spotify" {
+		t.Errorf("IdKey(Spotify, false) = %q", got)
+	}
+	if got := IdKey("Spotify", true); got != "spotify" {
+		t.Errorf("IdKey(Spotify, true) = %q", got)
+	}
+}
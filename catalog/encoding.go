@@ -0,0 +1,70 @@
+package catalog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// 文本输出的换行符
+const (
+	LineEndingsLF   = "lf"
+	LineEndingsCRLF = "crlf"
+)
+
+// utf8BOM UTF-8 字节顺序标记
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// TextEncoding 文本格式输出的换行符与 BOM，零值为 LF 且不带 BOM
+type TextEncoding struct {
+	CRLF bool
+	BOM  bool
+}
+
+// ParseLineEndings 解析 lf 或 crlf，返回是否使用 CRLF
+func ParseLineEndings(name string) (bool, error) {
+	switch name {
+	case LineEndingsLF:
+		return false, nil
+	case LineEndingsCRLF:
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown line endings %q (expected %s or %s)", name, LineEndingsLF, LineEndingsCRLF)
+	}
+}
+
+// IsDefault 报告是否为默认的 LF 且不带 BOM
+func (e TextEncoding) IsDefault() bool {
+	return !e.CRLF && !e.BOM
+}
+
+// Writer 返回按 e 转换输出的 Writer：需要时先写入 BOM，并将每个 \n 替换为 \r\n
+// 各文本格式中的换行只出现在行尾或 YAML 块标量中，解析时都会还原为 \n，因此转换不改变读回的内容
+func (e TextEncoding) Writer(w io.Writer) (io.Writer, error) {
+	if e.BOM {
+		if _, err := w.Write(utf8BOM); err != nil {
+			return nil, err
+		}
+	}
+	if e.CRLF {
+		return crlfWriter{w}, nil
+	}
+	return w, nil
+}
+
+// crlfWriter 将写入内容中的 \n 替换为 \r\n
+type crlfWriter struct {
+	w io.Writer
+}
+
+func (c crlfWriter) Write(p []byte) (int, error) {
+	if _, err := c.w.Write(bytes.ReplaceAll(p, []byte("\n"), []byte("\r\n"))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// stripBOM 去掉数据开头的 UTF-8 BOM，使带 BOM 的文件可以被各格式的解析器读取
+func stripBOM(data []byte) []byte {
+	return bytes.TrimPrefix(data, utf8BOM)
+}
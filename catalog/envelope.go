@@ -0,0 +1,160 @@
+package catalog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// checksumPrefix 校验和所用的算法前缀
+const checksumPrefix = "sha256:"
+
+// EnvelopeMeta 信封格式的头部信息
+type EnvelopeMeta struct {
+	// Generator 生成该文件的工具
+	Generator string `yaml:"generator,omitempty"`
+	Count     int    `yaml:"count"`
+	// Checksum 适配器列表规范 YAML 序列化结果的 SHA-256，形如 sha256:<hex>
+	Checksum string `yaml:"checksum,omitempty"`
+}
+
+// Envelope 带有头部信息的元数据文件，结构为 meta: 与 adapters: 两部分
+type Envelope struct {
+	Meta     EnvelopeMeta `yaml:"meta"`
+	Adapters []Entry      `yaml:"adapters"`
+}
+
+// NewEnvelope 用条目构造信封，withChecksum 为 true 时计算并填入校验和
+func NewEnvelope(entries []Entry, generator string, withChecksum bool) (Envelope, error) {
+	env := Envelope{
+		Meta:     EnvelopeMeta{Generator: generator, Count: len(entries)},
+		Adapters: entries,
+	}
+	if env.Adapters == nil {
+		env.Adapters = []Entry{}
+	}
+	if withChecksum {
+		sum, err := Checksum(entries)
+		if err != nil {
+			return env, err
+		}
+		env.Meta.Checksum = sum
+	}
+	return env, nil
+}
+
+// Checksum 计算条目列表规范形式（与 FormatYAML 输出一致）的 SHA-256
+func Checksum(entries []Entry) (string, error) {
+	data, err := Marshal(entries, FormatYAML)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return checksumPrefix + hex.EncodeToString(sum[:]), nil
+}
+
+// ContentHash 返回条目除 Id 以外内容的哈希，与 Checksum 使用相同的规范序列化
+// 作者按规范化形式参与计算，派生字段不参与计算；无法序列化时返回空字符串
+func ContentHash(e Entry) string {
+	e.Id = ""
+	e.Author = NormalizeAuthor(e.Author)
+	e.AuthorName = ""
+	e.AuthorEmail = ""
+	e.ShortDescription = ""
+	sum, err := Checksum([]Entry{e})
+	if err != nil {
+		return ""
+	}
+	return sum
+}
+
+// Verify 重新计算适配器列表的校验和并与头部记录的值比较
+// 校验基于规范形式，因此只改变缩进等格式的编辑不会导致校验失败
+func (e Envelope) Verify() error {
+	if e.Meta.Checksum == "" {
+		return fmt.Errorf("envelope has no checksum")
+	}
+	sum, err := Checksum(e.Adapters)
+	if err != nil {
+		return err
+	}
+	if sum != e.Meta.Checksum {
+		return fmt.Errorf("checksum mismatch: header has %s, adapters hash to %s", e.Meta.Checksum, sum)
+	}
+	return nil
+}
+
+// EncodeEnvelope 将信封写为 YAML
+func EncodeEnvelope(w io.Writer, env Envelope) error {
+	enc := yaml.NewEncoder(w)
+	if err := enc.Encode(env); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// ReadEnvelope 读取并解析信封格式的元数据文件
+func ReadEnvelope(path string) (Envelope, error) {
+	var env Envelope
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return env, fmt.Errorf("could not read metadata file %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, &env); err != nil {
+		return env, fmt.Errorf("could not parse envelope file %s: %w", path, err)
+	}
+	return env, nil
+}
+
+// DecodeYAML 解析 YAML 元数据，同时接受平铺列表与信封格式
+// 内容为含有 adapters 键的映射时按信封解析并返回其头部，否则按列表解析，meta 为 nil
+func DecodeYAML(data []byte) ([]Entry, *EnvelopeMeta, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+	if !isEnvelopeNode(&doc) {
+		entries, err := Unmarshal(data, FormatYAML)
+		return entries, nil, err
+	}
+	var env Envelope
+	if err := doc.Decode(&env); err != nil {
+		return nil, nil, err
+	}
+	return env.Adapters, &env.Meta, nil
+}
+
+// isEnvelopeNode 判断文档的顶层是否为含有 adapters 键的映射
+func isEnvelopeNode(doc *yaml.Node) bool {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return false
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return false
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "adapters" {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadFileMeta 读取元数据文件，平铺列表与信封格式均可，文件为信封格式时同时返回其头部
+// 文件不存在时返回的错误满足 errors.Is(err, os.ErrNotExist)
+func ReadFileMeta(path string) ([]Entry, *EnvelopeMeta, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read metadata file %s: %w", path, err)
+	}
+	entries, meta, err := DecodeYAML(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not parse metadata file %s: %w", path, err)
+	}
+	return entries, meta, nil
+}
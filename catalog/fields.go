@@ -0,0 +1,74 @@
+package catalog
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// entryFields Entry 中可以被 Project 保留的字段，键为 YAML 键名，值为反射用的字段下标
+// 表由 Entry 的结构生成，包括内嵌的 adapter.Metadata 中的字段与工具派生的字段
+var entryFields = func() map[string][]int {
+	fields := make(map[string][]int)
+	for _, field := range reflect.VisibleFields(reflect.TypeOf(Entry{})) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		fields[name] = field.Index
+	}
+	return fields
+}()
+
+// FieldNames 返回所有可以被 Project 保留的字段名，按字母顺序排列
+func FieldNames() []string {
+	names := make([]string, 0, len(entryFields))
+	for name := range entryFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseFields 解析逗号分隔的字段名列表，字段名与 YAML 键名一致，如 id,title,type
+// 空字符串表示保留全部字段，返回 nil；未知的字段名返回错误
+func ParseFields(list string) ([]string, error) {
+	if strings.TrimSpace(list) == "" {
+		return nil, nil
+	}
+	var fields []string
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := entryFields[name]; !ok {
+			return nil, fmt.Errorf("unknown field %q (expected one of %s)", name, strings.Join(FieldNames(), ", "))
+		}
+		fields = append(fields, name)
+	}
+	return fields, nil
+}
+
+// Project 返回只保留 fields 中字段的条目副本，其余字段被置为零值，fields 为空时原样返回
+// 派生字段为零值时不会输出；adapter.Metadata 中的字段没有 omitempty，仍会以空值输出
+func Project(entries []Entry, fields []string) []Entry {
+	if len(fields) == 0 {
+		return entries
+	}
+
+	projected := make([]Entry, len(entries))
+	for i := range entries {
+		src := reflect.ValueOf(entries[i])
+		dst := reflect.ValueOf(&projected[i]).Elem()
+		for _, name := range fields {
+			index := entryFields[name]
+			dst.FieldByIndex(index).Set(src.FieldByIndex(index))
+		}
+	}
+	return projected
+}
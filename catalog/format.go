@@ -0,0 +1,215 @@
+package catalog
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/meloshub/meloshub/adapter"
+	"gopkg.in/yaml.v3"
+)
+
+// 支持的输出格式
+const (
+	FormatYAML = "yaml"
+	FormatCSV  = "csv"
+	// FormatEnvelope 带有 meta 头部的 YAML，见 Envelope
+	FormatEnvelope = "envelope"
+	// FormatNDJSON 每行一个紧凑 JSON 对象
+	FormatNDJSON = "ndjson"
+	// FormatProtobuf 按 catalog.proto 中的 Catalog 消息编码的二进制目录
+	FormatProtobuf = "pb"
+)
+
+// Formats 所有支持的格式
+var Formats = []string{FormatYAML, FormatCSV, FormatEnvelope, FormatNDJSON, FormatProtobuf}
+
+// csvHeader CSV 输出的表头，仅包含 adapter.Metadata 中的字段
+var csvHeader = []string{"Id", "Title", "Type", "Version", "Author", "Description"}
+
+// Marshal 按指定格式序列化条目
+func Marshal(entries []Entry, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := Encode(&buf, entries, format); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// checkCSVFields 检查条目是否只使用了 csvHeader 中的字段：功能开关与本地化标题无法写入平铺的 CSV，
+// 与其在输出中悄悄丢弃，不如在写入任何内容之前报错
+func checkCSVFields(entries []Entry) error {
+	for _, e := range entries {
+		var dropped []string
+		if len(e.Capabilities) > 0 {
+			dropped = append(dropped, "capabilities")
+		}
+		if len(e.Titles) > 0 {
+			dropped = append(dropped, "titles")
+		}
+		if len(dropped) > 0 {
+			return fmt.Errorf("csv cannot represent the %s of adapter '%s'", strings.Join(dropped, " and "), e.Id)
+		}
+	}
+	return nil
+}
+
+// Encode 按指定格式将条目逐个写入 w，避免在内存中同时保留整个序列化结果
+func Encode(w io.Writer, entries []Entry, format string) error {
+	switch format {
+	case FormatYAML:
+		return encodeYAML(w, entries)
+	case FormatCSV:
+		if err := checkCSVFields(entries); err != nil {
+			return err
+		}
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvHeader); err != nil {
+			return err
+		}
+		for _, e := range entries {
+			row := []string{e.Id, e.Title, string(e.Type), e.Version, e.Author, e.Description}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case FormatEnvelope:
+		env, err := NewEnvelope(entries, "", false)
+		if err != nil {
+			return err
+		}
+		return EncodeEnvelope(w, env)
+	case FormatNDJSON:
+		enc := json.NewEncoder(w)
+		// 保留作者字段中的 <>，便于直接 grep
+		enc.SetEscapeHTML(false)
+		for _, e := range entries {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatProtobuf:
+		data, err := marshalProtobuf(entries)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// encodeYAML 逐个条目输出 YAML 列表，结果与对整个切片调用 yaml.Marshal 逐字节一致
+// 每个条目都编码为只含一个元素的列表，多个这样的片段首尾相接即构成完整的顶层列表
+func encodeYAML(w io.Writer, entries []Entry) error {
+	if len(entries) == 0 {
+		data, err := yaml.Marshal(entries)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+
+	for i := range entries {
+		// 每个片段使用独立的 Encoder，否则会在片段之间插入文档分隔符 ---
+		enc := yaml.NewEncoder(w)
+		if err := enc.Encode(entries[i : i+1]); err != nil {
+			return err
+		}
+		if err := enc.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarshalEntry 将单个条目序列化为 YAML 映射，而不是只含一个元素的列表
+func MarshalEntry(entry Entry) ([]byte, error) {
+	return yaml.Marshal(entry)
+}
+
+// Unmarshal 按指定格式解析条目，文本格式开头的 UTF-8 BOM 会被忽略
+func Unmarshal(data []byte, format string) ([]Entry, error) {
+	if format != FormatProtobuf {
+		data = stripBOM(data)
+	}
+	switch format {
+	case FormatYAML:
+		var entries []Entry
+		if err := yaml.Unmarshal(data, &entries); err != nil {
+			return nil, err
+		}
+		return entries, nil
+	case FormatCSV:
+		records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		if len(records) == 0 {
+			return nil, nil
+		}
+		var entries []Entry
+		for _, r := range records[1:] {
+			if len(r) != len(csvHeader) {
+				return nil, fmt.Errorf("expected %d columns, got %d", len(csvHeader), len(r))
+			}
+			entries = append(entries, Entry{Metadata: adapter.Metadata{
+				Id:          r[0],
+				Title:       r[1],
+				Type:        adapter.AdapterType(r[2]),
+				Version:     r[3],
+				Author:      r[4],
+				Description: r[5],
+			}})
+		}
+		return entries, nil
+	case FormatEnvelope:
+		var env Envelope
+		if err := yaml.Unmarshal(data, &env); err != nil {
+			return nil, err
+		}
+		return env.Adapters, nil
+	case FormatNDJSON:
+		var entries []Entry
+		dec := json.NewDecoder(bytes.NewReader(data))
+		for {
+			var e Entry
+			if err := dec.Decode(&e); errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("line %d: %w", len(entries)+1, err)
+			}
+			entries = append(entries, e)
+		}
+		return entries, nil
+	case FormatProtobuf:
+		return unmarshalProtobuf(data)
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// ReadFileFormat 读取并按指定格式解析元数据文件
+// 文件不存在时返回的错误满足 errors.Is(err, os.ErrNotExist)
+func ReadFileFormat(path, format string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read metadata file %s: %w", path, err)
+	}
+
+	entries, err := Unmarshal(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s file %s: %w", format, path, err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,46 @@
+package catalog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/meloshub/meloshub/adapter"
+)
+
+func TestMarshalCSV(t *testing.T) {
+	entries := []Entry{{Metadata: adapter.Metadata{Id: "spotify", Title: "Spotify, Inc.", Type: adapter.TypeOfficial, Version: "1.0.0", Author: "alice"}}}
+	data, err := Marshal(entries, FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Id,Title,Type,Version,Author,Description\nspotify,\"Spotify, Inc.\"," + string(adapter.TypeOfficial) + ",1.0.0,alice,\n"
+	if string(data) != want {
+		t.Errorf("csv = %q, want %q", data, want)
+	}
+}
+
+// TestMarshalCSVRejectsMapFields 检查带有功能开关或本地化标题的条目不会在 CSV 中被悄悄丢弃
+func TestMarshalCSVRejectsMapFields(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry Entry
+		want  string
+	}{
+		{"capabilities", Entry{Metadata: adapter.Metadata{Id: "spotify"}, Capabilities: map[string]bool{"search": true}}, "the capabilities of adapter 'spotify'"},
+		{"titles", Entry{Metadata: adapter.Metadata{Id: "deezer"}, Titles: map[string]string{"zh": "迪泽"}}, "the titles of adapter 'deezer'"},
+		{"both", Entry{Metadata: adapter.Metadata{Id: "tidal"}, Capabilities: map[string]bool{"lyrics": false}, Titles: map[string]string{"en": "Tidal"}}, "the capabilities and titles of adapter 'tidal'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf strings.Builder
+			err := Encode(&buf, []Entry{tt.entry}, FormatCSV)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Encode = %v, want an error mentioning %q", err, tt.want)
+			}
+			// 报错发生在写入任何内容之前，不会留下只有表头的输出
+			if buf.Len() != 0 {
+				t.Errorf("Encode wrote %q before failing", buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package catalog
+
+// ApplyDefaultLocale 用 Titles 中 locale 对应的标题填充 Title 为空的条目，使只读取 Title 的旧版客户端仍能显示标题
+// 已声明 Title 的条目保持不变；返回声明了 Titles 却缺少该语言、因而 Title 仍为空的条目下标
+func ApplyDefaultLocale(entries []Entry, locale string) []int {
+	var missing []int
+	for i := range entries {
+		e := &entries[i]
+		if e.Title != "" || len(e.Titles) == 0 {
+			continue
+		}
+		if title, ok := e.Titles[locale]; ok {
+			e.Title = title
+		} else {
+			missing = append(missing, i)
+		}
+	}
+	return missing
+}
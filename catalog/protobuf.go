@@ -0,0 +1,58 @@
+package catalog
+
+import (
+	"github.com/meloshub/meloshub-tools/catalog/catalogpb"
+	"github.com/meloshub/meloshub/adapter"
+	"google.golang.org/protobuf/proto"
+)
+
+//go:generate protoc -I.. --go_out=.. --go_opt=module=github.com/meloshub/meloshub-tools ../catalog/catalog.proto
+
+// marshalProtobuf 将条目按 catalog.proto 中的 Catalog 消息编码
+// 使用确定性编码，功能开关与本地化标题按键排序，使相同的输入总是得到相同的字节
+func marshalProtobuf(entries []Entry) ([]byte, error) {
+	msg := &catalogpb.Catalog{Adapters: make([]*catalogpb.Adapter, 0, len(entries))}
+	for _, e := range entries {
+		msg.Adapters = append(msg.Adapters, &catalogpb.Adapter{
+			Id:               e.Id,
+			Title:            e.Title,
+			Type:             string(e.Type),
+			Version:          e.Version,
+			Author:           e.Author,
+			Description:      e.Description,
+			Capabilities:     e.Capabilities,
+			AuthorName:       e.AuthorName,
+			AuthorEmail:      e.AuthorEmail,
+			ShortDescription: e.ShortDescription,
+			Titles:           e.Titles,
+		})
+	}
+	return proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+}
+
+// unmarshalProtobuf 解析 Catalog 消息，未知字段会被跳过，以兼容新版本写出的文件
+func unmarshalProtobuf(data []byte) ([]Entry, error) {
+	var msg catalogpb.Catalog
+	if err := proto.Unmarshal(data, &msg); err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	for _, a := range msg.GetAdapters() {
+		entries = append(entries, Entry{
+			Metadata: adapter.Metadata{
+				Id:          a.GetId(),
+				Title:       a.GetTitle(),
+				Type:        adapter.AdapterType(a.GetType()),
+				Version:     a.GetVersion(),
+				Author:      a.GetAuthor(),
+				Description: a.GetDescription(),
+			},
+			Capabilities:     a.GetCapabilities(),
+			AuthorName:       a.GetAuthorName(),
+			AuthorEmail:      a.GetAuthorEmail(),
+			ShortDescription: a.GetShortDescription(),
+			Titles:           a.GetTitles(),
+		})
+	}
+	return entries, nil
+}
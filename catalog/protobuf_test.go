@@ -0,0 +1,145 @@
+package catalog
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"github.com/meloshub/meloshub-tools/catalog/catalogpb"
+	"github.com/meloshub/meloshub/adapter"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// protobufEntries 覆盖 catalog.proto 中的每个字段，第二个条目只有 Id
+func protobufEntries() []Entry {
+	return []Entry{
+		{
+			Metadata: adapter.Metadata{
+				Id:          "a",
+				Title:       "Café",
+				Type:        adapter.TypeOfficial,
+				Version:     "1.0.0",
+				Author:      "bob <b@x.io>",
+				Description: "d",
+			},
+			Capabilities:     map[string]bool{"search": true, "lyrics": false},
+			Titles:           map[string]string{"zh": "甲", "en": "A"},
+			AuthorName:       "bob",
+			AuthorEmail:      "b@x.io",
+			ShortDescription: "s",
+		},
+		{Metadata: adapter.Metadata{Id: "b"}},
+	}
+}
+
+func TestProtobufRoundTrip(t *testing.T) {
+	want := protobufEntries()
+	data, err := Marshal(want, FormatProtobuf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Unmarshal(data, FormatProtobuf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip = %+v, want %+v", got, want)
+	}
+
+	again, err := Marshal(got, FormatProtobuf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again, data) {
+		t.Errorf("re-encoding produced different bytes:\n%x\n%x", again, data)
+	}
+}
+
+// TestProtobufMatchesSchema 用 catalog.proto 生成的消息解码输出，检查每个字段都写在 .proto 声明的编号上
+func TestProtobufMatchesSchema(t *testing.T) {
+	data, err := Marshal(protobufEntries(), FormatProtobuf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg catalogpb.Catalog
+	if err := proto.Unmarshal(data, &msg); err != nil {
+		t.Fatal(err)
+	}
+	if len(msg.ProtoReflect().GetUnknown()) != 0 {
+		t.Errorf("catalog has unknown fields")
+	}
+	if len(msg.Adapters) != 2 {
+		t.Fatalf("decoded %d adapters, want 2", len(msg.Adapters))
+	}
+	a := msg.Adapters[0]
+	if len(a.ProtoReflect().GetUnknown()) != 0 {
+		t.Errorf("adapter has unknown fields")
+	}
+	want := &catalogpb.Adapter{
+		Id:               "a",
+		Title:            "Café",
+		Type:             "official",
+		Version:          "1.0.0",
+		Author:           "bob <b@x.io>",
+		Description:      "d",
+		Capabilities:     map[string]bool{"search": true, "lyrics": false},
+		AuthorName:       "bob",
+		AuthorEmail:      "b@x.io",
+		ShortDescription: "s",
+		Titles:           map[string]string{"zh": "甲", "en": "A"},
+	}
+	if !proto.Equal(a, want) {
+		t.Errorf("adapter = %v, want %v", a, want)
+	}
+	if got := msg.Adapters[1]; !proto.Equal(got, &catalogpb.Adapter{Id: "b"}) {
+		t.Errorf("second adapter = %v, want only id b", got)
+	}
+}
+
+// TestProtobufDecodesEarlierOutput 解码早期手工编码器写出的字节，其中值为 false 的功能开关省略了 value 字段
+func TestProtobufDecodesEarlierOutput(t *testing.T) {
+	data, err := hex.DecodeString(
+		"0a660a01611205436166c3a91a086f6666696369616c2205312e302e302a0c626f62203c" +
+			"6240782e696f3e3201643a080a066c79726963733a0a0a0673656172636810014203626f" +
+			"624a066240782e696f5201735a070a02656e1201415a090a027a681203e794b20a030a01" +
+			"62")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Unmarshal(data, FormatProtobuf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := protobufEntries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("decoded %+v, want %+v", got, want)
+	}
+}
+
+func TestProtobufSkipsUnknownFields(t *testing.T) {
+	data, err := Marshal(protobufEntries()[1:], FormatProtobuf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Catalog 中未声明的字段 2，模拟新版本写出的文件
+	data = protowire.AppendTag(data, 2, protowire.BytesType)
+	data = protowire.AppendString(data, "future")
+	got, err := Unmarshal(data, FormatProtobuf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Id != "b" {
+		t.Errorf("decoded %+v, want only adapter b", got)
+	}
+}
+
+func TestProtobufRejectsTruncatedInput(t *testing.T) {
+	data, err := Marshal(protobufEntries(), FormatProtobuf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Unmarshal(data[:len(data)-3], FormatProtobuf); err == nil {
+		t.Error("truncated input decoded without error")
+	}
+}
@@ -0,0 +1,60 @@
+package catalog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 条目排序键
+const (
+	SortKeyId     = "id"
+	SortKeyTitle  = "title"
+	SortKeyType   = "type"
+	SortKeyAuthor = "author"
+)
+
+// sortKeyFields 各排序键对应的字段
+var sortKeyFields = map[string]func(e Entry) string{
+	SortKeyId:     func(e Entry) string { return e.Id },
+	SortKeyTitle:  func(e Entry) string { return e.Title },
+	SortKeyType:   func(e Entry) string { return string(e.Type) },
+	SortKeyAuthor: func(e Entry) string { return e.Author },
+}
+
+// ParseSortKeys 解析逗号分隔的排序键列表，如 type,title；键不能重复
+func ParseSortKeys(value string) ([]string, error) {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, key := range strings.Split(value, ",") {
+		key = strings.TrimSpace(key)
+		if _, ok := sortKeyFields[key]; !ok {
+			return nil, fmt.Errorf("unknown sort key %q (expected %s, %s, %s or %s)", key, SortKeyId, SortKeyTitle, SortKeyType, SortKeyAuthor)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("sort key %q is listed more than once", key)
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// SortBy 按 keys 依次比较对应字段对条目稳定排序，所有键都相同时再按 Id 排序，
+// 使结果不依赖条目原有的顺序；keys 须已由 ParseSortKeys 检查
+func SortBy(entries []Entry, keys []string) {
+	fields := make([]func(e Entry) string, 0, len(keys)+1)
+	for _, key := range keys {
+		fields = append(fields, sortKeyFields[key])
+	}
+	fields = append(fields, sortKeyFields[SortKeyId])
+	sort.SliceStable(entries, func(i, j int) bool {
+		for _, field := range fields {
+			a, b := field(entries[i]), field(entries[j])
+			if a != b {
+				return a < b
+			}
+		}
+		return false
+	})
+}
@@ -0,0 +1,92 @@
+package catalog
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/meloshub/meloshub/adapter"
+)
+
+// sortEntries 排序测试所用的条目，各字段的顺序互不相同
+func sortEntries() []Entry {
+	entry := func(id, title string, typ adapter.AdapterType, author string) Entry {
+		return Entry{Metadata: adapter.Metadata{Id: id, Title: title, Type: typ, Author: author}}
+	}
+	return []Entry{
+		entry("tidal", "Tidal", adapter.TypeOfficial, "carol"),
+		entry("deezer", "Deezer", adapter.TypeCommunity, "bob"),
+		entry("spotify", "Alpha", adapter.TypeOfficial, "alice"),
+		entry("qobuz", "Tidal", adapter.TypeCommunity, "alice"),
+	}
+}
+
+// entryIds 按顺序返回条目的 Id
+func entryIds(entries []Entry) []string {
+	ids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.Id)
+	}
+	return ids
+}
+
+func TestSortBy(t *testing.T) {
+	tests := []struct {
+		spec string
+		want []string
+	}{
+		{"id", []string{"deezer", "qobuz", "spotify", "tidal"}},
+		// Title 相同的 qobuz 与 tidal 按 Id 排序
+		{"title", []string{"spotify", "deezer", "qobuz", "tidal"}},
+		{"type", []string{"deezer", "qobuz", "spotify", "tidal"}},
+		{"author", []string{"qobuz", "spotify", "deezer", "tidal"}},
+		{"type,title", []string{"deezer", "qobuz", "spotify", "tidal"}},
+		{"type,author", []string{"qobuz", "deezer", "spotify", "tidal"}},
+		{"author,title", []string{"spotify", "qobuz", "deezer", "tidal"}},
+		{" title , id ", []string{"spotify", "deezer", "qobuz", "tidal"}},
+	}
+	for _, tt := range tests {
+		keys, err := ParseSortKeys(tt.spec)
+		if err != nil {
+			t.Fatalf("ParseSortKeys(%q): %v", tt.spec, err)
+		}
+		entries := sortEntries()
+		SortBy(entries, keys)
+		if got := entryIds(entries); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("--sort-by %s = %v, want %v", tt.spec, got, tt.want)
+		}
+
+		// 结果与输入顺序无关
+		reversed := sortEntries()
+		for i, j := 0, len(reversed)-1; i < j; i, j = i+1, j-1 {
+			reversed[i], reversed[j] = reversed[j], reversed[i]
+		}
+		SortBy(reversed, keys)
+		if got := entryIds(reversed); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("--sort-by %s on reversed input = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestSortByIdMatchesSortById(t *testing.T) {
+	byKey, byId := sortEntries(), sortEntries()
+	SortBy(byKey, []string{SortKeyId})
+	SortById(byId)
+	if !reflect.DeepEqual(entryIds(byKey), entryIds(byId)) {
+		t.Errorf("SortBy id = %v, SortById = %v", entryIds(byKey), entryIds(byId))
+	}
+}
+
+func TestParseSortKeysErrors(t *testing.T) {
+	tests := []struct{ spec, want string }{
+		{"name", `unknown sort key "name"`},
+		{"", `unknown sort key ""`},
+		{"title,", `unknown sort key ""`},
+		{"title,title", `sort key "title" is listed more than once`},
+	}
+	for _, tt := range tests {
+		if _, err := ParseSortKeys(tt.spec); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseSortKeys(%q) error = %v, want %q", tt.spec, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package catalog
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ellipsis 截断的描述末尾追加的省略号，计为一个字符
+const ellipsis = "…"
+
+// TruncateDescription 将超过 max 个字符（按 Unicode 码点计）的描述截断为不超过 max 个字符，末尾为省略号
+// 优先在单词边界截断，并去掉断点处多余的空白与 ,;: 等标点；开头的第一个单词就超过长度时在单词中间截断。
+// 不超过 max 的描述原样返回，max 必须大于 0
+func TruncateDescription(description string, max int) string {
+	if utf8.RuneCountInString(description) <= max {
+		return description
+	}
+
+	// 为省略号留出一个字符
+	runes := []rune(description)
+	cut := max - 1
+	prefix := runes[:cut]
+	// runes[cut] 为空白时 prefix 恰好在单词边界结束，否则回退到最后一个空白处
+	if !unicode.IsSpace(runes[cut]) {
+		for i := len(prefix) - 1; i > 0; i-- {
+			if unicode.IsSpace(prefix[i]) {
+				prefix = prefix[:i]
+				break
+			}
+		}
+	}
+	short := strings.TrimRightFunc(string(prefix), func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune(",;:、，；：", r)
+	})
+	return short + ellipsis
+}
+
+// TruncateDescriptions 将每个条目的描述截断为不超过 max 个字符，见 TruncateDescription
+// separate 为 true 时保留完整的 Description，截断结果写入 ShortDescription（未超长时与 Description 相同）
+func TruncateDescriptions(entries []Entry, max int, separate bool) {
+	for i := range entries {
+		short := TruncateDescription(entries[i].Description, max)
+		if separate {
+			entries[i].ShortDescription = short
+		} else {
+			entries[i].Description = short
+		}
+	}
+}
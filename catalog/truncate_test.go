@@ -0,0 +1,61 @@
+package catalog
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateDescription(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		max         int
+		want        string
+	}{
+		{"exactly max", "0123456789", 10, "0123456789"},
+		{"one over max", "Hello big world", 14, "Hello big…"},
+		{"cut right at a space", "Hello big world", 10, "Hello big…"},
+		{"cut inside a word", "Hello bigger world", 10, "Hello…"},
+		{"first word too long", "01234567890", 10, "012345678…"},
+		{"trailing comma", "Hello, big world", 10, "Hello…"},
+		{"multibyte", "音乐适配器支持歌词搜索", 10, "音乐适配器支持歌词…"},
+		{"multibyte exactly max", "音乐适配器支持歌词搜", 10, "音乐适配器支持歌词搜"},
+		{"max of one", "ab", 1, "…"},
+		{"empty", "", 1, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncateDescription(tt.description, tt.max)
+			if got != tt.want {
+				t.Errorf("TruncateDescription(%q, %d) = %q, want %q", tt.description, tt.max, got, tt.want)
+			}
+			if n := utf8.RuneCountInString(got); n > tt.max {
+				t.Errorf("result has %d characters, more than %d", n, tt.max)
+			}
+		})
+	}
+}
+
+func TestTruncateDescriptions(t *testing.T) {
+	long, short := validEntry("long"), validEntry("short")
+	long.Description = "Streams music from Deezer."
+	short.Description = "Streams."
+
+	entries := []Entry{long, short}
+	TruncateDescriptions(entries, 10, false)
+	if entries[0].Description != "Streams…" || entries[0].ShortDescription != "" {
+		t.Errorf("overwrite: %q / %q, want the truncated Description only", entries[0].Description, entries[0].ShortDescription)
+	}
+	if entries[1].Description != "Streams." {
+		t.Errorf("overwrite changed a short description to %q", entries[1].Description)
+	}
+
+	entries = []Entry{long, short}
+	TruncateDescriptions(entries, 10, true)
+	if entries[0].Description != long.Description || entries[0].ShortDescription != "Streams…" {
+		t.Errorf("separate: %q / %q, want the full Description and a truncated ShortDescription", entries[0].Description, entries[0].ShortDescription)
+	}
+	if entries[1].ShortDescription != "Streams." {
+		t.Errorf("separate: short ShortDescription = %q, want the Description", entries[1].ShortDescription)
+	}
+}
@@ -0,0 +1,322 @@
+package catalog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/meloshub/meloshub/adapter"
+	"golang.org/x/text/unicode/norm"
+)
+
+// 校验规则 Id
+const (
+	RuleMissingField    = "missing-field"
+	RuleBadVersion      = "bad-version"
+	RuleDuplicateId     = "duplicate-id"
+	RuleUnknownType     = "unknown-type"
+	RulePoorDescription = "poor-description"
+	RuleWhitespace      = "whitespace"
+	// RuleDescriptionStyle 仅在 ValidateOptions.DescriptionStyle 时检查
+	RuleDescriptionStyle = "description-style"
+	// RuleClone 仅在 ValidateOptions.WarnClones 时检查
+	RuleClone = "clone"
+)
+
+// RuleDescriptions 各校验规则的简要说明
+var RuleDescriptions = map[string]string{
+	RuleMissingField:     "Adapter metadata is missing a required field",
+	RuleBadVersion:       "Adapter version is not a valid semantic version",
+	RuleDuplicateId:      "Adapter Id is declared more than once",
+	RuleUnknownType:      "Adapter type is not a known AdapterType",
+	RulePoorDescription:  "Adapter description is too short or only placeholder text",
+	RuleWhitespace:       "Adapter metadata field has leading, trailing or repeated whitespace",
+	RuleDescriptionStyle: "Adapter description does not start with a capital letter or end with sentence punctuation",
+	RuleClone:            "Adapter metadata is identical to another adapter's apart from the Id",
+}
+
+// placeholderDescriptions 视为占位文本的描述，比较时忽略大小写与首尾标点
+var placeholderDescriptions = map[string]bool{
+	"todo":        true,
+	"tbd":         true,
+	"fixme":       true,
+	"wip":         true,
+	"placeholder": true,
+	"description": true,
+}
+
+// semverPattern 语义化版本 2.0.0 规范给出的正则表达式
+var semverPattern = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+// knownTypes 已知的适配器类型
+var knownTypes = map[adapter.AdapterType]bool{
+	adapter.TypeOfficial:  true,
+	adapter.TypeCommunity: true,
+}
+
+// Finding 一条校验结果
+type Finding struct {
+	Rule string
+	Id   string
+	// Index 问题条目在传入 Validate 的切片中的下标
+	Index   int
+	Message string
+}
+
+// ValidateOptions 控制可选的校验规则，零值只执行默认规则
+type ValidateOptions struct {
+	// MinDescriptionLen 描述的最小字符数，大于 0 时同时检查占位文本
+	MinDescriptionLen int
+	// DescriptionStyle 检查描述是否以大写字母开头、以句末标点结尾
+	DescriptionStyle bool
+	// WarnClones 检查除 Id 以外内容完全相同的适配器，通常是复制粘贴后忘记修改的定义
+	WarnClones bool
+	// IgnoreCaseId 检查重复 Id 时忽略大小写，只有大小写不同的 Id 同样视为重复
+	IgnoreCaseId bool
+}
+
+// Validate 校验条目的元数据，返回所有发现的问题
+func Validate(entries []Entry, opts ValidateOptions) []Finding {
+	var findings []Finding
+	seen := make(map[string]string)
+
+	for i, entry := range entries {
+		required := []struct {
+			name  string
+			value string
+		}{
+			{"Id", entry.Id},
+			{"Title", entry.Title},
+			{"Type", string(entry.Type)},
+			{"Version", entry.Version},
+			{"Author", entry.Author},
+		}
+		for _, field := range required {
+			if field.value == "" {
+				findings = append(findings, Finding{
+					Rule:    RuleMissingField,
+					Id:      entry.Id,
+					Index:   i,
+					Message: fmt.Sprintf("adapter '%s' is missing required field %s", entry.Id, field.name),
+				})
+			}
+		}
+
+		if entry.Version != "" && !semverPattern.MatchString(entry.Version) {
+			findings = append(findings, Finding{
+				Rule:    RuleBadVersion,
+				Id:      entry.Id,
+				Index:   i,
+				Message: fmt.Sprintf("adapter '%s' has invalid version %q", entry.Id, entry.Version),
+			})
+		}
+
+		if entry.Type != "" && !knownTypes[entry.Type] {
+			findings = append(findings, Finding{
+				Rule:    RuleUnknownType,
+				Id:      entry.Id,
+				Index:   i,
+				Message: fmt.Sprintf("adapter '%s' has unknown type %q", entry.Id, entry.Type),
+			})
+		}
+
+		for _, field := range stringFields(&entry) {
+			if problem := whitespaceProblem(*field.value); problem != "" {
+				findings = append(findings, Finding{
+					Rule:    RuleWhitespace,
+					Id:      entry.Id,
+					Index:   i,
+					Message: fmt.Sprintf("adapter '%s' field %s has %s", entry.Id, field.name, problem),
+				})
+			}
+		}
+
+		if opts.MinDescriptionLen > 0 {
+			if problems := descriptionProblems(entry.Description, opts.MinDescriptionLen); len(problems) > 0 {
+				findings = append(findings, Finding{
+					Rule:    RulePoorDescription,
+					Id:      entry.Id,
+					Index:   i,
+					Message: fmt.Sprintf("adapter '%s' has a poor description: %s", entry.Id, strings.Join(problems, "; ")),
+				})
+			}
+		}
+
+		if opts.DescriptionStyle {
+			if problems := descriptionStyleProblems(entry.Description); len(problems) > 0 {
+				findings = append(findings, Finding{
+					Rule:    RuleDescriptionStyle,
+					Id:      entry.Id,
+					Index:   i,
+					Message: fmt.Sprintf("adapter '%s' description %s", entry.Id, strings.Join(problems, " and ")),
+				})
+			}
+		}
+
+		key := IdKey(entry.Id, opts.IgnoreCaseId)
+		if first, ok := seen[key]; ok {
+			message := fmt.Sprintf("duplicate adapter Id '%s'", entry.Id)
+			if first != entry.Id {
+				message = fmt.Sprintf("adapter Ids '%s' and '%s' differ only in case", first, entry.Id)
+			}
+			findings = append(findings, Finding{
+				Rule:    RuleDuplicateId,
+				Id:      entry.Id,
+				Index:   i,
+				Message: message,
+			})
+		} else {
+			seen[key] = entry.Id
+		}
+	}
+
+	if opts.WarnClones {
+		findings = append(findings, cloneFindings(entries)...)
+	}
+	return findings
+}
+
+// cloneFindings 按 ContentHash 找出内容相同而 Id 不同的适配器，每组中第一个之后的适配器各产生一条结果
+// 同一 Id 重复出现时由 duplicate-id 规则报告，不视为克隆
+func cloneFindings(entries []Entry) []Finding {
+	var findings []Finding
+	firstByHash := make(map[string]int)
+	for i, entry := range entries {
+		hash := ContentHash(entry)
+		if hash == "" {
+			continue
+		}
+		first, ok := firstByHash[hash]
+		if !ok {
+			firstByHash[hash] = i
+			continue
+		}
+		if entries[first].Id == entry.Id {
+			continue
+		}
+		findings = append(findings, Finding{
+			Rule:    RuleClone,
+			Id:      entry.Id,
+			Index:   i,
+			Message: fmt.Sprintf("adapter '%s' has the same metadata as '%s' apart from the Id", entry.Id, entries[first].Id),
+		})
+	}
+	return findings
+}
+
+// descriptionProblems 返回描述存在的所有问题，同一条目的问题合并为一条结果
+func descriptionProblems(description string, minLen int) []string {
+	var problems []string
+	trimmed := strings.TrimSpace(description)
+	if n := utf8.RuneCountInString(trimmed); n < minLen {
+		problems = append(problems, fmt.Sprintf("%d characters, at least %d required", n, minLen))
+	}
+	word := strings.ToLower(strings.Trim(trimmed, " .!:-_*[]()"))
+	if placeholderDescriptions[word] {
+		problems = append(problems, fmt.Sprintf("placeholder text %q", trimmed))
+	}
+	return problems
+}
+
+// sentenceEnd 视为句末标点的字符
+const sentenceEnd = ".!?。！？"
+
+// descriptionStyleProblems 返回描述不符合格式约定之处，空描述不检查
+// 以非字母开头（如数字或版本号）的描述不要求大写
+func descriptionStyleProblems(description string) []string {
+	trimmed := strings.TrimSpace(description)
+	if trimmed == "" {
+		return nil
+	}
+	var problems []string
+	if first, _ := utf8.DecodeRuneInString(trimmed); unicode.IsLower(first) {
+		problems = append(problems, "does not start with a capital letter")
+	}
+	if last, _ := utf8.DecodeLastRuneInString(trimmed); !strings.ContainsRune(sentenceEnd, last) {
+		problems = append(problems, "does not end with a period")
+	}
+	return problems
+}
+
+// FixDescriptionStyle 将描述的首字母改为大写，并在缺少句末标点时补上句号，空描述保持不变
+func FixDescriptionStyle(entries []Entry) {
+	for i := range entries {
+		description := strings.TrimRightFunc(entries[i].Description, unicode.IsSpace)
+		if strings.TrimSpace(description) == "" {
+			continue
+		}
+		start := len(description) - len(strings.TrimLeftFunc(description, unicode.IsSpace))
+		if first, size := utf8.DecodeRuneInString(description[start:]); unicode.IsLower(first) {
+			description = description[:start] + string(unicode.ToUpper(first)) + description[start+size:]
+		}
+		if last, _ := utf8.DecodeLastRuneInString(description); !strings.ContainsRune(sentenceEnd, last) {
+			description += "."
+		}
+		entries[i].Description = description
+	}
+}
+
+// stringField 条目中的一个字符串字段
+type stringField struct {
+	name  string
+	value *string
+}
+
+// stringFields 返回条目中所有可由作者填写的字符串字段的指针
+func stringFields(entry *Entry) []stringField {
+	return []stringField{
+		{"Id", &entry.Id},
+		{"Title", &entry.Title},
+		{"Type", (*string)(&entry.Type)},
+		{"Version", &entry.Version},
+		{"Author", &entry.Author},
+		{"Description", &entry.Description},
+	}
+}
+
+// whitespaceProblem 描述值中多余的空白，没有问题时返回空字符串
+func whitespaceProblem(value string) string {
+	switch {
+	case value != strings.TrimSpace(value):
+		return "leading or trailing whitespace"
+	case strings.Contains(value, "  "):
+		return "repeated internal spaces"
+	}
+	return ""
+}
+
+// TrimWhitespace 去除所有字符串字段首尾的空白，并将内部连续的空格合并为一个
+func TrimWhitespace(entries []Entry) {
+	for i := range entries {
+		for _, field := range stringFields(&entries[i]) {
+			*field.value = collapseSpaces(strings.TrimSpace(*field.value))
+		}
+	}
+}
+
+// NormalizeUnicode 将所有字符串字段以及本地化标题的键与值转换为 Unicode NFC 形式，
+// 使以组合字符与预组合字符两种方式书写的同一段文字（如 "Café"）得到相同的值
+func NormalizeUnicode(entries []Entry) {
+	for i := range entries {
+		for _, field := range stringFields(&entries[i]) {
+			*field.value = norm.NFC.String(*field.value)
+		}
+		if titles := entries[i].Titles; titles != nil {
+			normalized := make(map[string]string, len(titles))
+			for locale, title := range titles {
+				normalized[norm.NFC.String(locale)] = norm.NFC.String(title)
+			}
+			entries[i].Titles = normalized
+		}
+	}
+}
+
+// collapseSpaces 将连续的空格合并为一个，保留换行等其它空白
+func collapseSpaces(value string) string {
+	for strings.Contains(value, "  ") {
+		value = strings.ReplaceAll(value, "  ", " ")
+	}
+	return value
+}
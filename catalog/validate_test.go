@@ -0,0 +1,247 @@
+package catalog
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/meloshub/meloshub/adapter"
+)
+
+func TestNormalizeUnicode(t *testing.T) {
+	tests := []struct {
+		name       string
+		composed   string
+		decomposed string
+	}{
+		{"latin acute", "Café", "Cafe\u0301"},
+		{"stacked marks", "ệ", "e\u0323\u0302"},
+		{"reordered marks", "ệ", "e\u0302\u0323"},
+		{"hangul syllable", "한", "\u1112\u1161\u11ab"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := []Entry{
+				{Metadata: adapter.Metadata{Id: "a", Title: tt.composed, Description: tt.composed}},
+				{Metadata: adapter.Metadata{Id: "b", Title: tt.decomposed, Description: tt.decomposed}},
+			}
+			NormalizeUnicode(entries)
+			for _, e := range entries {
+				if e.Title != tt.composed || e.Description != tt.composed {
+					t.Errorf("entry %s = %+q/%+q, want %+q", e.Id, e.Title, e.Description, tt.composed)
+				}
+			}
+		})
+	}
+}
+
+// TestNormalizeUnicodeTitles 检查本地化标题的键与值同样被规范化，包括由 ApplyDefaultLocale 复制到 Title 的标题
+func TestNormalizeUnicodeTitles(t *testing.T) {
+	composed := []Entry{{Metadata: adapter.Metadata{Id: "a"}, Titles: map[string]string{"en": "Café", "vi": "Tiếng"}}}
+	decomposed := []Entry{{Metadata: adapter.Metadata{Id: "a"}, Titles: map[string]string{"en": "Cafe\u0301", "vi\u0301": "Tie\u0302\u0301ng"}}}
+	for _, entries := range [][]Entry{composed, decomposed} {
+		ApplyDefaultLocale(entries, "en")
+		NormalizeUnicode(entries)
+	}
+	if decomposed[0].Title != "Café" {
+		t.Errorf("Title = %+q, want %+q", decomposed[0].Title, "Café")
+	}
+	want := map[string]string{"en": "Café", "ví": "Tiếng"}
+	if !reflect.DeepEqual(decomposed[0].Titles, want) {
+		t.Errorf("Titles = %+q, want %+q", decomposed[0].Titles, want)
+	}
+	composed[0].Titles = want
+	if !reflect.DeepEqual(composed, decomposed) {
+		t.Errorf("composed %+v and decomposed %+v spellings differ after normalization", composed, decomposed)
+	}
+}
+
+func TestNormalizeUnicodeKeepsASCII(t *testing.T) {
+	entries := []Entry{{Metadata: adapter.Metadata{Id: "plain", Title: "Plain Title", Author: "bob <bob@example.com>"}}}
+	NormalizeUnicode(entries)
+	if entries[0].Title != "Plain Title" || entries[0].Author != "bob <bob@example.com>" {
+		t.Errorf("ASCII fields changed: %+v", entries[0].Metadata)
+	}
+}
+
+// validEntry 返回一个能通过默认校验规则的条目
+func validEntry(id string) Entry {
+	return Entry{Metadata: adapter.Metadata{
+		Id:          id,
+		Title:       "Title",
+		Type:        adapter.TypeOfficial,
+		Version:     "1.0.0",
+		Author:      "bob",
+		Description: "A description.",
+	}}
+}
+
+// findingsByRule 返回指定规则的校验结果
+func findingsByRule(findings []Finding, rule string) []Finding {
+	var matched []Finding
+	for _, f := range findings {
+		if f.Rule == rule {
+			matched = append(matched, f)
+		}
+	}
+	return matched
+}
+
+func TestValidateWhitespace(t *testing.T) {
+	tests := []struct {
+		name    string
+		edit    func(e *Entry)
+		message string
+	}{
+		{"leading space", func(e *Entry) { e.Title = " Title" }, "adapter 'a' field Title has leading or trailing whitespace"},
+		{"trailing space", func(e *Entry) { e.Author = "bob " }, "adapter 'a' field Author has leading or trailing whitespace"},
+		{"trailing newline", func(e *Entry) { e.Description = "A description.\n" }, "adapter 'a' field Description has leading or trailing whitespace"},
+		{"leading tab in Id", func(e *Entry) { e.Id = "\ta" }, "adapter '\ta' field Id has leading or trailing whitespace"},
+		{"double space", func(e *Entry) { e.Description = "A  description." }, "adapter 'a' field Description has repeated internal spaces"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := validEntry("a")
+			tt.edit(&entry)
+			found := findingsByRule(Validate([]Entry{entry}, ValidateOptions{}), RuleWhitespace)
+			if len(found) != 1 || found[0].Message != tt.message {
+				t.Fatalf("whitespace findings = %+v, want one with message %q", found, tt.message)
+			}
+
+			entries := []Entry{entry}
+			TrimWhitespace(entries)
+			if found := findingsByRule(Validate(entries, ValidateOptions{}), RuleWhitespace); len(found) != 0 {
+				t.Errorf("after TrimWhitespace: findings = %+v, want none", found)
+			}
+			if want := validEntry("a"); entries[0].Metadata != want.Metadata {
+				t.Errorf("after TrimWhitespace: %+v, want %+v", entries[0].Metadata, want.Metadata)
+			}
+		})
+	}
+}
+
+func TestValidateWhitespaceAllowsSingleSpaces(t *testing.T) {
+	entry := validEntry("a")
+	entry.Title = "Two Words"
+	entry.Description = "Line one.\nLine two."
+	if found := findingsByRule(Validate([]Entry{entry}, ValidateOptions{}), RuleWhitespace); len(found) != 0 {
+		t.Errorf("findings = %+v, want none", found)
+	}
+}
+
+func TestValidateDuplicateIdIgnoreCase(t *testing.T) {
+	entries := []Entry{validEntry("Spotify"), validEntry("deezer"), validEntry("spotify")}
+
+	if found := findingsByRule(Validate(entries, ValidateOptions{}), RuleDuplicateId); len(found) != 0 {
+		t.Errorf("case-sensitive findings = %+v, want none", found)
+	}
+
+	found := findingsByRule(Validate(entries, ValidateOptions{IgnoreCaseId: true}), RuleDuplicateId)
+	if len(found) != 1 {
+		t.Fatalf("findings = %+v, want one", found)
+	}
+	if want := "adapter Ids 'Spotify' and 'spotify' differ only in case"; found[0].Message != want || found[0].Index != 2 {
+		t.Errorf("finding = %+v, want %q at index 2", found[0], want)
+	}
+}
+
+func TestValidatePoorDescription(t *testing.T) {
+	tests := []struct {
+		description string
+		// want 报告的问题，为空表示没有问题
+		want string
+	}{
+		{"Streams music from Deezer.", ""},
+		{"Exactly 10", ""},
+		{"Too short", "adapter 'a' has a poor description: 9 characters, at least 10 required"},
+		{"", "adapter 'a' has a poor description: 0 characters, at least 10 required"},
+		// 长度按字符而非字节计算
+		{"音乐适配器，支持歌词搜索", ""},
+		{"  padded  ", "adapter 'a' has a poor description: 6 characters, at least 10 required"},
+		{"TODO", "adapter 'a' has a poor description: 4 characters, at least 10 required; placeholder text \"TODO\""},
+		{"Description", "adapter 'a' has a poor description: placeholder text \"Description\""},
+		{"[placeholder]", "adapter 'a' has a poor description: placeholder text \"[placeholder]\""},
+		{"fixme!!!!!!", "adapter 'a' has a poor description: placeholder text \"fixme!!!!!!\""},
+	}
+	for _, tt := range tests {
+		entry := validEntry("a")
+		entry.Description = tt.description
+		found := findingsByRule(Validate([]Entry{entry}, ValidateOptions{MinDescriptionLen: 10}), RulePoorDescription)
+		switch {
+		case tt.want == "" && len(found) != 0:
+			t.Errorf("description %q: findings = %+v, want none", tt.description, found)
+		case tt.want != "" && (len(found) != 1 || found[0].Message != tt.want):
+			t.Errorf("description %q: findings = %+v, want %q", tt.description, found, tt.want)
+		}
+	}
+}
+
+func TestValidatePoorDescriptionDisabled(t *testing.T) {
+	entry := validEntry("a")
+	entry.Description = "TODO"
+	if found := findingsByRule(Validate([]Entry{entry}, ValidateOptions{}), RulePoorDescription); len(found) != 0 {
+		t.Errorf("findings = %+v, want none without MinDescriptionLen", found)
+	}
+}
+
+func TestValidateDescriptionStyle(t *testing.T) {
+	tests := []struct {
+		description string
+		// want 报告的问题，为空表示没有问题
+		want string
+	}{
+		{"Streams music from Deezer.", ""},
+		{"Does it stream?", ""},
+		{"支持歌词搜索。", ""},
+		// 以非字母开头的描述不要求大写
+		{"3rd-party client for Tidal.", ""},
+		{"", ""},
+		{"streams music from Deezer.", "adapter 'a' description does not start with a capital letter"},
+		{"Streams music from Deezer", "adapter 'a' description does not end with a period"},
+		{"streams music from Deezer", "adapter 'a' description does not start with a capital letter and does not end with a period"},
+		{"écoute de la musique", "adapter 'a' description does not start with a capital letter and does not end with a period"},
+	}
+	for _, tt := range tests {
+		entry := validEntry("a")
+		entry.Description = tt.description
+		found := findingsByRule(Validate([]Entry{entry}, ValidateOptions{DescriptionStyle: true}), RuleDescriptionStyle)
+		switch {
+		case tt.want == "" && len(found) != 0:
+			t.Errorf("description %q: findings = %+v, want none", tt.description, found)
+		case tt.want != "" && (len(found) != 1 || found[0].Message != tt.want):
+			t.Errorf("description %q: findings = %+v, want %q", tt.description, found, tt.want)
+		}
+	}
+}
+
+func TestValidateDescriptionStyleDisabled(t *testing.T) {
+	entry := validEntry("a")
+	entry.Description = "streams music"
+	if found := findingsByRule(Validate([]Entry{entry}, ValidateOptions{}), RuleDescriptionStyle); len(found) != 0 {
+		t.Errorf("findings = %+v, want none without DescriptionStyle", found)
+	}
+}
+
+func TestFixDescriptionStyle(t *testing.T) {
+	tests := []struct{ description, want string }{
+		{"streams music", "Streams music."},
+		{"Streams music", "Streams music."},
+		{"streams music!", "Streams music!"},
+		{"Streams music.", "Streams music."},
+		{"écoute de la musique", "Écoute de la musique."},
+		{"3rd-party client", "3rd-party client."},
+		{"  streams music \n", "  Streams music."},
+		{"", ""},
+		{"   ", "   "},
+	}
+	for _, tt := range tests {
+		entries := []Entry{validEntry("a")}
+		entries[0].Description = tt.description
+		FixDescriptionStyle(entries)
+		if got := entries[0].Description; got != tt.want {
+			t.Errorf("FixDescriptionStyle(%q) = %q, want %q", tt.description, got, tt.want)
+		}
+		if found := findingsByRule(Validate(entries, ValidateOptions{DescriptionStyle: true}), RuleDescriptionStyle); len(found) != 0 {
+			t.Errorf("after fixing %q: findings = %+v, want none", tt.description, found)
+		}
+	}
+}
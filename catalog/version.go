@@ -0,0 +1,23 @@
+package catalog
+
+import "strconv"
+
+// Version 语义化版本号的核心部分
+type Version struct {
+	Major, Minor, Patch int
+	// Prerelease 预发布标识，如 1.0.0-beta.1 中的 beta.1
+	Prerelease string
+}
+
+// ParseVersion 解析语义化版本号，不合法时 ok 为 false
+func ParseVersion(v string) (version Version, ok bool) {
+	m := semverPattern.FindStringSubmatch(v)
+	if m == nil {
+		return Version{}, false
+	}
+	version.Major, _ = strconv.Atoi(m[1])
+	version.Minor, _ = strconv.Atoi(m[2])
+	version.Patch, _ = strconv.Atoi(m[3])
+	version.Prerelease = m[4]
+	return version, true
+}
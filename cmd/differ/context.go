@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+	"github.com/meloshub/meloshub-tools/metadiff"
+)
+
+// JSON 报告的详细程度
+const (
+	// contextMinimal 只包含 Id 与变动类型，更新条目附带发生变化的字段名
+	contextMinimal = "minimal"
+	// contextStandard 包含变动前后的完整条目
+	contextStandard = "standard"
+	// contextFull 在 standard 的基础上为每个更新附带逐字段的变动，并列出未变化的适配器
+	contextFull = "full"
+)
+
+// minimalUpdate minimal 报告中的单个更新
+type minimalUpdate struct {
+	Id     string   `json:"id"`
+	Fields []string `json:"fields"`
+}
+
+// minimalIdChange minimal 报告中的单个重命名或移动
+type minimalIdChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// minimalReport minimal 详细程度下的报告
+type minimalReport struct {
+	Envelope         []metadiff.FieldChange `json:"envelope,omitempty"`
+	Added            []string               `json:"added"`
+	Removed          []string               `json:"removed"`
+	ExpectedRemovals []string               `json:"expected_removals,omitempty"`
+	Renamed          []minimalIdChange      `json:"renamed,omitempty"`
+	Moved            []minimalIdChange      `json:"moved,omitempty"`
+	Updated          []minimalUpdate        `json:"updated"`
+	VersionBumps     []string               `json:"version_bumps,omitempty"`
+	TypeChanges      []minimalUpdate        `json:"type_changes,omitempty"`
+	OwnershipChanges []string               `json:"ownership_changes"`
+}
+
+// fullUpdate full 报告中的单个更新，附带逐字段的变动
+type fullUpdate struct {
+	metadiff.UpdateEntry
+	Changes []metadiff.FieldChange `json:"changes"`
+}
+
+// fullReport full 详细程度下的报告，更新列表替换为带有字段变动的版本
+type fullReport struct {
+	metadiff.ChangeReport
+	Renamed          []fullUpdate `json:"renamed,omitempty"`
+	Updated          []fullUpdate `json:"updated"`
+	TypeChanges      []fullUpdate `json:"type_changes,omitempty"`
+	OwnershipChanges []fullUpdate `json:"ownership_changes"`
+}
+
+// groupedReport standard 报告按作者分组后的形式，新增、移除与更新替换为作者分组的列表
+type groupedReport struct {
+	metadiff.ChangeReport
+	Added   []authorGroup[catalog.Entry]        `json:"added"`
+	Removed []authorGroup[catalog.Entry]        `json:"removed"`
+	Updated []authorGroup[metadiff.UpdateEntry] `json:"updated"`
+}
+
+// groupedFullReport full 报告按作者分组后的形式
+type groupedFullReport struct {
+	fullReport
+	Added   []authorGroup[catalog.Entry] `json:"added"`
+	Removed []authorGroup[catalog.Entry] `json:"removed"`
+	Updated []authorGroup[fullUpdate]    `json:"updated"`
+}
+
+// groupedMinimalReport minimal 报告按作者分组后的形式
+type groupedMinimalReport struct {
+	minimalReport
+	Added   []authorGroup[string]        `json:"added"`
+	Removed []authorGroup[string]        `json:"removed"`
+	Updated []authorGroup[minimalUpdate] `json:"updated"`
+}
+
+// renderJSON 按详细程度将报告渲染为 JSON，byAuthor 为 true 时新增、移除与更新按作者嵌套
+func renderJSON(report metadiff.ChangeReport, context string, byAuthor bool) ([]byte, error) {
+	switch context {
+	case contextMinimal:
+		minimal := minimizeReport(report)
+		if byAuthor {
+			return json.MarshalIndent(groupedMinimalReport{
+				minimalReport: minimal,
+				Added:         groupAuthors(minimal.Added, func(i int) string { return report.Added[i].Author }),
+				Removed:       groupAuthors(minimal.Removed, func(i int) string { return report.Removed[i].Author }),
+				Updated:       groupAuthors(minimal.Updated, func(i int) string { return report.Updated[i].After.Author }),
+			}, "", "  ")
+		}
+		return json.MarshalIndent(minimal, "", "  ")
+	case contextStandard:
+		if byAuthor {
+			return json.MarshalIndent(groupedReport{
+				ChangeReport: report,
+				Added:        groupedEntries(report.Added),
+				Removed:      groupedEntries(report.Removed),
+				Updated:      groupedUpdates(report.Updated),
+			}, "", "  ")
+		}
+		return json.MarshalIndent(report, "", "  ")
+	case contextFull:
+		full := fullReport{
+			ChangeReport:     report,
+			Renamed:          withChanges(report.Renamed),
+			Updated:          withChanges(report.Updated),
+			TypeChanges:      withChanges(report.TypeChanges),
+			OwnershipChanges: withChanges(report.OwnershipChanges),
+		}
+		if byAuthor {
+			return json.MarshalIndent(groupedFullReport{
+				fullReport: full,
+				Added:      groupedEntries(report.Added),
+				Removed:    groupedEntries(report.Removed),
+				Updated:    groupAuthors(full.Updated, func(i int) string { return full.Updated[i].After.Author }),
+			}, "", "  ")
+		}
+		return json.MarshalIndent(full, "", "  ")
+	default:
+		return nil, fmt.Errorf("unknown diff context %q (expected %s, %s or %s)", context, contextMinimal, contextStandard, contextFull)
+	}
+}
+
+// minimizeReport 将报告缩减为只包含 Id 与变动类型的形式
+func minimizeReport(report metadiff.ChangeReport) minimalReport {
+	minimal := minimalReport{
+		Added:            []string{},
+		Removed:          []string{},
+		Updated:          []minimalUpdate{},
+		OwnershipChanges: []string{},
+		Envelope:         report.Envelope,
+	}
+	for _, e := range report.Added {
+		minimal.Added = append(minimal.Added, e.Id)
+	}
+	for _, e := range report.Removed {
+		minimal.Removed = append(minimal.Removed, e.Id)
+	}
+	for _, e := range report.ExpectedRemovals {
+		minimal.ExpectedRemovals = append(minimal.ExpectedRemovals, e.Id)
+	}
+	for _, u := range report.Renamed {
+		minimal.Renamed = append(minimal.Renamed, minimalIdChange{From: u.Before.Id, To: u.After.Id})
+	}
+	for _, m := range report.Moved {
+		minimal.Moved = append(minimal.Moved, minimalIdChange{From: m.From, To: m.To})
+	}
+	for _, u := range report.Updated {
+		minimal.Updated = append(minimal.Updated, minimizeUpdate(u))
+	}
+	for _, u := range report.TypeChanges {
+		minimal.TypeChanges = append(minimal.TypeChanges, minimizeUpdate(u))
+	}
+	for _, bump := range report.VersionBumps {
+		minimal.VersionBumps = append(minimal.VersionBumps, bump.Id)
+	}
+	for _, u := range report.OwnershipChanges {
+		minimal.OwnershipChanges = append(minimal.OwnershipChanges, u.After.Id)
+	}
+	return minimal
+}
+
+// minimizeUpdate 将更新缩减为 Id 与发生变化的字段名
+func minimizeUpdate(u metadiff.UpdateEntry) minimalUpdate {
+	update := minimalUpdate{Id: u.After.Id, Fields: []string{}}
+	for _, change := range metadiff.ChangedFields(u.Before, u.After) {
+		update.Fields = append(update.Fields, change.Field)
+	}
+	if u.Capabilities != nil {
+		update.Fields = append(update.Fields, "capabilities")
+	}
+	if u.Titles != nil {
+		update.Fields = append(update.Fields, "titles")
+	}
+	return update
+}
+
+// withChanges 为每个更新附带逐字段的变动
+func withChanges(updates []metadiff.UpdateEntry) []fullUpdate {
+	full := make([]fullUpdate, 0, len(updates))
+	for _, u := range updates {
+		changes := metadiff.ChangedFields(u.Before, u.After)
+		if changes == nil {
+			changes = []metadiff.FieldChange{}
+		}
+		full = append(full, fullUpdate{UpdateEntry: u, Changes: changes})
+	}
+	return full
+}
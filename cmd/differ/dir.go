@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+	"github.com/meloshub/meloshub-tools/diagnostics"
+	"gopkg.in/yaml.v3"
+)
+
+// readMetadata 读取 --old 或 --new 指向的元数据，path 为目录时按 readMetadataDir 读取逐个适配器的文件，
+// 否则按 catalog.ReadFileMeta 读取单个文件；目录没有信封头部
+// 路径不存在时返回的错误满足 errors.Is(err, os.ErrNotExist)
+func readMetadata(path string, diags *diagnostics.Diagnostics) ([]catalog.Entry, *catalog.EnvelopeMeta, error) {
+	info, err := os.Stat(path)
+	if err == nil && info.IsDir() {
+		entries, err := readMetadataDir(path, diags)
+		return entries, nil, err
+	}
+	return catalog.ReadFileMeta(path)
+}
+
+// readMetadataDir 读取目录中每个 *.yaml 文件，每个文件包含一个适配器（即 metagen --per-adapter-dir 的输出），
+// 按文件名顺序返回；不含子目录。无法解析或不含适配器的文件会逐个输出并使整个读取失败，
+// 以免被跳过的适配器在比较中显示为已移除
+func readMetadataDir(dir string, diags *diagnostics.Diagnostics) ([]catalog.Entry, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	entries := []catalog.Entry{}
+	var failed []string
+	for _, path := range paths {
+		entry, err := readAdapterFile(path)
+		if err != nil {
+			diags.Errorf("", token.Position{Filename: path}, "%v", err)
+			failed = append(failed, filepath.Base(path))
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if len(failed) > 0 {
+		return nil, fmt.Errorf("%d files in %s could not be read: %s", len(failed), dir, strings.Join(failed, ", "))
+	}
+	if len(paths) == 0 {
+		diags.Warnf("", token.Position{Filename: dir}, "directory %s contains no .yaml files.", dir)
+	}
+	return entries, nil
+}
+
+// readAdapterFile 解析只包含一个适配器的 YAML 文件
+func readAdapterFile(path string) (catalog.Entry, error) {
+	var entry catalog.Entry
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return entry, fmt.Errorf("could not read adapter file %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, &entry); err != nil {
+		return entry, fmt.Errorf("could not parse adapter file %s: %w", path, err)
+	}
+	if entry.Id == "" {
+		return entry, fmt.Errorf("adapter file %s contains no adapter with an id", path)
+	}
+	return entry, nil
+}
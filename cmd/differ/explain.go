@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+	"github.com/meloshub/meloshub-tools/metadiff"
+)
+
+// explainAdapter 输出单个适配器在新旧元数据之间的详细比较，未变化的字段也会列出以便对照
+// 与 Compare 一致，同一 Id 出现多次时以最后一个条目为准
+func explainAdapter(w io.Writer, id string, oldList, newList []catalog.Entry) error {
+	before, inOld := findEntry(oldList, id)
+	after, inNew := findEntry(newList, id)
+
+	switch {
+	case !inOld && !inNew:
+		return fmt.Errorf("adapter '%s' is not present in either the old or the new metadata", id)
+	case !inOld:
+		fmt.Fprintf(w, "%s: added\n", id)
+		for _, f := range metadiff.CompareFields(before, after) {
+			fmt.Fprintf(w, "  %s: %q\n", f.Field, f.After)
+		}
+		writeCapabilities(w, after.Capabilities)
+		writeTitles(w, after.Titles)
+		return nil
+	case !inNew:
+		fmt.Fprintf(w, "%s: removed\n", id)
+		for _, f := range metadiff.CompareFields(before, after) {
+			fmt.Fprintf(w, "  %s: %q\n", f.Field, f.Before)
+		}
+		writeCapabilities(w, before.Capabilities)
+		writeTitles(w, before.Titles)
+		return nil
+	}
+
+	fields := metadiff.CompareFields(before, after)
+	capabilities := metadiff.CompareCapabilities(before, after)
+	titles := metadiff.CompareTitles(before, after)
+	status := "unchanged"
+	if len(metadiff.ChangedFields(before, after)) > 0 || capabilities != nil || titles != nil {
+		status = "updated"
+	}
+	fmt.Fprintf(w, "%s: %s\n", id, status)
+	for _, f := range fields {
+		if f.Equal {
+			fmt.Fprintf(w, "  %s: %q (unchanged)\n", f.Field, f.After)
+		} else {
+			fmt.Fprintf(w, "  %s: %q -> %q\n", f.Field, f.Before, f.After)
+		}
+	}
+
+	names := make(map[string]bool)
+	for name := range before.Capabilities {
+		names[name] = true
+	}
+	for name := range after.Capabilities {
+		names[name] = true
+	}
+	for _, name := range sortedKeys(names) {
+		b, inBefore := before.Capabilities[name]
+		a, inAfter := after.Capabilities[name]
+		switch {
+		case !inBefore:
+			fmt.Fprintf(w, "  capability %s: added (%t)\n", name, a)
+		case !inAfter:
+			fmt.Fprintf(w, "  capability %s: removed (was %t)\n", name, b)
+		case a != b:
+			fmt.Fprintf(w, "  capability %s: %t -> %t\n", name, b, a)
+		default:
+			fmt.Fprintf(w, "  capability %s: %t (unchanged)\n", name, a)
+		}
+	}
+
+	locales := make(map[string]bool)
+	for locale := range before.Titles {
+		locales[locale] = true
+	}
+	for locale := range after.Titles {
+		locales[locale] = true
+	}
+	for _, locale := range sortedKeys(locales) {
+		b, inBefore := before.Titles[locale]
+		a, inAfter := after.Titles[locale]
+		switch {
+		case !inBefore:
+			fmt.Fprintf(w, "  title[%s]: added (%q)\n", locale, a)
+		case !inAfter:
+			fmt.Fprintf(w, "  title[%s]: removed (was %q)\n", locale, b)
+		case a != b:
+			fmt.Fprintf(w, "  title[%s]: %q -> %q\n", locale, b, a)
+		default:
+			fmt.Fprintf(w, "  title[%s]: %q (unchanged)\n", locale, a)
+		}
+	}
+	return nil
+}
+
+// findEntry 返回列表中最后一个 Id 匹配的条目
+func findEntry(list []catalog.Entry, id string) (catalog.Entry, bool) {
+	for i := len(list) - 1; i >= 0; i-- {
+		if list[i].Id == id {
+			return list[i], true
+		}
+	}
+	return catalog.Entry{}, false
+}
+
+// writeCapabilities 按名称顺序输出功能开关
+func writeCapabilities(w io.Writer, capabilities map[string]bool) {
+	names := make(map[string]bool)
+	for name := range capabilities {
+		names[name] = true
+	}
+	for _, name := range sortedKeys(names) {
+		fmt.Fprintf(w, "  capability %s: %t\n", name, capabilities[name])
+	}
+}
+
+// writeTitles 按语言代码顺序输出本地化标题
+func writeTitles(w io.Writer, titles map[string]string) {
+	locales := make(map[string]bool)
+	for locale := range titles {
+		locales[locale] = true
+	}
+	for _, locale := range sortedKeys(locales) {
+		fmt.Fprintf(w, "  title[%s]: %q\n", locale, titles[locale])
+	}
+}
+
+// sortedKeys 返回集合中按字典序排列的键
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+	"github.com/meloshub/meloshub-tools/metadiff"
+)
+
+// Atom 订阅源中用到的最小结构子集，参见 RFC 4287
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Id      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	Id      string     `xml:"id"`
+	Title   string     `xml:"title"`
+	Updated string     `xml:"updated"`
+	Author  atomAuthor `xml:"author"`
+	Summary string     `xml:"summary"`
+}
+
+type atomAuthor struct {
+	Name  string `xml:"name"`
+	Email string `xml:"email,omitempty"`
+}
+
+// writeFeed 将变动报告渲染为 Atom 订阅源，每个新增的适配器对应一个条目
+// includeAll 为 true 时移除、重命名、移动与更新的适配器也会生成条目；所有内容均由 encoding/xml 转义
+func writeFeed(path string, report metadiff.ChangeReport, now time.Time, includeAll bool) error {
+	updated := now.UTC().Format(time.RFC3339)
+	feed := atomFeed{
+		Id:      "urn:meloshub:catalog:changes",
+		Title:   "Meloshub Adapter Catalog Changes",
+		Updated: updated,
+	}
+
+	newEntry := func(kind, verb string, e catalog.Entry, summary string) atomEntry {
+		author := atomAuthor{Name: e.Author}
+		if parsed, err := catalog.ParseAuthor(e.Author); err == nil {
+			author = atomAuthor{Name: parsed.Name, Email: parsed.Email}
+		}
+		if author.Name == "" {
+			// Atom 要求 author 必须包含 name
+			author.Name = "unknown"
+		}
+		return atomEntry{
+			Id:      fmt.Sprintf("urn:meloshub:adapter:%s:%s:%s:%d", e.Id, kind, e.Version, now.Unix()),
+			Title:   fmt.Sprintf("%s %s (%s) %s", verb, e.Title, e.Id, e.Version),
+			Updated: updated,
+			Author:  author,
+			Summary: summary,
+		}
+	}
+
+	for _, e := range report.Added {
+		summary := fmt.Sprintf("New adapter %s (%s) version %s by %s.", e.Title, e.Id, e.Version, e.Author)
+		if e.Description != "" {
+			summary += " " + e.Description
+		}
+		feed.Entries = append(feed.Entries, newEntry("added", "Added", e, summary))
+	}
+
+	if includeAll {
+		for _, u := range report.Updated {
+			summary := fmt.Sprintf("Adapter %s (%s) updated from version %s to %s.", u.After.Title, u.After.Id, u.Before.Version, u.After.Version)
+			feed.Entries = append(feed.Entries, newEntry("updated", "Updated", u.After, summary))
+		}
+		for _, e := range report.Removed {
+			summary := fmt.Sprintf("Adapter %s (%s) version %s was removed.", e.Title, e.Id, e.Version)
+			feed.Entries = append(feed.Entries, newEntry("removed", "Removed", e, summary))
+		}
+		for _, u := range report.Renamed {
+			summary := fmt.Sprintf("Adapter %s was renamed from %s to %s.", u.After.Title, u.Before.Id, u.After.Id)
+			feed.Entries = append(feed.Entries, newEntry("renamed", "Renamed", u.After, summary))
+		}
+		for _, m := range report.Moved {
+			summary := fmt.Sprintf("Adapter %s was moved from %s to %s without other changes.", m.Entry.Title, m.From, m.To)
+			feed.Entries = append(feed.Entries, newEntry("moved", "Moved", m.Entry, summary))
+		}
+	}
+
+	data, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not marshal Atom feed: %w", err)
+	}
+	data = append([]byte(xml.Header), data...)
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/meloshub/meloshub-tools/metadiff"
+)
+
+// --fail-on 可以指定的变动类型
+const (
+	failOnAdded     = "added"
+	failOnRemoved   = "removed"
+	failOnUpdated   = "updated"
+	failOnOwnership = "ownership"
+	failOnRenamed   = "renamed"
+	failOnMoved     = "moved"
+)
+
+// parseFailOn 解析 --fail-on 指定的变动类型集合
+func parseFailOn(values []string) (map[string]bool, error) {
+	kinds := make(map[string]bool)
+	for _, kind := range splitList(values) {
+		switch kind {
+		case failOnAdded, failOnRemoved, failOnUpdated, failOnOwnership, failOnRenamed, failOnMoved:
+			kinds[kind] = true
+		default:
+			return nil, fmt.Errorf("unknown change kind %q (expected %s, %s, %s, %s, %s or %s)", kind, failOnAdded, failOnRemoved, failOnUpdated, failOnOwnership, failOnRenamed, failOnMoved)
+		}
+	}
+	return kinds, nil
+}
+
+// failOnViolations 描述报告中属于 kinds 的变动，预期内的移除不计入
+func failOnViolations(report metadiff.ChangeReport, kinds map[string]bool) []string {
+	var violations []string
+	if kinds[failOnAdded] && len(report.Added) > 0 {
+		violations = append(violations, fmt.Sprintf("%d added", len(report.Added)))
+	}
+	if kinds[failOnRemoved] && len(report.Removed) > 0 {
+		violations = append(violations, fmt.Sprintf("%d removed", len(report.Removed)))
+	}
+	if n := report.UpdatedCount(); kinds[failOnUpdated] && n > 0 {
+		violations = append(violations, fmt.Sprintf("%d updated", n))
+	}
+	if kinds[failOnRenamed] && len(report.Renamed) > 0 {
+		violations = append(violations, fmt.Sprintf("%d renamed", len(report.Renamed)))
+	}
+	if kinds[failOnMoved] && len(report.Moved) > 0 {
+		violations = append(violations, fmt.Sprintf("%d moved", len(report.Moved)))
+	}
+	if kinds[failOnOwnership] && len(report.OwnershipChanges) > 0 {
+		violations = append(violations, fmt.Sprintf("%d ownership changes", len(report.OwnershipChanges)))
+	}
+	return violations
+}
+
+// readIdList 展开逗号分隔的 Id 列表；以 @ 开头的值视为文件，每行一个 Id，# 之后为注释
+func readIdList(values []string) ([]string, error) {
+	var ids []string
+	for _, value := range splitList(values) {
+		if !strings.HasPrefix(value, "@") {
+			ids = append(ids, value)
+			continue
+		}
+
+		path := strings.TrimPrefix(value, "@")
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not open Id list %s: %w", path, err)
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line, _, _ := strings.Cut(scanner.Text(), "#")
+			if line = strings.TrimSpace(line); line != "" {
+				ids = append(ids, line)
+			}
+		}
+		err = scanner.Err()
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("could not read Id list %s: %w", path, err)
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+	"github.com/meloshub/meloshub-tools/metadiff"
+)
+
+// readBaselineFromGit 通过 git show <ref>:<path> 读取旧元数据，平铺列表与信封格式均可
+// path 相对于仓库根目录；若该路径在 ref 中不存在，则与旧文件缺失时一样视为空列表
+func readBaselineFromGit(ref, path string) ([]catalog.Entry, *catalog.EnvelopeMeta, error) {
+	data, err := readBaselineBytes(ref, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if data == nil {
+		return []catalog.Entry{}, nil, nil
+	}
+
+	object := ref + ":" + path
+	entries, meta, err := catalog.DecodeYAML(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not parse baseline %s: %w", object, err)
+	}
+	if duplicates := metadiff.DuplicateIds(entries); len(duplicates) > 0 {
+		return nil, nil, fmt.Errorf("duplicate adapter Id '%s' found in baseline %s", duplicates[0], object)
+	}
+	return entries, meta, nil
+}
+
+// readBaselineBytes 通过 git show <ref>:<path> 读取基线文件的原始内容，路径在 ref 中不存在时返回 nil
+func readBaselineBytes(ref, path string) ([]byte, error) {
+	if _, err := runGit("rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+		return nil, fmt.Errorf("unknown git ref %q: %w", ref, err)
+	}
+
+	object := ref + ":" + path
+	if _, err := runGit("cat-file", "-e", object); err != nil {
+		log.Printf("Baseline '%s' does not exist. Assuming all new adapters are 'Added'.", object)
+		return nil, nil
+	}
+	return runGit("show", object)
+}
+
+// runGit 执行 git 命令并返回标准输出，失败时错误信息中附带标准错误输出
+func runGit(args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return stdout.Bytes(), nil
+}
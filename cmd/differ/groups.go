@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+	"github.com/meloshub/meloshub-tools/metadiff"
+)
+
+// groupByAuthor --group-by 唯一支持的分组方式，按作者嵌套新增、移除与更新的适配器
+const groupByAuthor = "author"
+
+// parseGroupBy 解析 --group-by，返回是否按作者分组
+func parseGroupBy(value string) (bool, error) {
+	switch value {
+	case "":
+		return false, nil
+	case groupByAuthor:
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown grouping %q (expected %s)", value, groupByAuthor)
+	}
+}
+
+// authorGroup 同一位作者的适配器
+type authorGroup[T any] struct {
+	// Author 规范化后的作者，形如 "Name <email>"；没有作者的适配器归入空字符串
+	Author   string `json:"author"`
+	Adapters []T    `json:"adapters"`
+}
+
+// groupAuthors 将 items 按作者分组，authorOf 返回第 i 项的作者字段
+// 作者按 metadiff.AuthorKeys 规范化，拥有多位作者的适配器出现在每位作者下；
+// 分组按作者排序，没有作者的分组排在最后，组内保持 items 原有的顺序
+func groupAuthors[T any](items []T, authorOf func(i int) string) []authorGroup[T] {
+	groups := []authorGroup[T]{}
+	index := make(map[string]int)
+	for i, item := range items {
+		for _, author := range metadiff.AuthorKeys(authorOf(i)) {
+			n, ok := index[author]
+			if !ok {
+				n = len(groups)
+				index[author] = n
+				groups = append(groups, authorGroup[T]{Author: author})
+			}
+			groups[n].Adapters = append(groups[n].Adapters, item)
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if (groups[i].Author == "") != (groups[j].Author == "") {
+			return groups[j].Author == ""
+		}
+		return groups[i].Author < groups[j].Author
+	})
+	return groups
+}
+
+// groupedEntries 按作者分组条目
+func groupedEntries(entries []catalog.Entry) []authorGroup[catalog.Entry] {
+	return groupAuthors(entries, func(i int) string { return entries[i].Author })
+}
+
+// groupedUpdates 按变动后的作者分组更新
+func groupedUpdates(updates []metadiff.UpdateEntry) []authorGroup[metadiff.UpdateEntry] {
+	return groupAuthors(updates, func(i int) string { return updates[i].After.Author })
+}
+
+// authorHeading 返回分组标题中显示的作者，没有作者时显示 (no author)
+func authorHeading(author string) string {
+	if author == "" {
+		return "(no author)"
+	}
+	return author
+}
@@ -4,108 +4,389 @@ import (
 	"encoding/json"
 	"errors"
 	"flag"
+	"fmt"
+	"go/token"
 	"log"
 	"os"
+	"strings"
+	"time"
 
-	"github.com/meloshub/meloshub/adapter"
-	"gopkg.in/yaml.v3"
+	"github.com/meloshub/meloshub-tools/catalog"
+	"github.com/meloshub/meloshub-tools/diagnostics"
+	"github.com/meloshub/meloshub-tools/metadiff"
 )
 
-type UpdateEntry struct {
-	Before adapter.Metadata `json:"before"`
-	After  adapter.Metadata `json:"after"`
+// stringList 可重复指定的字符串参数
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
 }
-type ChangeReport struct {
-	Added   []adapter.Metadata `json:"added"`
-	Removed []adapter.Metadata `json:"removed"`
-	Updated []UpdateEntry      `json:"updated"`
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
 }
 
 func main() {
-	oldFile := flag.String("old", "", "Path to the old metadata YAML file")
-	newFile := flag.String("new", "", "Path to the new metadata YAML file")
-	outputFile := flag.String("output", "changes.json", "Path to the output JSON report file")
+	var oldFiles stringList
+	flag.Var(&oldFiles, "old", "Path to an old metadata YAML file, flat list or envelope, or a directory of per-adapter <id>.yaml files; may be repeated, inputs are merged and duplicate Ids across them are an error")
+	newFile := flag.String("new", "", "Path to the new metadata YAML file, flat list or envelope, or a directory of per-adapter <id>.yaml files")
+	baselineRef := flag.String("baseline-ref", "", "Read the old metadata from this git ref when --old is not given")
+	baselinePath := flag.String("baseline-path", "adapters.yaml", "Repository-relative path of the metadata file at --baseline-ref")
+	outputFile := flag.String("output", "changes.json", "Path to the output report file, or - for stdout")
+	format := flag.String("format", formatJSON, "Report format: json, text or gh-comment")
+	groupBy := flag.String("group-by", "", "Nest the added, removed and updated adapters of the report under each author: author (JSON groups become {author, adapters} objects, Markdown and text get per-author subheadings)")
+	summaryOnly := flag.Bool("summary-only", false, "Write only the change counts and overall severity to --output, without per-adapter detail: a one-line summary with --format text, a small object with --format json")
+	jsonPointer := flag.Bool("json-pointer", false, "Write a JSON array of {path, before, after} for every changed value of the updated adapters to --output instead of the report, with JSON Pointer paths such as /adapters/spotify/version")
+	reportTitle := flag.String("report-title", defaultReportTitle, "Top-level heading of Markdown reports (--format gh-comment), e.g. \"Catalog changes for v2.3.0\"")
+	strict := flag.Bool("strict", false, "Treat malformed input such as duplicate Ids within --new or an --old file as an error instead of a warning")
+	feedFile := flag.String("feed", "", "Also write the report as an Atom feed of added adapters to this file")
+	sinceReport := flag.String("since-report", "", "Path to a previous JSON report (standard or full context); changes identical to ones in it are left out, so only new changes are reported")
+	metricsFile := flag.String("metrics", "", "Also write added, removed, updated and total adapter counts as Prometheus text-format gauges to this file")
+	feedAll := flag.Bool("feed-all", false, "Include removed and updated adapters in the --feed output")
+	includeUnchanged := flag.Bool("include-unchanged", false, "Also list adapters present in both files without changes, for a full-state snapshot")
+	var excludes stringList
+	flag.Var(&excludes, "exclude", "Comma-separated adapter Ids or globs to leave out of the comparison entirely; may be repeated")
+	archiveDir := flag.String("archive-dir", "", "Also write a timestamped JSON copy of the report, changes-<UTC timestamp>.json, e.g. changes-20240102T150405.123456789Z.json, into this directory")
+	sortBy := flag.String("sort", metadiff.SortById, "Order of report entries: id, title or severity (updated entries ordered major→minor→patch)")
+	var expectTypes stringList
+	flag.Var(&expectTypes, "expect-types", "Comma-separated adapter Types that must each have at least one adapter in --new; may be repeated")
+	keySpec := flag.String("key", "id", "Comma-separated fields that identify the same adapter in both files, e.g. type,title; must be unique within each input")
+	groupVersionBumps := flag.Bool("group-version-bumps", false, "Report updates that only change Version in a separate version_bumps section instead of under updated")
+	compareStrategy := flag.String("compare-strategy", "", "Comma-separated field precedence, e.g. version,type,author: each update is reported only in the section of its highest-precedence changed field (version_bumps, type_changes or ownership_changes); by default an update appears in every applicable section")
+	maxBump := flag.String("max-bump", "", "Fail when any adapter changes by more than this level: patch, minor or major (removals and Type changes count as major)")
+	diffContext := flag.String("diff-context", contextStandard, "Detail of the JSON report: minimal (Ids and change kinds), standard (full before/after entries) or full (standard plus per-field changes and unchanged adapters)")
+	detectRenames := flag.Bool("detect-renames", false, "Report a removed and an added adapter with the same Title, Type and Author as a rename instead")
+	detectMoves := flag.Bool("detect-moves", false, "Report a removed and an added adapter whose content is identical apart from the Id as a move; checked before --detect-renames")
+	authorStatsFile := flag.String("author-stats", "", "Also write per-author counts of added, updated and removed adapters, sorted by total activity, as JSON to this file")
+	renameMapFile := flag.String("rename-map", "", "With --detect-renames or --detect-moves, also write a JSON object mapping each renamed or moved adapter's old Id to its new Id to this file")
+	var failOn, expectedRemovals stringList
+	flag.Var(&failOn, "fail-on", "Comma-separated change kinds that make differ exit non-zero: added, removed, updated, ownership, renamed, moved; may be repeated")
+	flag.Var(&expectedRemovals, "expected-removals", "Comma-separated Ids whose removal is sanctioned, or @file with one Id per line; such removals are reported separately and do not trip --fail-on removed")
+	textDiffMode := flag.Bool("text-diff", false, "Write a unified diff of the two raw YAML files, with mapping keys sorted, to --output (stdout unless --output is given) instead of a structured report")
+	explainId := flag.String("explain", "", "Print a detailed before/after breakdown of the adapter with this Id to stdout instead of writing a report")
 	flag.Parse()
 
-	if *oldFile == "" || *newFile == "" {
-		log.Fatal("Both --old and --new file paths are required.")
+	excludePatterns, err := metadiff.ParsePatterns(excludes)
+	if err != nil {
+		log.Fatalf("Invalid --exclude: %v", err)
+	}
+	precedence, err := metadiff.ParsePrecedence(*compareStrategy)
+	if err != nil {
+		log.Fatalf("Invalid --compare-strategy: %v", err)
+	}
+	byAuthor, err := parseGroupBy(*groupBy)
+	if err != nil {
+		log.Fatalf("Invalid --group-by: %v", err)
+	}
+
+	if *jsonPointer && (*format != formatJSON || *summaryOnly) {
+		log.Fatal("--json-pointer writes JSON and cannot be used together with --summary-only or a --format other than json.")
 	}
 
-	var oldMetadata []adapter.Metadata
-	oldData, err := os.ReadFile(*oldFile)
+	if *renameMapFile != "" && !*detectRenames && !*detectMoves {
+		log.Fatal("--rename-map requires --detect-renames or --detect-moves.")
+	}
+
+	failOnKinds, err := parseFailOn(failOn)
 	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			log.Printf("Old metadata file '%s' not found. Assuming all new adapters are 'Added'.", *oldFile)
-			oldMetadata = []adapter.Metadata{} // 将旧元数据视为空列表
-		} else {
-			// 如果是其他错误，则终止
-			log.Fatalf("Error reading old metadata file: %v", err)
+		log.Fatalf("Invalid --fail-on: %v", err)
+	}
+	expectedRemovalIds, err := readIdList(expectedRemovals)
+	if err != nil {
+		log.Fatalf("Invalid --expected-removals: %v", err)
+	}
+
+	bumpLimit := metadiff.BumpMajor
+	if *maxBump != "" {
+		bumpLimit, err = metadiff.ParseBumpKind(*maxBump)
+		if err != nil {
+			log.Fatalf("Invalid --max-bump: %v", err)
 		}
-	} else {
-		// 如果文件存在，正常解析
-		if err := yaml.Unmarshal(oldData, &oldMetadata); err != nil {
-			log.Fatalf("Could not parse old yaml file %s: %v", *oldFile, err)
+	}
+
+	var key metadiff.Key
+	if *keySpec != "id" {
+		key, err = metadiff.ParseKey(*keySpec)
+		if err != nil {
+			log.Fatalf("Invalid --key: %v", err)
 		}
 	}
 
+	if (len(oldFiles) == 0 && *baselineRef == "") || *newFile == "" {
+		log.Fatal("Both --old (or --baseline-ref) and --new are required.")
+	}
+
+	// 文本差异不解析元数据字段，因此也不受 --exclude 等选项影响
+	if *textDiffMode {
+		output := "-"
+		flag.Visit(func(f *flag.Flag) {
+			if f.Name == "output" {
+				output = *outputFile
+			}
+		})
+		if err := runTextDiff(oldFiles, *baselineRef, *baselinePath, *newFile, output); err != nil {
+			log.Fatalf("Text diff failed: %v", err)
+		}
+		return
+	}
+
+	diags := &diagnostics.Diagnostics{}
+	var (
+		oldMetadata []catalog.Entry
+		oldMeta     *catalog.EnvelopeMeta
+	)
+	if len(oldFiles) > 0 {
+		if *baselineRef != "" {
+			log.Printf("Both --old and --baseline-ref given, ignoring --baseline-ref.")
+		}
+		oldMetadata, oldMeta, err = readOldMetadata(oldFiles, *strict, diags)
+	} else {
+		oldMetadata, oldMeta, err = readBaselineFromGit(*baselineRef, *baselinePath)
+	}
+	if err != nil {
+		log.Fatalf("Error reading old metadata: %v", err)
+	}
+
 	// 读取和解析新文件
-	newData, err := os.ReadFile(*newFile)
+	newMetadata, newMeta, err := readMetadata(*newFile, diags)
 	if err != nil {
-		log.Fatalf("Error reading new metadata file: %v", err)
+		log.Fatalf("Error reading new metadata: %v", err)
+	}
+	if err := reportDuplicateIds(newMetadata, *newFile, *strict, diags); err != nil {
+		log.Fatalf("%v.", err)
 	}
-	var newMetadata []adapter.Metadata
-	if err := yaml.Unmarshal(newData, &newMetadata); err != nil {
-		log.Fatalf("Could not parse new yaml file %s: %v", *newFile, err)
+
+	// 被排除的适配器从新旧两侧同时移除，因此不会出现在报告的任何部分中
+	oldMetadata = metadiff.Exclude(oldMetadata, excludePatterns)
+	newMetadata = metadiff.Exclude(newMetadata, excludePatterns)
+
+	// 只解释单个适配器时不生成完整报告
+	if *explainId != "" {
+		if err := explainAdapter(os.Stdout, *explainId, oldMetadata, newMetadata); err != nil {
+			log.Fatalf("Explain failed: %v", err)
+		}
+		return
+	}
+
+	// 自定义匹配键时，重复的键无法确定应与哪个条目配对
+	if key != nil {
+		if err := metadiff.CheckUniqueKeys(key, oldMetadata, newMetadata); err != nil {
+			log.Fatalf("Invalid --key: %v", err)
+		}
 	}
 
 	// 比较并生成报告
-	report := compareMetadata(oldMetadata, newMetadata)
+	report := metadiff.Compare(oldMetadata, newMetadata, metadiff.Options{IncludeUnchanged: *includeUnchanged || *diffContext == contextFull, GroupVersionBumps: *groupVersionBumps, Key: key, DetectRenames: *detectRenames, DetectMoves: *detectMoves, Precedence: precedence})
+	for _, id := range report.ApplyExpectedRemovals(expectedRemovalIds) {
+		diags.Warnf(id, token.Position{}, "adapter '%s' is listed in --expected-removals but was not removed.", id)
+	}
+	if err := report.Sort(*sortBy); err != nil {
+		log.Fatalf("Invalid --sort: %v", err)
+	}
+	// 合并多个旧文件或旧文件缺失时没有可比较的头部，此时不报告文件形式的变化
+	if len(oldFiles) <= 1 && len(oldMetadata) > 0 {
+		report.Envelope = metadiff.CompareEnvelopes(oldMeta, newMeta)
+	}
+	if *sinceReport != "" {
+		prior, err := readPriorReport(*sinceReport)
+		if err != nil {
+			log.Fatalf("Error reading --since-report: %v", err)
+		}
+		report.Subtract(prior)
+	}
 
-	reportJSON, err := json.MarshalIndent(report, "", "  ")
+	var reportData []byte
+	switch {
+	case *summaryOnly:
+		reportData, err = renderSummary(report, *format, *reportTitle)
+	case *jsonPointer:
+		reportData, err = renderPointers(report)
+	default:
+		reportData, err = renderReport(report, *format, *diffContext, *reportTitle, byAuthor)
+	}
 	if err != nil {
-		log.Fatalf("Error marshalling report to JSON: %v", err)
+		log.Fatalf("Error rendering report: %v", err)
 	}
-	if err := os.WriteFile(*outputFile, reportJSON, 0644); err != nil {
+	if err := writeOutput(*outputFile, reportData); err != nil {
 		log.Fatalf("Error writing output report file: %v", err)
 	}
 	log.Printf("Successfully generated change report to %s", *outputFile)
-}
 
-// compareMetadata 比较元数据变动
-func compareMetadata(oldList, newList []adapter.Metadata) ChangeReport {
-	oldMap := make(map[string]adapter.Metadata)
-	for _, m := range oldList {
-		oldMap[m.Id] = m
+	if *renameMapFile != "" {
+		if err := writeRenameMap(*renameMapFile, report); err != nil {
+			log.Fatalf("Error writing rename map: %v", err)
+		}
+		log.Printf("Successfully generated rename map to %s", *renameMapFile)
+	}
+
+	if *authorStatsFile != "" {
+		if err := writeAuthorStats(*authorStatsFile, report, newMetadata); err != nil {
+			log.Fatalf("Error writing author stats: %v", err)
+		}
+		log.Printf("Successfully generated author stats to %s", *authorStatsFile)
+	}
+
+	if *archiveDir != "" {
+		archived, err := archiveReport(*archiveDir, report, time.Now())
+		if err != nil {
+			log.Fatalf("Error archiving report: %v", err)
+		}
+		log.Printf("Archived change report to %s", archived)
+	}
+
+	if *feedFile != "" {
+		if err := writeFeed(*feedFile, report, time.Now(), *feedAll); err != nil {
+			log.Fatalf("Error writing feed: %v", err)
+		}
+		log.Printf("Successfully generated Atom feed to %s", *feedFile)
+	}
+	if *metricsFile != "" {
+		if err := writeMetrics(*metricsFile, report, len(newMetadata)); err != nil {
+			log.Fatalf("Error writing metrics: %v", err)
+		}
+		log.Printf("Successfully generated metrics to %s", *metricsFile)
+	}
+	logSummary(report)
+
+	if violations := failOnViolations(report, failOnKinds); len(violations) > 0 {
+		log.Fatalf("Changes matched --fail-on: %s", strings.Join(violations, ", "))
 	}
 
-	newMap := make(map[string]adapter.Metadata)
-	for _, m := range newList {
-		newMap[m.Id] = m
+	if violations := bumpViolations(report, bumpLimit); len(violations) > 0 {
+		log.Fatalf("Changes exceed --max-bump %s: %s", bumpLimit, strings.Join(violations, "; "))
 	}
 
-	report := ChangeReport{}
+	if empty := emptyTypes(newMetadata, splitList(expectTypes)); len(empty) > 0 {
+		log.Fatalf("Expected adapter types with no adapters in %s: %s", *newFile, strings.Join(empty, ", "))
+	}
+}
 
-	// 适配器新增与更新检查
-	for id, newMeta := range newMap {
-		oldMeta, exists := oldMap[id]
-		if !exists {
-			// 如果旧文件中不存在此ID，视为新增的适配器
-			report.Added = append(report.Added, newMeta)
+// bumpViolations 描述变动级别超过 limit 的适配器
+func bumpViolations(report metadiff.ChangeReport, limit metadiff.BumpKind) []string {
+	var violations []string
+	renames := report.RenameMap()
+	for _, bump := range report.AdapterBumps() {
+		if bump.Kind <= limit {
+			continue
+		}
+		if newId, ok := renames[bump.Id]; ok {
+			violations = append(violations, fmt.Sprintf("'%s' %s (Id changed to '%s')", bump.Id, bump.Kind, newId))
+		} else if bump.To == "" {
+			violations = append(violations, fmt.Sprintf("'%s' %s (removed)", bump.Id, bump.Kind))
 		} else {
-			oldYAML, _ := yaml.Marshal(oldMeta)
-			newYAML, _ := yaml.Marshal(newMeta)
-			if string(oldYAML) != string(newYAML) {
-				report.Updated = append(report.Updated, UpdateEntry{Before: oldMeta, After: newMeta})
+			violations = append(violations, fmt.Sprintf("'%s' %s (%s -> %s)", bump.Id, bump.Kind, bump.From, bump.To))
+		}
+	}
+	return violations
+}
+
+// splitList 将可重复且逗号分隔的参数展开为去除空白后的列表
+func splitList(values []string) []string {
+	var items []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
 			}
 		}
 	}
+	return items
+}
+
+// emptyTypes 返回 expected 中在 entries 里没有任何适配器的类型，用于发现整类适配器消失的情况
+func emptyTypes(entries []catalog.Entry, expected []string) []string {
+	counts := make(map[string]int)
+	for _, entry := range entries {
+		counts[string(entry.Type)]++
+	}
 
-	// 适配器移除检查
-	for id, oldMeta := range oldMap {
-		if _, exists := newMap[id]; !exists {
-			report.Removed = append(report.Removed, oldMeta)
+	var empty []string
+	for _, typ := range expected {
+		if counts[typ] == 0 {
+			empty = append(empty, typ)
 		}
 	}
+	return empty
+}
 
-	return report
+// logSummary 输出报告摘要，所有权变动会被单独列出以免被忽略
+func logSummary(report metadiff.ChangeReport) {
+	log.Printf("Summary: %d added, %d removed (%d expected), %d renamed, %d moved, %d updated, %d version bumps, %d type changes, %d ownership changes",
+		len(report.Added), len(report.Removed), len(report.ExpectedRemovals), len(report.Renamed), len(report.Moved), len(report.Updated), len(report.VersionBumps), len(report.TypeChanges), len(report.OwnershipChanges))
+	for _, change := range report.OwnershipChanges {
+		log.Printf("WARNING: ownership of adapter '%s' changed from %q to %q",
+			change.After.Id, change.Before.Author, change.After.Author)
+	}
+}
+
+// readPriorReport 读取之前写出的 JSON 报告；minimal 报告只含 Id，无法判断变动是否相同，因此不被接受
+func readPriorReport(path string) (metadiff.ChangeReport, error) {
+	var report metadiff.ChangeReport
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return report, fmt.Errorf("could not read report %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return report, fmt.Errorf("could not parse report %s (only JSON reports with standard or full context can be used): %w", path, err)
+	}
+	return report, nil
+}
+
+// readOldMetadata 读取并合并所有旧元数据文件，平铺列表、信封格式与逐个适配器的目录均可
+// 不存在的文件视为空列表；同一 Id 出现在多个文件中时返回错误，因为无法判断应以哪一份作为比较基准。
+// 同一文件中重复的 Id 与 --new 一样由 reportDuplicateIds 处理。只有一个旧文件且为信封格式时才返回其头部
+func readOldMetadata(paths []string, strict bool, diags *diagnostics.Diagnostics) ([]catalog.Entry, *catalog.EnvelopeMeta, error) {
+	merged := []catalog.Entry{}
+	// sources 记录每个 Id 首次出现的文件在 paths 中的下标
+	sources := make(map[string]int)
+	var envelope *catalog.EnvelopeMeta
+
+	for i, path := range paths {
+		metadata, meta, err := readMetadata(path, diags)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				return nil, nil, err
+			}
+			log.Printf("Old metadata file '%s' not found. Treating it as empty.", path)
+			continue
+		}
+		if err := reportDuplicateIds(metadata, path, strict, diags); err != nil {
+			return nil, nil, err
+		}
+
+		for _, entry := range metadata {
+			if prev, exists := sources[entry.Id]; exists && prev != i {
+				return nil, nil, fmt.Errorf("duplicate adapter Id '%s' found in %s and %s", entry.Id, paths[prev], path)
+			}
+			sources[entry.Id] = i
+			merged = append(merged, entry)
+		}
+		if len(paths) == 1 {
+			envelope = meta
+		}
+	}
+
+	if len(merged) == 0 {
+		log.Println("No old metadata found. Assuming all new adapters are 'Added'.")
+	}
+	return merged, envelope, nil
+}
+
+// reportDuplicateIds 报告 path 中重复的适配器 Id：默认为警告，比较时只使用最后一个条目；
+// strict 为 true 时报告为错误并返回 error
+func reportDuplicateIds(entries []catalog.Entry, path string, strict bool, diags *diagnostics.Diagnostics) error {
+	duplicates := metadiff.DuplicateIds(entries)
+	for _, id := range duplicates {
+		if strict {
+			diags.Errorf(id, token.Position{Filename: path}, "duplicate adapter Id '%s' found in %s", id, path)
+			continue
+		}
+		diags.Warnf(id, token.Position{Filename: path}, "duplicate adapter Id '%s' found in %s, only the last entry is compared", id, path)
+	}
+	if strict && len(duplicates) > 0 {
+		return fmt.Errorf("--strict: %d duplicate adapter Ids found in %s", len(duplicates), path)
+	}
+	return nil
 }
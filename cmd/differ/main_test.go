@@ -0,0 +1,163 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+	"github.com/meloshub/meloshub-tools/diagnostics"
+	"github.com/meloshub/meloshub-tools/metadiff"
+)
+
+const (
+	listYAML = `- id: spotify
+  title: Spotify
+  version: 1.0.0
+`
+	envelopeYAML = `meta:
+  generator: metagen 1.2.0
+  count: 2
+adapters:
+  - id: spotify
+    title: Spotify
+    version: 1.1.0
+  - id: deezer
+    title: Deezer
+`
+)
+
+// writeFile 在临时目录中写入元数据文件并返回其路径
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadMetadataShapes(t *testing.T) {
+	entries, meta, err := readMetadata(writeFile(t, "list.yaml", listYAML), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta != nil || len(entries) != 1 || entries[0].Version != "1.0.0" {
+		t.Errorf("list file = %+v with meta %+v, want spotify 1.0.0 and no meta", entries, meta)
+	}
+
+	entries, meta, err = readMetadata(writeFile(t, "envelope.yaml", envelopeYAML), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (catalog.EnvelopeMeta{Generator: "metagen 1.2.0", Count: 2}); meta == nil || *meta != want {
+		t.Errorf("envelope meta = %+v, want %+v", meta, want)
+	}
+	if len(entries) != 2 || entries[0].Id != "spotify" || entries[1].Id != "deezer" {
+		t.Errorf("envelope adapters = %+v, want spotify and deezer", entries)
+	}
+}
+
+func TestCompareMixedShapes(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		envelope []metadiff.FieldChange
+	}{
+		{"list to envelope", listYAML, envelopeYAML, []metadiff.FieldChange{{Field: "format", Before: "list", After: "envelope"}}},
+		{"envelope to list", envelopeYAML, listYAML, []metadiff.FieldChange{{Field: "format", Before: "envelope", After: "list"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := &diagnostics.Diagnostics{}
+			oldList, oldMeta, err := readOldMetadata([]string{writeFile(t, "old.yaml", tt.old)}, false, diags)
+			if err != nil {
+				t.Fatal(err)
+			}
+			newList, newMeta, err := readMetadata(writeFile(t, "new.yaml", tt.new), diags)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := metadiff.CompareEnvelopes(oldMeta, newMeta); !reflect.DeepEqual(got, tt.envelope) {
+				t.Errorf("envelope changes = %+v, want %+v", got, tt.envelope)
+			}
+
+			// 文件形式的变化不影响适配器本身的比较
+			report := metadiff.Compare(oldList, newList, metadiff.Options{})
+			if len(report.Updated) != 1 || report.Updated[0].After.Id != "spotify" {
+				t.Errorf("updated = %+v, want only spotify", report.Updated)
+			}
+			if len(report.Added)+len(report.Removed) != 1 {
+				t.Errorf("added %+v, removed %+v, want deezer on one side only", report.Added, report.Removed)
+			}
+			if n := len(diags.Entries()); n != 0 {
+				t.Errorf("reading reported %d diagnostics, want none", n)
+			}
+		})
+	}
+}
+
+func TestReadOldMetadataMergesShapes(t *testing.T) {
+	list := writeFile(t, "list.yaml", "- id: tidal\n  title: Tidal\n")
+	envelope := writeFile(t, "envelope.yaml", envelopeYAML)
+	entries, meta, err := readOldMetadata([]string{list, envelope}, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, e := range entries {
+		ids = append(ids, e.Id)
+	}
+	if want := []string{"tidal", "spotify", "deezer"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("merged Ids = %v, want %v", ids, want)
+	}
+	// 多个旧文件合并后没有唯一的头部
+	if meta != nil {
+		t.Errorf("merged meta = %+v, want nil", meta)
+	}
+}
+
+// TestReadOldMetadataDuplicates 检查同一旧文件中的重复 Id 与 --new 一样默认只警告、--strict 下报错，
+// 而出现在不同旧文件中的重复 Id 总是报错
+func TestReadOldMetadataDuplicates(t *testing.T) {
+	path := writeFile(t, "old.yaml", "- id: spotify\n  version: 1.0.0\n- id: spotify\n  version: 1.1.0\n")
+
+	diags := &diagnostics.Diagnostics{}
+	entries, _, err := readOldMetadata([]string{path}, false, diags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("entries = %+v, want both spotify entries", entries)
+	}
+	if got := diags.Entries(); len(got) != 1 || got[0].Severity != diagnostics.SeverityWarning || !strings.Contains(got[0].Message, "only the last entry is compared") {
+		t.Errorf("diagnostics = %+v, want one duplicate Id warning", got)
+	}
+
+	diags = &diagnostics.Diagnostics{}
+	if _, _, err := readOldMetadata([]string{path}, true, diags); err == nil || !strings.Contains(err.Error(), "--strict: 1 duplicate adapter Ids found in "+path) {
+		t.Errorf("strict error = %v, want a duplicate Id error", err)
+	}
+	if got := diags.Entries(); len(got) != 1 || got[0].Severity != diagnostics.SeverityError {
+		t.Errorf("strict diagnostics = %+v, want one duplicate Id error", got)
+	}
+
+	other := writeFile(t, "other.yaml", "- id: spotify\n")
+	if _, _, err := readOldMetadata([]string{path, other}, false, nil); err == nil || !strings.Contains(err.Error(), "found in "+path+" and "+other) {
+		t.Errorf("cross-file error = %v, want a duplicate Id error naming both files", err)
+	}
+}
+
+func TestCompareEnvelopeGenerator(t *testing.T) {
+	before := &catalog.EnvelopeMeta{Generator: "metagen 1.1.0", Count: 1}
+	after := &catalog.EnvelopeMeta{Generator: "metagen 1.2.0", Count: 2, Checksum: "sha256:00"}
+	want := []metadiff.FieldChange{{Field: "generator", Before: "metagen 1.1.0", After: "metagen 1.2.0"}}
+	if got := metadiff.CompareEnvelopes(before, after); !reflect.DeepEqual(got, want) {
+		t.Errorf("changes = %+v, want %+v", got, want)
+	}
+	if got := metadiff.CompareEnvelopes(nil, nil); len(got) != 0 {
+		t.Errorf("two lists: changes = %+v, want none", got)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/meloshub/meloshub-tools/metadiff"
+)
+
+// metric 单个 Prometheus gauge
+type metric struct {
+	name  string
+	help  string
+	value int
+}
+
+// writeMetrics 将报告中的计数以 Prometheus 文本格式写为 gauge，路径为 - 时写入标准输出
+// total 为参与比较的新适配器总数，即排除 --exclude 之后的 --new 条目数
+func writeMetrics(path string, report metadiff.ChangeReport, total int) error {
+	metrics := []metric{
+		{"catalog_adapters_added", "Number of adapters added in the new catalog.", len(report.Added)},
+		{"catalog_adapters_removed", "Number of adapters removed from the old catalog.", len(report.Removed)},
+		{"catalog_adapters_updated", "Number of adapters whose metadata changed.", report.UpdatedCount()},
+		{"catalog_adapters_total_new", "Number of adapters in the new catalog.", total},
+	}
+
+	var b strings.Builder
+	for _, m := range metrics {
+		fmt.Fprintf(&b, "# HELP %s %s\n", m.name, m.help)
+		fmt.Fprintf(&b, "# TYPE %s gauge\n", m.name)
+		fmt.Fprintf(&b, "%s %d\n", m.name, m.value)
+	}
+	return writeOutput(path, []byte(b.String()))
+}
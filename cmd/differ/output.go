@@ -0,0 +1,407 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+	"github.com/meloshub/meloshub-tools/metadiff"
+)
+
+// 报告输出格式
+const (
+	formatJSON = "json"
+	formatText = "text"
+	// formatGHComment 适合作为 GitHub PR 评论发布的 Markdown
+	formatGHComment = "gh-comment"
+)
+
+// defaultReportTitle Markdown 报告默认的一级标题
+const defaultReportTitle = "Adapter Catalog Changes"
+
+// renderReport 按指定格式渲染报告，context 只影响 JSON 格式的详细程度，title 只用于 Markdown 格式的一级标题
+// byAuthor 为 true 时新增、移除与更新的适配器按作者分组
+func renderReport(report metadiff.ChangeReport, format, context, title string, byAuthor bool) ([]byte, error) {
+	switch format {
+	case formatJSON:
+		return renderJSON(report, context, byAuthor)
+	case formatText:
+		return []byte(renderText(report, byAuthor)), nil
+	case formatGHComment:
+		return []byte(renderGHComment(report, title, byAuthor)), nil
+	default:
+		return nil, fmt.Errorf("unknown format %q (expected %s, %s or %s)", format, formatJSON, formatText, formatGHComment)
+	}
+}
+
+// renderText 将报告渲染为便于阅读的纯文本，byAuthor 为 true 时新增、移除与更新按作者列出
+func renderText(report metadiff.ChangeReport, byAuthor bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d added, %d removed, %d updated, %d ownership changes",
+		len(report.Added), len(report.Removed), len(report.Updated), len(report.OwnershipChanges))
+	if n := len(report.Renamed); n > 0 {
+		fmt.Fprintf(&b, ", %d renamed", n)
+	}
+	if n := len(report.Moved); n > 0 {
+		fmt.Fprintf(&b, ", %d moved", n)
+	}
+	if n := len(report.VersionBumps); n > 0 {
+		fmt.Fprintf(&b, ", %d version bumps", n)
+	}
+	if n := len(report.TypeChanges); n > 0 {
+		fmt.Fprintf(&b, ", %d type changes", n)
+	}
+	b.WriteString("\n")
+
+	if len(report.Envelope) > 0 {
+		b.WriteString("\nFile header:\n")
+		for _, change := range report.Envelope {
+			fmt.Fprintf(&b, "  %s: %q -> %q\n", change.Field, change.Before, change.After)
+		}
+	}
+
+	writeEntryLines := func(indent string, entries []catalog.Entry) {
+		for _, e := range entries {
+			fmt.Fprintf(&b, "%s%s (%s)\n", indent, e.Title, e.Id)
+		}
+	}
+	writeEntries := func(heading string, entries []catalog.Entry, grouped bool) {
+		if len(entries) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n%s\n", heading)
+		if !grouped {
+			writeEntryLines("  ", entries)
+			return
+		}
+		for _, group := range groupedEntries(entries) {
+			fmt.Fprintf(&b, "  %s:\n", authorHeading(group.Author))
+			writeEntryLines("    ", group.Adapters)
+		}
+	}
+
+	writeEntries("Added:", report.Added, byAuthor)
+	writeEntries("Removed:", report.Removed, byAuthor)
+	writeEntries("Expected removals:", report.ExpectedRemovals, false)
+
+	if len(report.Renamed) > 0 {
+		b.WriteString("\nRenamed:\n")
+		for _, u := range report.Renamed {
+			fmt.Fprintf(&b, "  %s: %s -> %s\n", u.After.Title, u.Before.Id, u.After.Id)
+		}
+	}
+
+	if len(report.Moved) > 0 {
+		b.WriteString("\nMoved:\n")
+		for _, m := range report.Moved {
+			fmt.Fprintf(&b, "  %s: %s -> %s\n", m.Entry.Title, m.From, m.To)
+		}
+	}
+
+	// writeUpdateChanges 逐行列出单个更新中变化的字段、功能开关与本地化标题
+	writeUpdateChanges := func(indent string, u metadiff.UpdateEntry) {
+		for _, change := range metadiff.ChangedFields(u.Before, u.After) {
+			fmt.Fprintf(&b, "%s  %s: %q -> %q\n", indent, change.Field, change.Before, change.After)
+		}
+		if c := u.Capabilities; c != nil {
+			for _, name := range c.Added {
+				fmt.Fprintf(&b, "%s  capability %s: added (%t)\n", indent, name, u.After.Capabilities[name])
+			}
+			for _, name := range c.Removed {
+				fmt.Fprintf(&b, "%s  capability %s: removed\n", indent, name)
+			}
+			for _, name := range c.Flipped {
+				fmt.Fprintf(&b, "%s  capability %s: %t -> %t\n", indent, name, u.Before.Capabilities[name], u.After.Capabilities[name])
+			}
+		}
+		if t := u.Titles; t != nil {
+			for _, locale := range t.Added {
+				fmt.Fprintf(&b, "%s  title[%s]: added %q\n", indent, locale, u.After.Titles[locale])
+			}
+			for _, locale := range t.Removed {
+				fmt.Fprintf(&b, "%s  title[%s]: removed\n", indent, locale)
+			}
+			for _, locale := range t.Changed {
+				fmt.Fprintf(&b, "%s  title[%s]: %q -> %q\n", indent, locale, u.Before.Titles[locale], u.After.Titles[locale])
+			}
+		}
+	}
+	writeUpdateLines := func(indent string, updates []metadiff.UpdateEntry) {
+		for _, u := range updates {
+			fmt.Fprintf(&b, "%s%s (%s)\n", indent, u.After.Title, u.After.Id)
+			writeUpdateChanges(indent, u)
+		}
+	}
+	writeUpdates := func(heading string, updates []metadiff.UpdateEntry, grouped bool) {
+		if len(updates) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n%s\n", heading)
+		if !grouped {
+			writeUpdateLines("  ", updates)
+			return
+		}
+		for _, group := range groupedUpdates(updates) {
+			fmt.Fprintf(&b, "  %s:\n", authorHeading(group.Author))
+			writeUpdateLines("    ", group.Adapters)
+		}
+	}
+
+	// 归属变化列在一般的更新之前，避免评审时被忽略
+	if len(report.OwnershipChanges) > 0 {
+		b.WriteString("\nOwnership changes:\n")
+		// 按优先级只归入这里的更新不会在其它分组中列出，需要在此列出其余的变动
+		ownershipOnly := make(map[string]bool)
+		for _, u := range report.OwnershipOnly() {
+			ownershipOnly[u.After.Id] = true
+		}
+		for _, u := range report.OwnershipChanges {
+			fmt.Fprintf(&b, "  %s (%s): %q -> %q\n", u.After.Title, u.After.Id, u.Before.Author, u.After.Author)
+			if ownershipOnly[u.After.Id] {
+				writeUpdateChanges("  ", u)
+			}
+		}
+	}
+
+	writeUpdates("Updated:", report.Updated, byAuthor)
+	writeUpdates("Type changes:", report.TypeChanges, false)
+
+	if len(report.VersionBumps) > 0 {
+		b.WriteString("\nVersion bumps:\n")
+		for _, bump := range report.VersionBumps {
+			fmt.Fprintf(&b, "  %s: %s -> %s (%s)\n", bump.Id, bump.From, bump.To, bump.Kind)
+			// 按优先级归入的更新可能还改变了其它字段
+			if bump.Update != nil {
+				writeUpdateChanges("  ", *bump.Update)
+			}
+		}
+	}
+
+	writeEntries("Unchanged:", report.Unchanged, false)
+	return b.String()
+}
+
+// renderGHComment 将报告渲染为 GitHub PR 评论：以 title 为一级标题，一行摘要，每个分组折叠在 <details> 中并以表格列出
+// title 中的换行会合并为空格，为空时不输出标题；byAuthor 为 true 时新增、移除与更新的表格按作者拆分，每位作者一个小标题
+func renderGHComment(report metadiff.ChangeReport, title string, byAuthor bool) string {
+	var b strings.Builder
+	if title = strings.Join(strings.Fields(title), " "); title != "" {
+		fmt.Fprintf(&b, "# %s\n\n", title)
+	}
+	if len(report.Envelope)+len(report.Added)+len(report.Removed)+len(report.ExpectedRemovals)+len(report.Renamed)+len(report.Moved)+report.UpdatedCount() == 0 {
+		b.WriteString("**Adapter catalog:** no changes.\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "**Adapter catalog:** ➕ %d added, ➖ %d removed, ✏️ %d updated",
+		len(report.Added), len(report.Removed), len(report.Updated))
+	if n := len(report.Renamed); n > 0 {
+		fmt.Fprintf(&b, ", 🔀 %d renamed", n)
+	}
+	if n := len(report.Moved); n > 0 {
+		fmt.Fprintf(&b, ", 🔀 %d moved", n)
+	}
+	if n := len(report.VersionBumps); n > 0 {
+		fmt.Fprintf(&b, ", %d version bumps", n)
+	}
+	if n := len(report.TypeChanges); n > 0 {
+		fmt.Fprintf(&b, ", %d type changes", n)
+	}
+	if n := len(report.OwnershipChanges); n > 0 {
+		fmt.Fprintf(&b, ", ⚠️ %d ownership changes", n)
+	}
+	b.WriteString("\n")
+	for _, change := range report.Envelope {
+		fmt.Fprintf(&b, "\nFile header `%s`: %s → %s\n", change.Field, escapeCell(change.Before), escapeCell(change.After))
+	}
+
+	section := func(summary string, count int, header string, rows []string) {
+		if count == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n<details>\n<summary>%s (%d)</summary>\n\n", summary, count)
+		b.WriteString(header)
+		for _, row := range rows {
+			b.WriteString(row)
+		}
+		b.WriteString("\n</details>\n")
+	}
+
+	// groupedSection 与 section 相同，但每位作者的行单独成表，以作者作为小标题
+	groupedSection := func(summary string, count int, header string, authors []string, rows [][]string) {
+		if count == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n<details>\n<summary>%s (%d)</summary>\n", summary, count)
+		for i, author := range authors {
+			fmt.Fprintf(&b, "\n#### %s\n\n", escapeCell(authorHeading(author)))
+			b.WriteString(header)
+			for _, row := range rows[i] {
+				b.WriteString(row)
+			}
+		}
+		b.WriteString("\n</details>\n")
+	}
+
+	entryHeader := "| | Id | Title | Version | Author |\n|---|---|---|---|---|\n"
+	entryRows := func(emoji string, entries []catalog.Entry) []string {
+		var rows []string
+		for _, e := range entries {
+			rows = append(rows, fmt.Sprintf("| %s | `%s` | %s | %s | %s |\n",
+				emoji, escapeCell(e.Id), escapeCell(e.Title), escapeCell(e.Version), escapeCell(e.Author)))
+		}
+		return rows
+	}
+	entrySection := func(summary, emoji string, entries []catalog.Entry) {
+		if !byAuthor {
+			section(summary, len(entries), entryHeader, entryRows(emoji, entries))
+			return
+		}
+		var authors []string
+		var rows [][]string
+		for _, group := range groupedEntries(entries) {
+			authors = append(authors, group.Author)
+			rows = append(rows, entryRows(emoji, group.Adapters))
+		}
+		groupedSection(summary, len(entries), entryHeader, authors, rows)
+	}
+	entrySection("Added", "➕", report.Added)
+	entrySection("Removed", "➖", report.Removed)
+	section("Expected removals", len(report.ExpectedRemovals), entryHeader, entryRows("➖", report.ExpectedRemovals))
+
+	var renamedRows []string
+	for _, u := range report.Renamed {
+		renamedRows = append(renamedRows, fmt.Sprintf("| 🔀 | `%s` | `%s` | %s |\n",
+			escapeCell(u.Before.Id), escapeCell(u.After.Id), escapeCell(u.After.Title)))
+	}
+	section("Renamed", len(report.Renamed), "| | Old Id | New Id | Title |\n|---|---|---|---|\n", renamedRows)
+
+	var movedRows []string
+	for _, m := range report.Moved {
+		movedRows = append(movedRows, fmt.Sprintf("| 🔀 | `%s` | `%s` | %s |\n",
+			escapeCell(m.From), escapeCell(m.To), escapeCell(m.Entry.Title)))
+	}
+	section("Moved", len(report.Moved), "| | Old Id | New Id | Title |\n|---|---|---|---|\n", movedRows)
+
+	// 归属变化列在一般的更新之前，避免评审时被忽略
+	var ownershipRows []string
+	for _, u := range report.OwnershipChanges {
+		ownershipRows = append(ownershipRows, fmt.Sprintf("| ⚠️ | `%s` | %s | %s | %s |\n",
+			escapeCell(u.After.Id), escapeCell(u.Before.Author), escapeCell(u.After.Author),
+			escapeCell(strings.Join(minimizeUpdate(u).Fields, ", "))))
+	}
+	section("Ownership changes", len(report.OwnershipChanges), "| | Id | Before | After | Changed fields |\n|---|---|---|---|---|\n", ownershipRows)
+
+	updateRows := func(updates []metadiff.UpdateEntry) []string {
+		var rows []string
+		for _, u := range updates {
+			var fields []string
+			for _, change := range metadiff.ChangedFields(u.Before, u.After) {
+				fields = append(fields, change.Field)
+			}
+			if u.Capabilities != nil {
+				fields = append(fields, "capabilities")
+			}
+			if u.Titles != nil {
+				fields = append(fields, "titles")
+			}
+			rows = append(rows, fmt.Sprintf("| ✏️ | `%s` | %s | %s → %s | %s |\n",
+				escapeCell(u.After.Id), escapeCell(u.After.Title), escapeCell(u.Before.Version), escapeCell(u.After.Version),
+				escapeCell(strings.Join(fields, ", "))))
+		}
+		return rows
+	}
+	updateHeader := "| | Id | Title | Version | Changed fields |\n|---|---|---|---|---|\n"
+	if byAuthor {
+		var authors []string
+		var rows [][]string
+		for _, group := range groupedUpdates(report.Updated) {
+			authors = append(authors, group.Author)
+			rows = append(rows, updateRows(group.Adapters))
+		}
+		groupedSection("Updated", len(report.Updated), updateHeader, authors, rows)
+	} else {
+		section("Updated", len(report.Updated), updateHeader, updateRows(report.Updated))
+	}
+	section("Type changes", len(report.TypeChanges), updateHeader, updateRows(report.TypeChanges))
+
+	var bumpRows []string
+	for _, bump := range report.VersionBumps {
+		fields := "version"
+		if bump.Update != nil {
+			fields = strings.Join(minimizeUpdate(*bump.Update).Fields, ", ")
+		}
+		bumpRows = append(bumpRows, fmt.Sprintf("| ✏️ | `%s` | %s → %s | %s | %s |\n",
+			escapeCell(bump.Id), escapeCell(bump.From), escapeCell(bump.To), bump.Kind, escapeCell(fields)))
+	}
+	section("Version bumps", len(report.VersionBumps), "| | Id | Version | Kind | Changed fields |\n|---|---|---|---|---|\n", bumpRows)
+
+	return b.String()
+}
+
+// cellReplacer 转义会破坏 Markdown 表格或被当作 HTML 的字符，并将换行合并为空格
+var cellReplacer = strings.NewReplacer(
+	"|", "\\|",
+	"`", "\\`",
+	"<", "&lt;",
+	">", "&gt;",
+	"\r\n", " ",
+	"\n", " ",
+	"\r", " ",
+)
+
+// escapeCell 转义表格单元格的内容
+func escapeCell(s string) string {
+	return cellReplacer.Replace(s)
+}
+
+// archiveTimeLayout 归档文件名中的 UTC 时间格式：不含冒号以兼容 Windows 文件名，精确到纳秒以免同一秒内的运行互相覆盖，
+// 且按字典序排列即为时间顺序
+const archiveTimeLayout = "20060102T150405.000000000Z"
+
+// archiveReport 将 JSON 格式的报告写入归档目录，文件名中带有运行时间，目录不存在时会被创建
+// 无论 --format 为何，归档副本始终为 JSON
+func archiveReport(dir string, report metadiff.ChangeReport, now time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("could not create archive directory %s: %w", dir, err)
+	}
+	data, err := renderReport(report, formatJSON, contextStandard, "", false)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "changes-"+now.UTC().Format(archiveTimeLayout)+".json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("could not write archive file: %w", err)
+	}
+	return path, nil
+}
+
+// writeOutput 将内容写入文件，路径为 - 时写入标准输出
+func writeOutput(path string, data []byte) error {
+	if path == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// writeRenameMap 将重命名与移动前后 Id 的对应关系写为 JSON 对象，没有 Id 变化时写入 {}
+func writeRenameMap(path string, report metadiff.ChangeReport) error {
+	data, err := json.MarshalIndent(report.RenameMap(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeOutput(path, append(data, '\n'))
+}
+
+// writeAuthorStats 将按作者统计的变动数量写为 JSON 数组，路径为 - 时写入标准输出
+func writeAuthorStats(path string, report metadiff.ChangeReport, newList []catalog.Entry) error {
+	data, err := json.MarshalIndent(report.AuthorStats(newList), "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeOutput(path, append(data, '\n'))
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+	"github.com/meloshub/meloshub-tools/metadiff"
+	"github.com/meloshub/meloshub/adapter"
+)
+
+// ownershipReport 返回同时包含归属变化与一般更新的报告
+func ownershipReport() metadiff.ChangeReport {
+	oldList := []catalog.Entry{
+		{Metadata: adapter.Metadata{Id: "spotify", Title: "Spotify", Version: "1.0.0", Author: "alice"}},
+		{Metadata: adapter.Metadata{Id: "deezer", Title: "Deezer", Version: "1.0.0", Author: "bob", Description: "Old."}},
+	}
+	newList := []catalog.Entry{
+		{Metadata: adapter.Metadata{Id: "spotify", Title: "Spotify", Version: "1.0.0", Author: "mallory"}},
+		{Metadata: adapter.Metadata{Id: "deezer", Title: "Deezer", Version: "1.0.0", Author: "bob", Description: "New."}},
+	}
+	return metadiff.Compare(oldList, newList, metadiff.Options{})
+}
+
+func TestRenderOwnershipBeforeUpdates(t *testing.T) {
+	report := ownershipReport()
+	if len(report.OwnershipChanges) != 1 || len(report.Updated) == 0 {
+		t.Fatalf("report has %d ownership changes and %d updates, want both", len(report.OwnershipChanges), len(report.Updated))
+	}
+	tests := []struct {
+		format             string
+		ownership, updated string
+	}{
+		{formatText, "\nOwnership changes:\n", "\nUpdated:\n"},
+		{formatGHComment, "<summary>Ownership changes (1)</summary>", "<summary>Updated ("},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			out, err := renderReport(report, tt.format, "", defaultReportTitle, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ownership, updated := strings.Index(string(out), tt.ownership), strings.Index(string(out), tt.updated)
+			if ownership < 0 || updated < 0 {
+				t.Fatalf("output lacks the ownership or updated section:\n%s", out)
+			}
+			if ownership > updated {
+				t.Errorf("ownership changes listed after updates:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestArchiveReport(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "archive")
+	report := ownershipReport()
+	// 同一秒内的两次运行写入不同的文件
+	first := time.Date(2024, 1, 2, 15, 4, 5, 123456789, time.FixedZone("CET", 3600))
+	second := first.Add(time.Millisecond)
+
+	var paths []string
+	for _, now := range []time.Time{first, second} {
+		path, err := archiveReport(dir, report, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	if want := filepath.Join(dir, "changes-20240102T140405.123456789Z.json"); paths[0] != want {
+		t.Errorf("archive path = %s, want %s", paths[0], want)
+	}
+	if paths[0] == paths[1] || paths[0] > paths[1] {
+		t.Errorf("archive paths %v are not distinct and in time order", paths)
+	}
+	for _, path := range paths {
+		if strings.Contains(filepath.Base(path), ":") {
+			t.Errorf("archive name %s contains a colon", filepath.Base(path))
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !json.Valid(data) {
+			t.Errorf("%s is not valid JSON", path)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/meloshub/meloshub-tools/metadiff"
+)
+
+// pointerChange --json-pointer 输出中单个值的变动，值不存在的一侧（如新增的功能开关）为 null
+type pointerChange struct {
+	Path   string `json:"path"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+// pointerEscaper 按 RFC 6901 转义 JSON Pointer 中的单个路径段
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// adapterPointer 返回适配器中某个值的 JSON Pointer，形如 /adapters/spotify/version；
+// 适配器以旧 Id 为键，map 字段的键作为额外的路径段，如 /adapters/spotify/capabilities/lyrics
+func adapterPointer(id string, segments ...string) string {
+	var b strings.Builder
+	b.WriteString("/adapters/")
+	b.WriteString(pointerEscaper.Replace(id))
+	for _, segment := range segments {
+		b.WriteByte('/')
+		b.WriteString(pointerEscaper.Replace(segment))
+	}
+	return b.String()
+}
+
+// pointerChanges 列出报告中每个更新（包括重命名、版本变动、Type 变动与所有权变动）中变化的值，
+// 顺序与报告一致；同时出现在多个分组中的更新只列出一次
+func pointerChanges(report metadiff.ChangeReport) []pointerChange {
+	changes := []pointerChange{}
+	seen := make(map[string]bool)
+	addUpdate := func(u metadiff.UpdateEntry) {
+		id := u.Before.Id
+		if seen[id] {
+			return
+		}
+		seen[id] = true
+		for _, f := range metadiff.ChangedFields(u.Before, u.After) {
+			changes = append(changes, pointerChange{Path: adapterPointer(id, f.Field), Before: f.Before, After: f.After})
+		}
+		if c := u.Capabilities; c != nil {
+			for _, name := range c.Added {
+				changes = append(changes, pointerChange{Path: adapterPointer(id, "capabilities", name), After: u.After.Capabilities[name]})
+			}
+			for _, name := range c.Removed {
+				changes = append(changes, pointerChange{Path: adapterPointer(id, "capabilities", name), Before: u.Before.Capabilities[name]})
+			}
+			for _, name := range c.Flipped {
+				changes = append(changes, pointerChange{Path: adapterPointer(id, "capabilities", name), Before: u.Before.Capabilities[name], After: u.After.Capabilities[name]})
+			}
+		}
+		if t := u.Titles; t != nil {
+			for _, locale := range t.Added {
+				changes = append(changes, pointerChange{Path: adapterPointer(id, "titles", locale), After: u.After.Titles[locale]})
+			}
+			for _, locale := range t.Removed {
+				changes = append(changes, pointerChange{Path: adapterPointer(id, "titles", locale), Before: u.Before.Titles[locale]})
+			}
+			for _, locale := range t.Changed {
+				changes = append(changes, pointerChange{Path: adapterPointer(id, "titles", locale), Before: u.Before.Titles[locale], After: u.After.Titles[locale]})
+			}
+		}
+	}
+
+	for _, u := range report.Updated {
+		addUpdate(u)
+	}
+	for _, u := range report.Renamed {
+		addUpdate(u)
+	}
+	for _, u := range report.TypeChanges {
+		addUpdate(u)
+	}
+	// 按优先级只归入 OwnershipChanges 的更新不在 Updated 中
+	for _, u := range report.OwnershipChanges {
+		addUpdate(u)
+	}
+	for _, bump := range report.VersionBumps {
+		// 按优先级归入的更新保留了完整的变动
+		if bump.Update != nil {
+			addUpdate(*bump.Update)
+			continue
+		}
+		if seen[bump.Id] {
+			continue
+		}
+		seen[bump.Id] = true
+		changes = append(changes, pointerChange{Path: adapterPointer(bump.Id, "version"), Before: bump.From, After: bump.To})
+	}
+	return changes
+}
+
+// renderPointers 将 pointerChanges 的结果渲染为 JSON 数组
+func renderPointers(report metadiff.ChangeReport) ([]byte, error) {
+	return json.MarshalIndent(pointerChanges(report), "", "  ")
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/meloshub/meloshub-tools/metadiff"
+)
+
+// reportSummary --summary-only 输出的计数，updated 包括版本变动、Type 变动与所有权变动
+type reportSummary struct {
+	Added            int               `json:"added"`
+	Removed          int               `json:"removed"`
+	Updated          int               `json:"updated"`
+	Renamed          int               `json:"renamed,omitempty"`
+	Moved            int               `json:"moved,omitempty"`
+	OwnershipChanges int               `json:"ownership_changes,omitempty"`
+	Severity         metadiff.BumpKind `json:"severity"`
+}
+
+// summarize 统计报告中各类变动的数量与整体变动级别
+func summarize(report metadiff.ChangeReport) reportSummary {
+	return reportSummary{
+		Added:            len(report.Added),
+		Removed:          len(report.Removed),
+		Updated:          report.UpdatedCount(),
+		Renamed:          len(report.Renamed),
+		Moved:            len(report.Moved),
+		OwnershipChanges: len(report.OwnershipChanges),
+		Severity:         report.Severity(),
+	}
+}
+
+// renderSummary 按指定格式只渲染报告的计数：json 为单个对象，text 为一行文字，gh-comment 在标题下输出同样的一行
+func renderSummary(report metadiff.ChangeReport, format, title string) ([]byte, error) {
+	summary := summarize(report)
+	switch format {
+	case formatJSON:
+		data, err := json.Marshal(summary)
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case formatText:
+		return []byte(summaryLine(summary) + "\n"), nil
+	case formatGHComment:
+		var b strings.Builder
+		if title = strings.Join(strings.Fields(title), " "); title != "" {
+			fmt.Fprintf(&b, "# %s\n\n", title)
+		}
+		b.WriteString(summaryLine(summary) + "\n")
+		return []byte(b.String()), nil
+	default:
+		return nil, fmt.Errorf("unknown format %q (expected %s, %s or %s)", format, formatJSON, formatText, formatGHComment)
+	}
+}
+
+// summaryLine 将计数写成形如 "+5 -1 ~12 (minor)" 的一行，重命名、移动与所有权变动只在存在时列出
+func summaryLine(s reportSummary) string {
+	line := fmt.Sprintf("+%d -%d ~%d", s.Added, s.Removed, s.Updated)
+	if s.Renamed > 0 {
+		line += fmt.Sprintf(", %d renamed", s.Renamed)
+	}
+	if s.Moved > 0 {
+		line += fmt.Sprintf(", %d moved", s.Moved)
+	}
+	if s.OwnershipChanges > 0 {
+		line += fmt.Sprintf(", %d ownership changes", s.OwnershipChanges)
+	}
+	return fmt.Sprintf("%s (%s)", line, s.Severity)
+}
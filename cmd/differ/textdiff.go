@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/meloshub/meloshub-tools/internal/textdiff"
+	"gopkg.in/yaml.v3"
+)
+
+// textDiffContext 统一格式差异中每个变动块前后保留的上下文行数
+const textDiffContext = 3
+
+// readRawInput 读取元数据文件的原始内容，文件不存在时视为空文件；逐个适配器的目录没有单一的原始内容，不被接受
+func readRawInput(path string) ([]byte, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return nil, fmt.Errorf("--text-diff compares files, but %s is a directory", path)
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read metadata file %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// normalizeYAML 重新序列化 YAML 文档，使映射的键按字母顺序排列
+// 解析为通用值而不是 catalog.Entry，因此工具不认识的字段也会保留在结果中
+func normalizeYAML(data []byte) ([]byte, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(doc)
+}
+
+// runTextDiff 读取新旧两份原始文件，将二者的文本差异写入 output
+// 旧文件来自唯一的 --old，或 --baseline-ref 指向的 git 对象
+func runTextDiff(oldFiles []string, baselineRef, baselinePath, newFile, output string) error {
+	var oldName string
+	var oldData []byte
+	var err error
+	switch {
+	case len(oldFiles) > 1:
+		return fmt.Errorf("--text-diff compares exactly one --old file, got %d", len(oldFiles))
+	case len(oldFiles) == 1:
+		oldName = oldFiles[0]
+		oldData, err = readRawInput(oldName)
+	default:
+		oldName = baselineRef + ":" + baselinePath
+		oldData, err = readBaselineBytes(baselineRef, baselinePath)
+	}
+	if err != nil {
+		return err
+	}
+
+	newData, err := readRawInput(newFile)
+	if err != nil {
+		return err
+	}
+
+	diff, err := textDiff(oldName, oldData, newFile, newData)
+	if err != nil {
+		return err
+	}
+	return writeOutput(output, []byte(diff))
+}
+
+// textDiff 生成两份规范化后的 YAML 之间的统一格式差异，内容相同时返回空字符串
+func textDiff(oldName string, oldData []byte, newName string, newData []byte) (string, error) {
+	oldNorm, err := normalizeYAML(oldData)
+	if err != nil {
+		return "", fmt.Errorf("could not parse %s: %w", oldName, err)
+	}
+	newNorm, err := normalizeYAML(newData)
+	if err != nil {
+		return "", fmt.Errorf("could not parse %s: %w", newName, err)
+	}
+	return textdiff.Unified(oldName, oldNorm, newName, newNorm, textDiffContext), nil
+}
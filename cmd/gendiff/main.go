@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+	"github.com/meloshub/meloshub-tools/internal/atomicfile"
+	"github.com/meloshub/meloshub-tools/metadiff"
+	"github.com/meloshub/meloshub-tools/metascan"
+)
+
+// stringList 可重复指定的字符串参数
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// compareOptions 生成变动报告的选项，与 differ 的同名参数含义相同
+type compareOptions struct {
+	// excludes 不参与比较的 Id 或通配符，已由 metadiff.ParsePatterns 解析
+	excludes []string
+	// key 匹配新旧条目的字段，为 nil 时按 Id 匹配
+	key metadiff.Key
+	// sortBy 报告中条目的排列顺序
+	sortBy  string
+	options metadiff.Options
+}
+
+// gendiff 在一次调用中依次完成 metagen 与 differ 的工作：
+// 扫描源码生成新的元数据文件，并与原有文件比较生成变动报告
+func main() {
+	outputFile := flag.String("output", "adapters.yaml", "Path to the metadata file, read as the baseline and then regenerated")
+	format := flag.String("format", catalog.FormatYAML, "Format of --output: yaml, envelope, ndjson or pb; csv is not supported because it cannot hold capabilities and localized titles")
+	reportFile := flag.String("report", "changes.json", "Path to the output JSON report file, or - for stdout")
+	requireAuthorEmail := flag.Bool("require-author-email", false, "Fail when an adapter's Author has no parseable email")
+	var excludes stringList
+	flag.Var(&excludes, "exclude", "Comma-separated adapter Ids or globs to leave out of the report (they are still written to --output); may be repeated")
+	keySpec := flag.String("key", "id", "Comma-separated fields that identify the same adapter in both files, e.g. type,title; must be unique within each input")
+	includeUnchanged := flag.Bool("include-unchanged", false, "Also list adapters present in both files without changes, for a full-state snapshot")
+	groupVersionBumps := flag.Bool("group-version-bumps", false, "Report updates that only change Version in a separate version_bumps section instead of under updated")
+	compareStrategy := flag.String("compare-strategy", "", "Comma-separated field precedence, e.g. version,type,author: each update is reported only in the section of its highest-precedence changed field")
+	detectRenames := flag.Bool("detect-renames", false, "Report a removed and an added adapter with the same Title, Type and Author as a rename instead")
+	detectMoves := flag.Bool("detect-moves", false, "Report a removed and an added adapter whose content is identical apart from the Id as a move; checked before --detect-renames")
+	sortBy := flag.String("sort", metadiff.SortById, "Order of report entries: id, title or severity (updated entries ordered major→minor→patch)")
+	flag.Parse()
+
+	if err := checkFormat(*format); err != nil {
+		log.Fatalf("Invalid --format: %v", err)
+	}
+	opts := compareOptions{sortBy: *sortBy, options: metadiff.Options{
+		IncludeUnchanged:  *includeUnchanged,
+		GroupVersionBumps: *groupVersionBumps,
+		DetectRenames:     *detectRenames,
+		DetectMoves:       *detectMoves,
+	}}
+	var err error
+	if opts.excludes, err = metadiff.ParsePatterns(excludes); err != nil {
+		log.Fatalf("Invalid --exclude: %v", err)
+	}
+	if opts.options.Precedence, err = metadiff.ParsePrecedence(*compareStrategy); err != nil {
+		log.Fatalf("Invalid --compare-strategy: %v", err)
+	}
+	if *keySpec != "id" {
+		if opts.key, err = metadiff.ParseKey(*keySpec); err != nil {
+			log.Fatalf("Invalid --key: %v", err)
+		}
+	}
+
+	rootDir, err := os.Getwd()
+	if err != nil {
+		log.Fatalf("Error getting working directory: %v", err)
+	}
+
+	// 扫描源码
+	log.Println("Starting metadata scan in:", rootDir)
+	scanned, err := metascan.Scan(rootDir, metascan.Options{})
+	if err != nil {
+		log.Fatalf("Scan stage failed: %v", err)
+	}
+
+	newMetadata, err := catalog.NewEntries(metascan.Entries(scanned), catalog.Options{RequireAuthorEmail: *requireAuthorEmail})
+	if err != nil {
+		log.Fatalf("Validation stage failed: %v", err)
+	}
+	if err := catalog.CheckIdConflicts(newMetadata, false); err != nil {
+		log.Fatalf("Validation stage failed: %v", err)
+	}
+	catalog.SortById(newMetadata)
+
+	// 读取基线，必须在覆写输出文件之前完成
+	oldMetadata, err := readBaseline(*outputFile, *format)
+	if err != nil {
+		log.Fatalf("Baseline stage failed: %v", err)
+	}
+
+	report, err := compareEntries(oldMetadata, newMetadata, opts)
+	if err != nil {
+		log.Fatalf("Compare stage failed: %v", err)
+	}
+
+	// 写入新的元数据文件，没有适配器时与 metagen 一样删除该文件
+	if len(newMetadata) == 0 {
+		log.Printf("No metadata found. Ensuring %s does not exist.", *outputFile)
+		if err := os.Remove(*outputFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Fatalf("Generate stage failed: could not remove %s: %v", *outputFile, err)
+		}
+	} else {
+		data, err := catalog.Marshal(newMetadata, *format)
+		if err != nil {
+			log.Fatalf("Generate stage failed: could not marshal %s: %v", *format, err)
+		}
+		if err := atomicfile.WriteFile(*outputFile, data, 0644); err != nil {
+			log.Fatalf("Generate stage failed: %v", err)
+		}
+		log.Printf("Successfully generated metadata for %d adapters into %s", len(newMetadata), *outputFile)
+	}
+
+	// 写入变动报告，与 differ 默认的 JSON 报告相同
+	reportJSON, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		log.Fatalf("Report stage failed: could not marshal report: %v", err)
+	}
+	if err := writeReport(*reportFile, reportJSON); err != nil {
+		log.Fatalf("Report stage failed: %v", err)
+	}
+	log.Printf("Successfully generated change report to %s", *reportFile)
+	log.Printf("Summary: %d added, %d removed, %d renamed, %d moved, %d updated, %d version bumps, %d type changes, %d ownership changes",
+		len(report.Added), len(report.Removed), len(report.Renamed), len(report.Moved), len(report.Updated), len(report.VersionBumps), len(report.TypeChanges), len(report.OwnershipChanges))
+}
+
+// checkFormat 检查 --output 的格式：CSV 无法保存功能开关与本地化标题，下次读取基线时会把它们报告为变动
+func checkFormat(format string) error {
+	switch format {
+	case catalog.FormatYAML, catalog.FormatEnvelope, catalog.FormatNDJSON, catalog.FormatProtobuf:
+		return nil
+	case catalog.FormatCSV:
+		return errors.New("csv cannot hold capabilities and localized titles, so the baseline read back from it would not match the scan; use yaml, envelope, ndjson or pb")
+	default:
+		return fmt.Errorf("unknown format %q (expected %s, %s, %s or %s)", format, catalog.FormatYAML, catalog.FormatEnvelope, catalog.FormatNDJSON, catalog.FormatProtobuf)
+	}
+}
+
+// readBaseline 按 format 读取现有的元数据文件，文件不存在时视为空的基线
+func readBaseline(path, format string) ([]catalog.Entry, error) {
+	entries, err := catalog.ReadFileFormat(path, format)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		log.Printf("Baseline file '%s' not found. Assuming all adapters are 'Added'.", path)
+		return []catalog.Entry{}, nil
+	}
+	return entries, nil
+}
+
+// compareEntries 与 differ 相同：先从两侧移除被排除的适配器，检查匹配键的唯一性，再比较并排序
+func compareEntries(oldList, newList []catalog.Entry, opts compareOptions) (metadiff.ChangeReport, error) {
+	oldList = metadiff.Exclude(oldList, opts.excludes)
+	newList = metadiff.Exclude(newList, opts.excludes)
+	if opts.key != nil {
+		if err := metadiff.CheckUniqueKeys(opts.key, oldList, newList); err != nil {
+			return metadiff.ChangeReport{}, err
+		}
+	}
+	options := opts.options
+	options.Key = opts.key
+	report := metadiff.Compare(oldList, newList, options)
+	if err := report.Sort(opts.sortBy); err != nil {
+		return metadiff.ChangeReport{}, err
+	}
+	return report, nil
+}
+
+// writeReport 将报告写入文件，路径为 - 时写入标准输出
+func writeReport(path string, data []byte) error {
+	if path == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	return atomicfile.WriteFile(path, data, 0644)
+}
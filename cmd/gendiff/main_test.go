@@ -0,0 +1,122 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+	"github.com/meloshub/meloshub-tools/metadiff"
+	"github.com/meloshub/meloshub/adapter"
+)
+
+// baselineEntries 带有功能开关与本地化标题的条目，只有 CSV 无法完整保存
+func baselineEntries() []catalog.Entry {
+	return []catalog.Entry{
+		{
+			Metadata:     adapter.Metadata{Id: "deezer", Title: "Deezer", Type: adapter.TypeOfficial, Version: "1.0.0", Author: "bob"},
+			Capabilities: map[string]bool{"lyrics": true},
+			Titles:       map[string]string{"zh": "迪泽"},
+		},
+		{Metadata: adapter.Metadata{Id: "spotify", Title: "Spotify", Type: adapter.TypeCommunity, Version: "2.0.0", Author: "alice"}},
+	}
+}
+
+func TestCheckFormat(t *testing.T) {
+	for _, format := range []string{catalog.FormatYAML, catalog.FormatEnvelope, catalog.FormatNDJSON, catalog.FormatProtobuf} {
+		if err := checkFormat(format); err != nil {
+			t.Errorf("checkFormat(%s) = %v, want nil", format, err)
+		}
+	}
+	if err := checkFormat(catalog.FormatCSV); err == nil || !strings.Contains(err.Error(), "cannot hold capabilities") {
+		t.Errorf("checkFormat(csv) = %v, want it rejected", err)
+	}
+	if err := checkFormat("xml"); err == nil {
+		t.Error("checkFormat(xml) accepted an unknown format")
+	}
+}
+
+// TestReadBaselineRoundTrip 检查每种支持的格式写出后都能原样读回作为基线，不会产生虚假的变动
+func TestReadBaselineRoundTrip(t *testing.T) {
+	want := baselineEntries()
+	for _, format := range []string{catalog.FormatYAML, catalog.FormatEnvelope, catalog.FormatNDJSON, catalog.FormatProtobuf} {
+		t.Run(format, func(t *testing.T) {
+			data, err := catalog.Marshal(want, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(t.TempDir(), "adapters."+format)
+			if err := os.WriteFile(path, data, 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := readBaseline(path, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("baseline = %+v, want %+v", got, want)
+			}
+			report, err := compareEntries(got, want, compareOptions{sortBy: metadiff.SortById})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := len(report.Added) + len(report.Removed) + len(report.Updated); n != 0 {
+				t.Errorf("comparing the baseline with itself reported %d changes", n)
+			}
+		})
+	}
+}
+
+func TestReadBaselineMissing(t *testing.T) {
+	got, err := readBaseline(filepath.Join(t.TempDir(), "adapters.yaml"), catalog.FormatYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || len(got) != 0 {
+		t.Errorf("baseline = %#v, want an empty list", got)
+	}
+}
+
+func TestCompareEntries(t *testing.T) {
+	oldList := baselineEntries()
+	newList := baselineEntries()
+	newList[0].Version = "1.1.0"
+	newList[1].Id = "spotify-v2"
+	newList = append(newList, catalog.Entry{Metadata: adapter.Metadata{Id: "legacy-tidal", Title: "Tidal"}})
+
+	excludes, err := metadiff.ParsePatterns([]string{"legacy-*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := compareEntries(oldList, newList, compareOptions{
+		excludes: excludes,
+		sortBy:   metadiff.SortById,
+		options:  metadiff.Options{DetectRenames: true, GroupVersionBumps: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 被排除的适配器不出现在报告中，Id 变化报告为重命名，只改变版本的更新归入 version_bumps
+	if len(report.Added) != 0 || len(report.Removed) != 0 {
+		t.Errorf("added %+v, removed %+v, want none", report.Added, report.Removed)
+	}
+	if len(report.Renamed) != 1 || report.Renamed[0].After.Id != "spotify-v2" {
+		t.Errorf("renamed = %+v, want spotify -> spotify-v2", report.Renamed)
+	}
+	if len(report.VersionBumps) != 1 || report.VersionBumps[0].Id != "deezer" {
+		t.Errorf("version bumps = %+v, want deezer", report.VersionBumps)
+	}
+}
+
+func TestCompareEntriesDuplicateKey(t *testing.T) {
+	key, err := metadiff.ParseKey("type")
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := append(baselineEntries(), catalog.Entry{Metadata: adapter.Metadata{Id: "tidal", Type: adapter.TypeOfficial}})
+	if _, err := compareEntries(list, baselineEntries(), compareOptions{key: key, sortBy: metadiff.SortById}); err == nil || !strings.Contains(err.Error(), "key is not unique in the old metadata") {
+		t.Errorf("compareEntries = %v, want a duplicate key error", err)
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+	"github.com/meloshub/meloshub-tools/internal/atomicfile"
+	"github.com/meloshub/meloshub-tools/metadiff"
+)
+
+// newIds 返回 after 中相对 before 新增的适配器 Id，按 after 中的顺序排列
+// 与 differ 一样按 Id 匹配，改名后的适配器同样视为新增
+func newIds(before, after []catalog.Entry) []string {
+	added := make(map[string]bool)
+	for _, e := range metadiff.Compare(before, after, metadiff.Options{}).Added {
+		added[e.Id] = true
+	}
+	ids := []string{}
+	for _, e := range after {
+		if added[e.Id] {
+			ids = append(ids, e.Id)
+		}
+	}
+	return ids
+}
+
+// writeNewIds 将新增适配器的 Id 写为 JSON 数组，没有新增时写入空数组，使使用方不会读到上一次生成的结果
+func writeNewIds(path string, ids []string, perm os.FileMode) error {
+	data, err := json.MarshalIndent(ids, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(path, append(data, '\n'), perm)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+	"github.com/meloshub/meloshub-tools/internal/atomicfile"
+)
+
+// writeByAuthor 在 dir 下为每位作者写入一个 YAML 文件，内容为该作者的所有适配器，按 Title 排序
+// 拥有多位作者的适配器会出现在每位作者的文件中；返回写入的文件数
+func writeByAuthor(dir string, entries []catalog.Entry, perm os.FileMode) (int, error) {
+	groups := make(map[string][]catalog.Entry)
+	for _, entry := range entries {
+		authors, err := catalog.ParseAuthors(entry.Author)
+		if err != nil {
+			return 0, fmt.Errorf("adapter %s: %w", entry.Id, err)
+		}
+		seen := make(map[string]bool)
+		for _, author := range authors {
+			name := authorFileName(author)
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			groups[name] = append(groups[name], entry)
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, fmt.Errorf("could not create directory %s: %w", dir, err)
+	}
+
+	for name, group := range groups {
+		sort.SliceStable(group, func(i, j int) bool {
+			return group[i].Title < group[j].Title
+		})
+		data, err := catalog.Marshal(group, catalog.FormatYAML)
+		if err != nil {
+			return 0, err
+		}
+		path := filepath.Join(dir, name+".yaml")
+		if err := atomicfile.WriteFile(path, data, perm); err != nil {
+			return 0, fmt.Errorf("could not write %s: %w", path, err)
+		}
+	}
+	return len(groups), nil
+}
+
+// authorFileName 将作者转换为安全的文件名：优先使用名称，没有名称时使用邮箱
+func authorFileName(author catalog.Author) string {
+	source := author.Name
+	if source == "" {
+		source = author.Email
+	}
+	return sanitizeFileName(source)
+}
+
+// sanitizeFileName 只保留小写字母与数字，其余连续字符替换为一个 -；结果为空时返回 unknown
+func sanitizeFileName(source string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(source) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+
+	name := strings.TrimSuffix(b.String(), "-")
+	if name == "" {
+		return "unknown"
+	}
+	return name
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+	"github.com/meloshub/meloshub-tools/internal/textdiff"
+	"github.com/meloshub/meloshub-tools/metadiff"
+)
+
+// --check-diff 可以指定的差异形式
+const (
+	// checkDiffReport 按适配器列出重新生成后会新增、移除与变化的内容
+	checkDiffReport = "report"
+	// checkDiffText 将现有文件与生成内容之间的统一格式差异写入标准输出
+	checkDiffText = "text"
+)
+
+// checkDiffContext --check-diff text 每个变动块前后保留的上下文行数
+const checkDiffContext = 3
+
+// parseCheckDiff 检查 --check-diff 的取值
+func parseCheckDiff(mode string) error {
+	if mode != checkDiffReport && mode != checkDiffText {
+		return fmt.Errorf("unknown diff form %q (expected %s or %s)", mode, checkDiffReport, checkDiffText)
+	}
+	return nil
+}
+
+// runCheck 将本应写入的内容 generated 与现有输出文件逐字节比较，文件不存在或内容不同时按 diffMode 描述差异并返回错误
+// entries 为写入文件的条目，用于与现有文件解析出的条目比较
+func runCheck(path, format string, generated []byte, entries []catalog.Entry, diffMode string) error {
+	existing, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("could not read %s: %w", path, err)
+	}
+	if err == nil && bytes.Equal(existing, generated) {
+		infoLog.Printf("%s is up to date.", path)
+		return nil
+	}
+
+	if diffMode == checkDiffText {
+		fmt.Print(textdiff.Unified(path, existing, path+" (generated)", generated, checkDiffContext))
+	} else {
+		previous, err := readExistingMetadata(path, format)
+		if err != nil {
+			return err
+		}
+		logStaleChanges(metadiff.Compare(previous, entries, metadiff.Options{}), path)
+	}
+	return fmt.Errorf("%s is out of date; run metagen to regenerate it", path)
+}
+
+// logStaleChanges 逐个输出重新生成后 path 中会新增、移除与变化的适配器
+func logStaleChanges(report metadiff.ChangeReport, path string) {
+	for _, e := range report.Added {
+		log.Printf("Stale: adapter '%s' is missing from %s and would be added.", e.Id, path)
+	}
+	for _, e := range report.Removed {
+		log.Printf("Stale: adapter '%s' is no longer generated and would be removed from %s.", e.Id, path)
+	}
+	for _, u := range report.Updated {
+		for _, change := range metadiff.ChangedFields(u.Before, u.After) {
+			log.Printf("Stale: adapter '%s' would change %s from %q to %q.", u.After.Id, change.Field, change.Before, change.After)
+		}
+		if c := u.Capabilities; c != nil {
+			names := append(append(append([]string{}, c.Added...), c.Removed...), c.Flipped...)
+			log.Printf("Stale: adapter '%s' would change capabilities %s.", u.After.Id, strings.Join(names, ", "))
+		}
+		if t := u.Titles; t != nil {
+			locales := append(append(append([]string{}, t.Added...), t.Removed...), t.Changed...)
+			log.Printf("Stale: adapter '%s' would change titles %s.", u.After.Id, strings.Join(locales, ", "))
+		}
+	}
+	if len(report.Added)+len(report.Removed)+len(report.Updated) == 0 {
+		log.Printf("Stale: %s lists the same adapters, but differs in formatting or order.", path)
+		return
+	}
+	log.Printf("Regenerating %s would add %d, remove %d and update %d adapters.",
+		path, len(report.Added), len(report.Removed), len(report.Updated))
+}
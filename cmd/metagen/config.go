@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfigFile 未指定 --config 时自动读取的配置文件，位于扫描目录（当前目录）中
+const defaultConfigFile = ".metagen.yaml"
+
+// applyConfigFile 用配置文件设置尚未被命令行参数或环境变量设置的参数，返回实际读取的文件路径
+// path 为空时读取扫描目录中的 .metagen.yaml，文件不存在时返回空路径；显式指定的文件必须存在。
+// 配置文件为映射，键与参数名相同（不带 --），值为字符串、布尔值、数字或列表，列表按逗号拼接，
+// 对应 --plugin、--platforms 等接受逗号分隔列表的参数；未知的键视为错误
+func applyConfigFile(path string) (string, error) {
+	explicitPath := path != ""
+	if !explicitPath {
+		path = defaultConfigFile
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !explicitPath {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("could not read config file: %w", err)
+	}
+
+	var values map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return "", fmt.Errorf("could not parse config file %s: %w", path, err)
+	}
+
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if name == "config" || flag.Lookup(name) == nil {
+			return "", fmt.Errorf("%s: unknown key %q", path, name)
+		}
+		value, err := configValue(values[name])
+		if err != nil {
+			return "", fmt.Errorf("%s: key %q: %w", path, name, err)
+		}
+		if set[name] {
+			continue
+		}
+		if err := flag.Set(name, value); err != nil {
+			return "", fmt.Errorf("%s: key %q: %w", path, name, err)
+		}
+	}
+	return path, nil
+}
+
+// configValue 将配置文件中的值转换为参数的字符串形式；标量保留书写的原文，
+// 使 output-mode: 0640 这类值不会先被 YAML 解析为数字
+func configValue(node yaml.Node) (string, error) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Tag == "!!null" {
+			return "", errors.New("value is empty")
+		}
+		return node.Value, nil
+	case yaml.SequenceNode:
+		items := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return "", errors.New("list items must be scalars")
+			}
+			if strings.Contains(item.Value, ",") {
+				return "", fmt.Errorf("list item %q contains a comma", item.Value)
+			}
+			items = append(items, item.Value)
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return "", errors.New("value must be a scalar or a list")
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+	"github.com/meloshub/meloshub-tools/internal/atomicfile"
+	"github.com/meloshub/meloshub-tools/metadiff"
+)
+
+// driftTimeout 获取远程目录的超时时间
+const driftTimeout = 30 * time.Second
+
+// parseDriftURL 检查 --drift 给出的地址，只接受 http 与 https
+func parseDriftURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("%q is not an http or https URL", raw)
+	}
+	return nil
+}
+
+// fetchRemoteCatalog 下载远程目录并按 format 解析；yaml 与 envelope 格式同时接受平铺列表与信封
+func fetchRemoteCatalog(rawURL, format string) ([]catalog.Entry, error) {
+	client := &http.Client{Timeout: driftTimeout}
+	resp, err := client.Get(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not fetch %s: %s", rawURL, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", rawURL, err)
+	}
+
+	var entries []catalog.Entry
+	if format == catalog.FormatYAML || format == catalog.FormatEnvelope {
+		entries, _, err = catalog.DecodeYAML(data)
+	} else {
+		entries, err = catalog.Unmarshal(data, format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s catalog from %s: %w", format, rawURL, err)
+	}
+	return entries, nil
+}
+
+// checkDrift 将生成的条目与远程目录比较，逐个输出差异；存在差异时返回错误
+// 远程目录视为基准：只在本地生成的适配器报告为 added，只在远程存在的报告为 removed。
+// reportPath 不为空时将 JSON 格式的比较报告写入该文件，无论是否存在差异
+func checkDrift(rawURL string, entries []catalog.Entry, format, reportPath string, perm os.FileMode) error {
+	remote, err := fetchRemoteCatalog(rawURL, format)
+	if err != nil {
+		return err
+	}
+	report := metadiff.Compare(remote, entries, metadiff.Options{})
+
+	if reportPath != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := atomicfile.WriteFile(reportPath, append(data, '\n'), perm); err != nil {
+			return fmt.Errorf("could not write drift report: %w", err)
+		}
+		infoLog.Printf("Wrote drift report to %s", reportPath)
+	}
+
+	for _, e := range report.Added {
+		log.Printf("Drift: adapter '%s' is generated locally but missing from the remote catalog.", e.Id)
+	}
+	for _, e := range report.Removed {
+		log.Printf("Drift: adapter '%s' is in the remote catalog but was not generated locally.", e.Id)
+	}
+	for _, u := range report.Updated {
+		var fields []string
+		for _, change := range metadiff.ChangedFields(u.Before, u.After) {
+			fields = append(fields, change.Field)
+		}
+		if u.Capabilities != nil {
+			fields = append(fields, "capabilities")
+		}
+		if u.Titles != nil {
+			fields = append(fields, "titles")
+		}
+		if len(fields) == 0 {
+			log.Printf("Drift: adapter '%s' differs from the remote catalog.", u.After.Id)
+			continue
+		}
+		log.Printf("Drift: adapter '%s' differs from the remote catalog in %s.", u.After.Id, strings.Join(fields, ", "))
+	}
+
+	if n := len(report.Added) + len(report.Removed) + len(report.Updated); n > 0 {
+		return fmt.Errorf("%d adapters drift from %s (%d only local, %d only remote, %d differ)",
+			n, rawURL, len(report.Added), len(report.Removed), len(report.Updated))
+	}
+	infoLog.Printf("No drift from %s (%d adapters).", rawURL, len(entries))
+	return nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/meloshub/meloshub-tools/diagnostics"
+	"github.com/meloshub/meloshub-tools/metascan"
+)
+
+// scanGitRef 将 ref 检出到临时的 git 工作树中，在与 rootDir 对应的目录下调用 scan，结束后删除工作树
+// 结果中的源码位置会被映射回 rootDir 下的同名文件，使 SARIF 与警告中的路径与直接扫描时一致
+func scanGitRef(rootDir, ref string, scan func(dir string) ([]metascan.Result, error), diags *diagnostics.Diagnostics) ([]metascan.Result, error) {
+	prefix, err := runGit(rootDir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, fmt.Errorf("--git-ref requires running inside a git repository: %w", err)
+	}
+	commit, err := runGit(rootDir, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("unknown git ref %q: %w", ref, err)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "metagen-ref-*")
+	if err != nil {
+		return nil, fmt.Errorf("could not create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	infoLog.Printf("Checking out %s (%s) into temporary worktree %s", ref, commit, tmpDir)
+	if _, err := runGit(rootDir, "worktree", "add", "--detach", tmpDir, commit); err != nil {
+		return nil, fmt.Errorf("could not create worktree for %s: %w", ref, err)
+	}
+	defer func() {
+		if _, err := runGit(rootDir, "worktree", "remove", "--force", tmpDir); err != nil {
+			diags.Warnf("", token.Position{}, "could not remove temporary worktree %s: %v", tmpDir, err)
+		}
+	}()
+
+	scanDir := filepath.Join(tmpDir, filepath.FromSlash(prefix))
+	results, err := scan(scanDir)
+	for i := range results {
+		pos := &results[i].Pos
+		if rel, err := filepath.Rel(scanDir, pos.Filename); err == nil && !strings.HasPrefix(rel, "..") {
+			pos.Filename = filepath.Join(rootDir, rel)
+		}
+	}
+	return results, err
+}
+
+// runGit 在 dir 中执行 git 命令并返回去除首尾空白的标准输出，失败时错误信息中附带标准错误输出
+func runGit(dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, msg)
+		}
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -1,347 +1,779 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"errors"
 	"flag"
 	"fmt"
-	"go/ast"
-	"go/constant"
 	"go/token"
-	"go/types"
+	"io"
 	"log"
+	"math"
 	"os"
+	"runtime/debug"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
-	"github.com/meloshub/meloshub/adapter"
-	"golang.org/x/tools/go/packages"
-	"gopkg.in/yaml.v3"
+	"github.com/meloshub/meloshub-tools/catalog"
+	"github.com/meloshub/meloshub-tools/diagnostics"
+	"github.com/meloshub/meloshub-tools/internal/atomicfile"
+	"github.com/meloshub/meloshub-tools/metadiff"
+	"github.com/meloshub/meloshub-tools/metascan"
 )
 
+// infoLog 信息性日志，如 "Successfully generated"；--quiet-success 时丢弃，警告与错误仍写入标准 log
+var infoLog = log.New(os.Stderr, "", log.LstdFlags)
+
 func main() {
-	outputFile := flag.String("output", "adapters.yaml", "Path to the output YAML file")
+	outputFile := flag.String("output", "adapters.yaml", "Path to the output file (default can be overridden by METAGEN_OUTPUT)")
+	format := flag.String("format", catalog.FormatYAML, "Output format: yaml, csv, envelope, ndjson or pb (default can be overridden by METAGEN_FORMAT)")
+	requireAuthorEmail := flag.Bool("require-author-email", false, "Fail when an adapter's Author has no parseable email")
+	merge := flag.Bool("merge", false, "Merge scanned adapters into the existing output file instead of replacing it")
+	prune := flag.Bool("prune", false, "With --merge, drop existing entries whose Id was not found in the current scan; with --per-adapter-dir, delete files of adapters that no longer exist")
+	sarifFile := flag.String("sarif", "", "Write validation findings as a SARIF 2.1.0 report to this file")
+	packagesJSON := flag.String("packages-json", "", "Scan packages described by this `go list -json` output instead of loading them")
+	trace := flag.Bool("trace", false, "Log each step of resolving adapter metadata, for debugging undiscovered adapters")
+	check := flag.Bool("check", false, "Fail if the output file is not what this run would write, without writing any file; for CI")
+	checkDiff := flag.String("check-diff", checkDiffReport, "How --check describes a stale output file: report (the adapters that would be added, removed or changed) or text (a unified diff of the file contents on stdout)")
+	dryRun := flag.Bool("dry-run", false, "Run the scan and checks, report what would change, but do not write any file")
+	failOnWarning := flag.Bool("fail-on-warning", false, "Exit non-zero after the run if any warning was emitted, e.g. an untraced constructor or a validation finding; output files are still written")
+	strict := flag.Bool("strict", false, "Fail when a registration is invalid, e.g. the constructor's return type is not assignable to the type Register expects, instead of only warning")
+	metadataType := flag.String("metadata-type", metascan.DefaultMetadataType, "Type name suffix of the metadata struct literal, for forks that rename adapter.Metadata")
+	batchRegister := flag.String("batch-register", metascan.DefaultBatchRegister, "Name of a batch registration function whose slice-literal argument lists adapters to register")
+	registerArgIndex := flag.Int("register-arg-index", 0, "Index of the adapter.Register argument that holds the adapter or its constructor, for forks with signatures such as Register(name string, ctor func() Adapter)")
+	minDescriptionLen := flag.Int("min-description-len", 0, "Warn about descriptions shorter than this many characters or consisting of placeholder text such as TODO (0 disables)")
+	defaultLocale := flag.String("default-locale", "", "Fill an empty Title from this locale of the adapter's Titles map, e.g. en, for clients that only read Title")
+	maxDescriptionLen := flag.Int("max-description-len", 0, "Truncate descriptions longer than this many characters at a word boundary, ending with an ellipsis (0 disables)")
+	shortDescription := flag.Bool("short-description", false, "With --max-description-len, keep the full description and write the truncated one to short_description instead")
+	includeTests := flag.Bool("include-tests", false, "Also scan _test.go files by loading test variants of each package")
+	byAuthorDir := flag.String("by-author", "", "Also write one YAML file per author into this directory, listing that author's adapters by Title")
+	versionFromModule := flag.Bool("version-from-module", false, "Use the adapter's module version (or the main module's latest git tag) when Version cannot be resolved statically")
+	sortBy := flag.String("sort-by", catalog.SortKeyId, "Comma-separated keys the adapters are sorted by before writing: id, title, type or author, e.g. type,title; ties are broken by Id. Changing the keys changes the output file's bytes, so --check reports it as out of date")
+	noSort := flag.Bool("no-sort", false, "Keep adapters in discovery order (by package path, then source order) instead of sorting by Id; for debugging only, the order is not stable across code reorganizations")
+	sourceHashFile := flag.String("include-source-hash", "", "Also write a SHA-256 of the source declaration (usually the constructor) that defines each adapter's metadata to this JSON file, keyed by Id; it changes whenever that source changes, even if the metadata does not")
+	annotateNewFile := flag.String("annotate-new", "", "Also write the Ids of adapters that are not in the existing output file, i.e. new in this generation, as a JSON array to this file; the output file itself is unchanged")
+	warningsFile := flag.String("warnings", "", "Also write all warnings as a JSON array of {id, file, line, message, severity} to this file")
+	normalizeUnicode := flag.Bool("normalize-unicode", false, "Convert metadata string fields to Unicode NFC, so composed and decomposed spellings of the same text produce identical output")
+	warnClones := flag.Bool("warn-clones", false, "Warn about adapters whose metadata is identical apart from the Id, which usually indicates a copy-pasted definition")
+	descriptionStyle := flag.Bool("description-style", false, "Warn about descriptions that do not start with a capital letter or end with a period")
+	fixDescriptionStyle := flag.Bool("fix-description-style", false, "Capitalize descriptions and add a missing trailing period before validation")
+	trim := flag.Bool("trim", false, "Trim leading/trailing whitespace and collapse repeated spaces in metadata fields instead of reporting them")
+	checksum := flag.Bool("checksum", false, "With --format envelope, record a SHA-256 of the canonical adapter list in the envelope header")
+	verifyFile := flag.String("verify", "", "Verify the checksum of this existing envelope file and exit, without scanning")
+	listTypes := flag.Bool("list-types", false, "Print each distinct adapter Type with its count to stdout and exit, without writing output")
+	perAdapterDir := flag.String("per-adapter-dir", "", "Also write each adapter's metadata to <dir>/<id>.yaml")
+	gitRef := flag.String("git-ref", "", "Scan the sources at this git ref in a temporary worktree instead of the working tree; outputs are still written relative to the current directory")
+	platformList := flag.String("platforms", "", "Comma-separated GOOS/GOARCH pairs; scan once per platform and merge the adapters found on any of them")
+	profileTop := flag.Int("profile", 0, "Time the analysis of each package and log the slowest N packages after the scan (0 disables)")
+	profileCSV := flag.String("profile-csv", "", "Time the analysis of each package and write all timings as CSV to this file")
+	workers := flag.Int("workers", 0, "Number of packages to analyse in parallel (0 uses GOMAXPROCS); results, logs and warnings keep the same order as a sequential scan")
+	maxMemory := flag.Uint64("max-memory", 0, "Soft heap cap in bytes: when it is reached, fewer packages are analysed in parallel until the heap shrinks again; also set as the Go runtime memory limit (0 disables)")
+	serveMode := flag.Bool("serve", false, "Serve adapter metadata over HTTP (GET /adapters, GET /adapters/{id}) and rescan when sources change, instead of writing output")
+	addr := flag.String("addr", "localhost:8080", "Address the --serve HTTP server listens on")
+	outputMode := flag.String("output-mode", "0644", "Octal permission bits of the output file and all sidecar files (per-adapter, by-author, SARIF, warnings, source hashes, new adapter Ids, profile CSV, drift report)")
+	fieldList := flag.String("fields", "", "Comma-separated fields to keep in the output file, e.g. id,title,type; other fields are zeroed (default all fields)")
+	allowConflicts := flag.Bool("allow-conflicts", false, "Warn about adapters sharing an Id instead of failing; the last one scanned (in package path order) wins")
+	lineEndings := flag.String("line-endings", catalog.LineEndingsLF, "Line endings of the text output file: lf or crlf")
+	bom := flag.Bool("bom", false, "Start the text output file with a UTF-8 byte order mark")
+	strictFields := flag.Bool("strict-fields", false, "Fail when a metadata field is given a value in source that the scanner cannot evaluate statically and would be left empty; use with --trace to see why")
+	postHook := flag.String("post-hook", "", "Shell command to run after a successful write, with the output file path appended as its last argument; counts are passed as METAGEN_HOOK_* environment variables and a non-zero exit fails metagen")
+	ignoreCaseId := flag.Bool("ignore-case-id", false, "Treat adapter Ids that differ only in case, such as spotify and Spotify, as conflicting in duplicate and conflict checks")
+	quietSuccess := flag.Bool("quiet-success", false, "Print nothing on a clean run: suppress informational log lines but keep warnings and errors")
+	pluginList := flag.String("plugin", "", "Comma-separated Go plugins (.so) whose adapters are read at runtime and added to the scanned ones; each must export Adapters or Metadata, or register its adapters in init")
+	driftURL := flag.String("drift", "", "After generating, fetch the published catalog at this http(s) URL (in --format) and fail if the generated adapters differ from it")
+	driftReport := flag.String("drift-report", "", "With --drift, also write the local-vs-remote comparison as a JSON change report to this file")
+	countOnly := flag.Bool("count-only", false, "Print the number of scanned adapters to stdout and exit, skipping validation and all file output")
+	configFile := flag.String("config", "", "Read flag values from this YAML file instead of "+defaultConfigFile+" in the scan directory; keys are flag names, and flags given on the command line or through the environment take precedence")
 	flag.Parse()
 
-	rootDir, err := os.Getwd()
-	if err != nil {
-		log.Fatalf("Error getting working directory: %v", err)
+	if err := applyEnvOverrides(map[string]string{
+		"output": "METAGEN_OUTPUT",
+		"format": "METAGEN_FORMAT",
+	}); err != nil {
+		log.Fatalf("Invalid environment override: %v", err)
 	}
 
-	log.Println("Starting metadata scan in:", rootDir)
-
-	cfg := &packages.Config{
-		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo,
-		Dir:  rootDir,
-	}
-	pkgs, err := packages.Load(cfg, "./...")
+	// 环境变量设置的参数同样会被 flag.Visit 视为已设置，因此先应用环境变量，配置文件只填补剩余的参数
+	loadedConfig, err := applyConfigFile(*configFile)
 	if err != nil {
-		log.Fatalf("Error loading packages: %v", err)
+		log.Fatalf("Invalid config file: %v", err)
 	}
 
-	var allMetadata []adapter.Metadata
-
-	for _, pkg := range pkgs {
-		if isIrrelevantPackage(pkg) {
-			continue
-		}
-
-		if meta := findMetadataInPackage(pkg); meta != nil {
-			allMetadata = append(allMetadata, *meta)
-			log.Printf("Found metadata for adapter: %s", meta.Id)
-		}
+	if *quietSuccess {
+		infoLog.SetOutput(io.Discard)
+	}
+	if loadedConfig != "" {
+		infoLog.Printf("Loaded configuration from %s", loadedConfig)
 	}
 
-	// 没有适配器就删除yml文件并结束流程
-	if len(allMetadata) == 0 {
-		log.Println("No metadata found. Ensuring adapters.yaml does not exist.")
-		if err := os.Remove(*outputFile); err != nil && !errors.Is(err, os.ErrNotExist) {
-			log.Fatalf("Failed to remove existing file %s: %v", *outputFile, err)
+	if *verifyFile != "" {
+		if err := verifyEnvelope(*verifyFile); err != nil {
+			log.Fatalf("Verification failed: %v", err)
 		}
-		log.Printf("Successfully ensured %s is removed.", *outputFile)
+		infoLog.Printf("Checksum of %s verified.", *verifyFile)
 		return
 	}
 
-	// 在写入文件前进行冲突检查
-	if err := checkConflicts(allMetadata, *outputFile); err != nil {
-		// 如果发生冲突则报错，且CI将会失败
-		log.Fatalf("Conflict check failed: %v", err)
+	if !slices.Contains(catalog.Formats, *format) {
+		log.Fatalf("Unknown --format %q, expected one of %s.", *format, strings.Join(catalog.Formats, ", "))
 	}
-	log.Println("Conflict check passed.")
 
-	sort.Slice(allMetadata, func(i, j int) bool {
-		return allMetadata[i].Id < allMetadata[j].Id
-	})
+	if *checksum && *format != catalog.FormatEnvelope {
+		log.Fatal("--checksum can only be used together with --format envelope.")
+	}
 
-	yamlData, err := yaml.Marshal(allMetadata)
+	crlf, err := catalog.ParseLineEndings(*lineEndings)
 	if err != nil {
-		log.Fatalf("Error marshalling to YAML: %v", err)
+		log.Fatalf("Invalid --line-endings: %v", err)
+	}
+	textEncoding := catalog.TextEncoding{CRLF: crlf, BOM: *bom}
+	if !textEncoding.IsDefault() && *format == catalog.FormatProtobuf {
+		log.Fatal("--line-endings crlf and --bom only apply to text formats and cannot be used with --format pb.")
 	}
 
-	err = os.WriteFile(*outputFile, yamlData, 0644)
+	perm, err := parseFileMode(*outputMode)
 	if err != nil {
-		log.Fatalf("Error writing output file: %v", err)
+		log.Fatalf("Invalid --output-mode: %v", err)
 	}
 
-	log.Printf("Successfully generated metadata for %d adapters into %s", len(allMetadata), *outputFile)
-}
+	fields, err := catalog.ParseFields(*fieldList)
+	if err != nil {
+		log.Fatalf("Invalid --fields: %v", err)
+	}
 
-// checkConflicts 检查新生成的元数据与旧数据是否存在冲突
-func checkConflicts(newMetadata []adapter.Metadata, filePath string) error {
-	_, err := os.Stat(filePath)
-	// 如果文件不存在的话则不用检查冲突
-	if errors.Is(err, os.ErrNotExist) {
-		log.Println("No existing adapters.yaml file found, skipping conflict check.")
-		return nil
+	if *registerArgIndex < 0 {
+		log.Fatalf("Invalid --register-arg-index %d: must not be negative.", *registerArgIndex)
 	}
-	if err != nil {
-		return fmt.Errorf("could not stat existing file %s: %w", filePath, err)
+
+	if *maxDescriptionLen < 0 {
+		log.Fatalf("Invalid --max-description-len %d: must be greater than 0.", *maxDescriptionLen)
+	}
+	if *shortDescription && *maxDescriptionLen == 0 {
+		log.Fatal("--short-description can only be used together with --max-description-len.")
+	}
+	// 覆盖描述时，截断结果在之后的 --merge 中会作为现有条目再次被校验，下限大于上限的组合互相矛盾
+	if *maxDescriptionLen > 0 && !*shortDescription && *minDescriptionLen > *maxDescriptionLen {
+		log.Fatalf("--min-description-len %d exceeds --max-description-len %d.", *minDescriptionLen, *maxDescriptionLen)
+	}
+
+	if *prune && !*merge && *perAdapterDir == "" {
+		log.Fatal("--prune can only be used together with --merge or --per-adapter-dir.")
 	}
 
-	existingData, err := os.ReadFile(filePath)
+	rootDir, err := os.Getwd()
 	if err != nil {
-		return fmt.Errorf("could not read existing file %s: %w", filePath, err)
+		log.Fatalf("Error getting working directory: %v", err)
 	}
 
-	// 解析旧的元数据
-	var existingMetadata []adapter.Metadata
-	if err := yaml.Unmarshal(existingData, &existingMetadata); err != nil {
-		return fmt.Errorf("could not parse existing yaml file %s: %w", filePath, err)
+	diags := &diagnostics.Diagnostics{}
+	// 在 main 返回时检查，使所有正常结束的路径（包括 --dry-run 与 --check）都会处理累积的警告；
+	// 出现硬错误时 log.Fatal 直接退出，不会执行到这里
+	if *failOnWarning {
+		if *serveMode {
+			log.Fatal("--fail-on-warning cannot be used together with --serve, which never finishes.")
+		}
+		defer func() {
+			if err := warningFailure(diags); err != nil {
+				log.Fatalf("--fail-on-warning: %v.", err)
+			}
+		}()
+	}
+	if *workers < 0 {
+		log.Fatal("--workers must not be negative.")
+	}
+	// 运行时的内存上限让 GC 在接近上限时更积极地回收，同样作用于 packages.Load 的加载与类型检查
+	if *maxMemory > 0 {
+		debug.SetMemoryLimit(int64(min(*maxMemory, math.MaxInt64)))
+	}
+	scanOpts := metascan.Options{Trace: *trace, BatchRegister: *batchRegister, RegisterArgIndex: *registerArgIndex, MetadataType: *metadataType, Strict: *strict, IncludeTests: *includeTests, Diagnostics: diags, Quiet: *quietSuccess, SourceHash: *sourceHashFile != "", Workers: *workers, MaxMemory: *maxMemory}
+	profile := &scanProfile{}
+	if *profileTop > 0 || *profileCSV != "" {
+		scanOpts.OnPackage = profile.add
+		scanOpts.OnLoad = profile.setLoad
 	}
 
-	existingIdSet := make(map[string]bool)
-	for _, meta := range existingMetadata {
-		existingIdSet[meta.Id] = true
+	platforms, err := parsePlatforms(*platformList)
+	if err != nil {
+		log.Fatalf("Invalid --platforms: %v", err)
+	}
+	if len(platforms) > 0 && (*packagesJSON != "" || *serveMode) {
+		log.Fatal("--platforms cannot be used together with --packages-json or --serve.")
 	}
 
-	newIdSet := make(map[string]bool)
-	for _, meta := range newMetadata {
-		// 检查适配器元数据 ID 冲突
-		if existingIdSet[meta.Id] {
-		}
+	if *postHook != "" && *serveMode {
+		log.Fatal("--post-hook cannot be used together with --serve, which writes no output file.")
+	}
 
-		// 检查本次扫描内部是否有重复ID
-		if newIdSet[meta.Id] {
-			return fmt.Errorf("duplicate adapter Id '%s' found in the current scan", meta.Id)
-		}
-		newIdSet[meta.Id] = true
+	if *sourceHashFile != "" && *serveMode {
+		log.Fatal("--include-source-hash cannot be used together with --serve, which writes no output file.")
 	}
 
-	return nil
-}
+	if *annotateNewFile != "" && *serveMode {
+		log.Fatal("--annotate-new cannot be used together with --serve, which writes no output file.")
+	}
 
-// isIrrelevantPackage 过滤无需扫描的包
-func isIrrelevantPackage(pkg *packages.Package) bool {
-	// 过滤测试目录与适配器全集
-	hasIrrelevantSuffix := strings.HasSuffix(pkg.PkgPath, "/tests") || strings.HasSuffix(pkg.PkgPath, "/all")
+	if *pluginList != "" && *serveMode {
+		log.Fatal("--plugin cannot be used together with --serve, since a plugin cannot be reloaded.")
+	}
 
-	isEmptyPackage := len(pkg.GoFiles) == 0
+	sortKeys, err := catalog.ParseSortKeys(*sortBy)
+	if err != nil {
+		log.Fatalf("Invalid --sort-by: %v", err)
+	}
+	if *noSort && *sortBy != catalog.SortKeyId {
+		log.Fatal("--sort-by cannot be used together with --no-sort.")
+	}
 
-	return hasIrrelevantSuffix || isEmptyPackage
-}
+	if *check {
+		if *dryRun || *serveMode {
+			log.Fatal("--check cannot be used together with --dry-run or --serve.")
+		}
+		if err := parseCheckDiff(*checkDiff); err != nil {
+			log.Fatalf("Invalid --check-diff: %v", err)
+		}
+		if *checkDiff == checkDiffText && *format == catalog.FormatProtobuf {
+			log.Fatal("--check-diff text cannot be used with the binary --format pb.")
+		}
+	}
 
-// findMetadataInPackage 遍历包中的所有文件，寻找元数据
-func findMetadataInPackage(pkg *packages.Package) *adapter.Metadata {
-	for _, file := range pkg.Syntax {
-		if meta := findMetadataInFile(pkg, file); meta != nil {
-			return meta
+	if *driftURL != "" {
+		if *serveMode {
+			log.Fatal("--drift cannot be used together with --serve, which writes no output file.")
+		}
+		if err := parseDriftURL(*driftURL); err != nil {
+			log.Fatalf("Invalid --drift: %v", err)
 		}
+	} else if *driftReport != "" {
+		log.Fatal("--drift-report can only be used together with --drift.")
 	}
-	return nil
-}
 
-// findMetadataInFile 找到模块的init 函数，并从中追踪 Register 调用
-func findMetadataInFile(pkg *packages.Package, file *ast.File) *adapter.Metadata {
-	var foundMeta *adapter.Metadata
+	if *gitRef != "" && (*packagesJSON != "" || *serveMode) {
+		log.Fatal("--git-ref cannot be used together with --packages-json or --serve.")
+	}
 
-	ast.Inspect(file, func(n ast.Node) bool {
-		initFunc, ok := n.(*ast.FuncDecl)
-		if !ok || initFunc.Name.Name != "init" {
-			return true
+	if *serveMode {
+		if *packagesJSON != "" {
+			log.Fatal("--serve rescans the source tree and cannot be used together with --packages-json.")
 		}
+		// 服务长期运行，警告只写入日志而不累积在 diags 中
+		serveOpts := scanOpts
+		serveOpts.Diagnostics = nil
+		err := serve(*addr, rootDir, func() ([]catalog.Entry, error) {
+			scanned, err := metascan.Scan(rootDir, serveOpts)
+			if err != nil {
+				return nil, err
+			}
+			raw := metascan.Entries(scanned)
+			if *defaultLocale != "" {
+				catalog.ApplyDefaultLocale(raw, *defaultLocale)
+			}
+			if *normalizeUnicode {
+				catalog.NormalizeUnicode(raw)
+			}
+			entries, err := catalog.NewEntries(raw, catalog.Options{RequireAuthorEmail: *requireAuthorEmail})
+			if err != nil {
+				return nil, err
+			}
+			if *trim {
+				catalog.TrimWhitespace(entries)
+			}
+			if *fixDescriptionStyle {
+				catalog.FixDescriptionStyle(entries)
+			}
+			if *maxDescriptionLen > 0 {
+				catalog.TruncateDescriptions(entries, *maxDescriptionLen, *shortDescription)
+			}
+			catalog.SortBy(entries, sortKeys)
+			return entries, nil
+		}, nil)
+		log.Fatalf("Server failed: %v", err)
+	}
 
-		registerArg := findRegisterCallArgument(pkg.TypesInfo, initFunc.Body)
-		if registerArg == nil {
-			return false // 没有 Register 调用，一般不会出现这种情况，因为注册适配器是必要的
+	// 模块版本在扫描后立即解析：主模块的版本来自其目录中的 git 标签，而 --git-ref 的临时工作树只在扫描期间存在
+	resolveVersions := func(scanned []metascan.Result) error {
+		if !*versionFromModule {
+			return nil
 		}
-
-		constructorFunc := findConstructorFunc(pkg.TypesInfo, file, registerArg)
-		if constructorFunc == nil {
-			log.Printf("Warning: Found adapter.Register call in %s, but could not trace its constructor function.", pkg.Fset.File(file.Pos()).Name())
-			return false
+		if err := fillModuleVersions(scanned, diags); err != nil {
+			return fmt.Errorf("could not resolve module versions: %w", err)
+		}
+		return nil
+	}
+	scanTree := func(dir string) ([]metascan.Result, error) {
+		infoLog.Println("Starting metadata scan in:", dir)
+		var scanned []metascan.Result
+		var err error
+		if len(platforms) > 0 {
+			scanned, err = scanPlatforms(dir, scanOpts, platforms)
+		} else {
+			scanned, err = metascan.Scan(dir, scanOpts)
 		}
+		if err != nil {
+			return nil, err
+		}
+		return scanned, resolveVersions(scanned)
+	}
 
-		meta := findMetadataInFuncBody(pkg.TypesInfo, constructorFunc.Body)
-		if meta != nil {
-			foundMeta = meta
+	scanStart := time.Now()
+	var scanned []metascan.Result
+	switch {
+	case *packagesJSON != "":
+		infoLog.Println("Starting metadata scan of packages listed in:", *packagesJSON)
+		scanned, err = metascan.ScanPackagesJSON(*packagesJSON, scanOpts)
+		if err == nil {
+			err = resolveVersions(scanned)
+		}
+	case *gitRef != "":
+		scanned, err = scanGitRef(rootDir, *gitRef, scanTree, diags)
+	default:
+		scanned, err = scanTree(rootDir)
+	}
+	if err != nil {
+		log.Fatalf("Error scanning packages: %v", err)
+	}
+	if *pluginList != "" {
+		fromPlugins, err := loadPlugins(*pluginList)
+		if err != nil {
+			log.Fatalf("Error loading plugins: %v", err)
 		}
+		scanned = append(scanned, fromPlugins...)
+	}
+	if *strictFields {
+		if n := reportUnresolvedFields(scanned, diags); n > 0 {
+			log.Fatalf("--strict-fields: %d metadata fields could not be resolved statically (run with --trace for details).", n)
+		}
+	}
 
-		return false // 已处理此 init 函数，停止遍历
-	})
+	if *profileTop > 0 {
+		profile.logSlowest(*profileTop, time.Since(scanStart))
+	}
 
-	return foundMeta
-}
+	// 仅统计数量时跳过后续的校验、冲突检查与写入
+	if *countOnly {
+		fmt.Println(len(scanned))
+		return
+	}
+	if *listTypes {
+		printTypeCounts(os.Stdout, scanned)
+		return
+	}
 
-// findRegisterCallArgument 在函数体内寻找 adapter.Register 的调用，并返回其第一个参数。
-func findRegisterCallArgument(info *types.Info, body *ast.BlockStmt) ast.Expr {
-	var argExpr ast.Expr
+	if *allowConflicts {
+		scanned = resolveConflicts(scanned, *ignoreCaseId, diags)
+	}
 
-	ast.Inspect(body, func(n ast.Node) bool {
-		callExpr, ok := n.(*ast.CallExpr)
-		if !ok {
-			return true
+	rawEntries := metascan.Entries(scanned)
+	// 在校验之前填充，使缺少 Title 的检查针对填充后的标题
+	if *defaultLocale != "" {
+		for _, i := range catalog.ApplyDefaultLocale(rawEntries, *defaultLocale) {
+			diags.Warnf(rawEntries[i].Id, scanned[i].Pos, "adapter '%s' has Titles but no %q title, so Title stays empty.", rawEntries[i].Id, *defaultLocale)
 		}
+	}
+	// 在填充 Title 之后、派生作者名称等字段之前规范化，使填充的标题与派生字段同样是 NFC 形式
+	if *normalizeUnicode {
+		catalog.NormalizeUnicode(rawEntries)
+	}
+	allMetadata, err := catalog.NewEntries(rawEntries, catalog.Options{RequireAuthorEmail: *requireAuthorEmail})
+	if err != nil {
+		log.Fatalf("Invalid metadata: %v", err)
+	}
+
+	if *trim {
+		catalog.TrimWhitespace(allMetadata)
+	}
+	if *fixDescriptionStyle {
+		catalog.FixDescriptionStyle(allMetadata)
+	}
 
-		selExpr, ok := callExpr.Fun.(*ast.SelectorExpr)
-		if !ok || selExpr.Sel.Name != "Register" {
-			return true
+	if *merge {
+		allMetadata, err = mergeWithExisting(allMetadata, *outputFile, *format, *prune, *ignoreCaseId, diags)
+		if err != nil {
+			log.Fatalf("Merge failed: %v", err)
 		}
+	}
 
-		if obj := info.ObjectOf(selExpr.Sel); obj != nil {
-			if obj.Pkg() != nil && strings.HasSuffix(obj.Pkg().Path(), "meloshub/adapter") {
-				if len(callExpr.Args) > 0 {
-					argExpr = callExpr.Args[0]
-					return false
-				}
+	findings := catalog.Validate(allMetadata, catalog.ValidateOptions{MinDescriptionLen: *minDescriptionLen, DescriptionStyle: *descriptionStyle, WarnClones: *warnClones, IgnoreCaseId: *ignoreCaseId})
+	reportFindings(diags, findings, scanned)
+	if *ignoreCaseId {
+		if err := warnCaseRenames(allMetadata, *outputFile, *format, diags); err != nil {
+			log.Fatalf("Conflict check failed: %v", err)
+		}
+	}
+	// 校验针对作者编写的完整描述，截断只影响输出，因此在校验之后进行
+	if *maxDescriptionLen > 0 {
+		catalog.TruncateDescriptions(allMetadata, *maxDescriptionLen, *shortDescription)
+	}
+	if *sarifFile != "" && !*dryRun && !*check {
+		if err := writeSarif(*sarifFile, rootDir, diags.Entries(), perm); err != nil {
+			log.Fatalf("Error writing SARIF report: %v", err)
+		}
+		infoLog.Printf("Wrote %d validation findings to %s", len(findings), *sarifFile)
+	}
+	if *sourceHashFile != "" && !*dryRun && !*check {
+		count, err := writeSourceHashes(*sourceHashFile, rootDir, scanned, perm)
+		if err != nil {
+			log.Fatalf("Error writing source hashes: %v", err)
+		}
+		infoLog.Printf("Wrote source hashes of %d adapters to %s", count, *sourceHashFile)
+	}
+	if *warningsFile != "" && !*dryRun && !*check {
+		entries := diags.Entries()
+		if err := writeWarnings(*warningsFile, entries, perm); err != nil {
+			log.Fatalf("Error writing warnings file: %v", err)
+		}
+		infoLog.Printf("Wrote %d warnings to %s", len(entries), *warningsFile)
+	}
+	if *profileCSV != "" && !*dryRun && !*check {
+		if err := profile.writeCSV(*profileCSV, perm); err != nil {
+			log.Fatalf("Error writing profile: %v", err)
+		}
+		infoLog.Printf("Wrote timings of %d packages to %s", len(profile.timings), *profileCSV)
+	}
+	// 错误在附加文件写入之后才中止运行，使 SARIF 与 --warnings 文件中同样包含这些错误
+	if n := diags.Count(diagnostics.SeverityError); n > 0 {
+		for _, e := range diags.Entries() {
+			if e.Rule == catalog.RuleWhitespace {
+				log.Fatalf("Validation failed with %d errors; run with --trim to fix whitespace automatically.", n)
 			}
 		}
-		return true
-	})
+		log.Fatalf("Validation failed with %d errors.", n)
+	}
 
-	return argExpr
-}
+	// 没有适配器就删除yml文件并结束流程
+	if len(allMetadata) == 0 {
+		if *dryRun {
+			log.Printf("Dry run: no metadata found, %s would be removed.", *outputFile)
+			return
+		}
+		if *check {
+			if _, err := os.Stat(*outputFile); err == nil {
+				log.Fatalf("Check failed: no metadata found, but %s exists and would be removed.", *outputFile)
+			}
+			infoLog.Printf("%s is up to date.", *outputFile)
+			return
+		}
+		infoLog.Println("No metadata found. Ensuring adapters.yaml does not exist.")
+		if err := os.Remove(*outputFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Fatalf("Failed to remove existing file %s: %v", *outputFile, err)
+		}
+		infoLog.Printf("Successfully ensured %s is removed.", *outputFile)
+		return
+	}
 
-// findConstructorFunc 根据 Register 的参数，找到对应的构造函数 AST。
-func findConstructorFunc(info *types.Info, file *ast.File, arg ast.Expr) *ast.FuncDecl {
-	var constructorName string
+	if !*noSort {
+		catalog.SortBy(allMetadata, sortKeys)
+	}
 
-	if call, ok := arg.(*ast.CallExpr); ok {
-		if ident, ok := call.Fun.(*ast.Ident); ok {
-			constructorName = ident.Name
+	// writeOutput 写出输出文件的完整内容，--check 用它得到本应写入的字节
+	writeOutput := func(w io.Writer) error {
+		bw := bufio.NewWriter(w)
+		tw, err := textEncoding.Writer(bw)
+		if err != nil {
+			return err
+		}
+		if err := encodeOutput(tw, catalog.Project(allMetadata, fields), *format, *checksum); err != nil {
+			return err
 		}
+		return bw.Flush()
 	}
 
-	if ident, ok := arg.(*ast.Ident); ok {
-		obj := info.ObjectOf(ident)
-		if obj == nil {
-			return nil
+	if *check {
+		var generated bytes.Buffer
+		if err := writeOutput(&generated); err != nil {
+			log.Fatalf("Error encoding output: %v", err)
+		}
+		if err := runCheck(*outputFile, *format, generated.Bytes(), catalog.Project(allMetadata, fields), *checkDiff); err != nil {
+			log.Fatalf("Check failed: %v", err)
 		}
-		ast.Inspect(file, func(n ast.Node) bool {
-			assign, ok := n.(*ast.AssignStmt)
-			if !ok || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
-				return true
+		if *driftURL != "" {
+			if err := checkDrift(*driftURL, catalog.Project(allMetadata, fields), *format, "", perm); err != nil {
+				log.Fatalf("Drift check failed: %v", err)
 			}
-			if lhsIdent, ok := assign.Lhs[0].(*ast.Ident); ok {
-				if info.ObjectOf(lhsIdent) == obj {
-					if call, ok := assign.Rhs[0].(*ast.CallExpr); ok {
-						if funIdent, ok := call.Fun.(*ast.Ident); ok {
-							constructorName = funIdent.Name
-							return false
-						}
-					}
-				}
+		}
+		return
+	}
+
+	if *dryRun {
+		if err := reportDryRun(allMetadata, *outputFile, *format); err != nil {
+			log.Fatalf("Dry run failed: %v", err)
+		}
+		// 比较只读取远程目录，dry run 与 --check 时同样进行，但不写出报告文件
+		if *driftURL != "" {
+			if err := checkDrift(*driftURL, catalog.Project(allMetadata, fields), *format, "", perm); err != nil {
+				log.Fatalf("Drift check failed: %v", err)
 			}
-			return true
-		})
+		}
+		return
 	}
 
-	if constructorName == "" {
-		return nil
+	// 计数需要与写入前的文件比较，因此在覆盖之前读取
+	var previous []catalog.Entry
+	if *postHook != "" || *annotateNewFile != "" {
+		previous, err = readExistingMetadata(*outputFile, *format)
+		if err != nil {
+			log.Fatalf("Error reading existing output: %v", err)
+		}
 	}
 
-	var constructorFunc *ast.FuncDecl
-	ast.Inspect(file, func(n ast.Node) bool {
-		funcDecl, ok := n.(*ast.FuncDecl)
-		if ok && funcDecl.Name.Name == constructorName {
-			constructorFunc = funcDecl
-			return false
+	err = atomicfile.Write(*outputFile, perm, writeOutput)
+	if err != nil {
+		log.Fatalf("Error writing output file: %v", err)
+	}
+
+	infoLog.Printf("Successfully generated metadata for %d adapters into %s", len(allMetadata), *outputFile)
+
+	if *perAdapterDir != "" {
+		if err := writePerAdapter(*perAdapterDir, allMetadata, *prune, perm); err != nil {
+			log.Fatalf("Error writing per-adapter files: %v", err)
 		}
-		return true
-	})
+		infoLog.Printf("Wrote %d per-adapter files into %s", len(allMetadata), *perAdapterDir)
+	}
+
+	if *annotateNewFile != "" {
+		ids := newIds(previous, allMetadata)
+		if err := writeNewIds(*annotateNewFile, ids, perm); err != nil {
+			log.Fatalf("Error writing new adapter Ids: %v", err)
+		}
+		infoLog.Printf("Wrote %d new adapter Ids to %s", len(ids), *annotateNewFile)
+	}
+
+	if *byAuthorDir != "" {
+		count, err := writeByAuthor(*byAuthorDir, allMetadata, perm)
+		if err != nil {
+			log.Fatalf("Error writing per-author files: %v", err)
+		}
+		infoLog.Printf("Wrote %d per-author files into %s", count, *byAuthorDir)
+	}
+
+	if *postHook != "" {
+		infoLog.Printf("Running post-hook: %s", *postHook)
+		if err := runPostHook(*postHook, *outputFile, *format, newHookCounts(previous, allMetadata)); err != nil {
+			log.Fatalf("Post-hook failed: %v", err)
+		}
+	}
 
-	return constructorFunc
+	if *driftURL != "" {
+		infoLog.Printf("Checking for drift from %s", *driftURL)
+		if err := checkDrift(*driftURL, catalog.Project(allMetadata, fields), *format, *driftReport, perm); err != nil {
+			log.Fatalf("Drift check failed: %v", err)
+		}
+	}
 }
 
-// findMetadataInFuncBody 在任意函数体中寻找 adapter.Metadata 的创建实例
-func findMetadataInFuncBody(info *types.Info, body *ast.BlockStmt) *adapter.Metadata {
-	var foundMeta *adapter.Metadata
+// reportUnresolvedFields 将每个无法静态求值而留空的字段报告为错误，返回字段数
+func reportUnresolvedFields(scanned []metascan.Result, diags *diagnostics.Diagnostics) int {
+	count := 0
+	for _, result := range scanned {
+		for _, field := range result.PendingFields() {
+			diags.Errorf(result.Metadata.Id, field.Pos, "adapter '%s' field %s at %s: %s is %s", result.Metadata.Id, field.Field, field.Pos, field.Expr, field.Reason)
+			count++
+		}
+	}
+	return count
+}
+
+// errorRules 作为错误报告、使运行失败的校验规则：重复的 Id 无法写入同一个目录（--allow-conflicts 在校验之前已去重）；
+// 多余的空白可以由 --trim 自动修复，开启 --trim 时值在校验之前已被修整，不会再产生这类结果
+var errorRules = map[string]bool{
+	catalog.RuleDuplicateId: true,
+	catalog.RuleWhitespace:  true,
+}
 
-	ast.Inspect(body, func(n ast.Node) bool {
-		compLit, ok := n.(*ast.CompositeLit)
-		if !ok {
-			return true
+// reportFindings 将校验结果报告为警告（errorRules 中的规则报告为错误），scanned 与参与校验的条目前缀一一对应，用于定位问题所在的源码位置
+func reportFindings(diags *diagnostics.Diagnostics, findings []catalog.Finding, scanned []metascan.Result) {
+	for _, finding := range findings {
+		var pos token.Position
+		if finding.Index < len(scanned) {
+			pos = scanned[finding.Index].Pos
 		}
+		severity := diagnostics.SeverityWarning
+		if errorRules[finding.Rule] {
+			severity = diagnostics.SeverityError
+		}
+		diags.Report(diagnostics.Entry{
+			Severity: severity,
+			Id:       finding.Id,
+			File:     pos.Filename,
+			Line:     pos.Line,
+			Column:   pos.Column,
+			Rule:     finding.Rule,
+			Message:  finding.Message,
+		})
+	}
+}
 
-		if typ := info.TypeOf(compLit); typ != nil {
-			if strings.HasSuffix(typ.String(), "adapter.Metadata") {
-				meta := parseCompositeLit(info, compLit)
-				if meta != nil {
-					foundMeta = meta
-					return false
-				}
-			}
+// printTypeCounts 按类型名称排序输出每个类型的适配器数量，只出现一次的类型往往是拼写错误
+func printTypeCounts(w io.Writer, scanned []metascan.Result) {
+	counts := make(map[string]int)
+	for _, result := range scanned {
+		counts[string(result.Metadata.Type)]++
+	}
+
+	types := make([]string, 0, len(counts))
+	for typ := range counts {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	for _, typ := range types {
+		label := typ
+		if label == "" {
+			label = "(empty)"
 		}
-		return true
-	})
+		fmt.Fprintf(w, "%s\t%d\n", label, counts[typ])
+	}
+}
 
-	return foundMeta
+// encodeOutput 按输出格式写入条目；信封格式会带上生成工具信息，并在 withChecksum 时附带校验和
+func encodeOutput(w io.Writer, entries []catalog.Entry, format string, withChecksum bool) error {
+	if format != catalog.FormatEnvelope {
+		return catalog.Encode(w, entries, format)
+	}
+	env, err := catalog.NewEnvelope(entries, "metagen", withChecksum)
+	if err != nil {
+		return err
+	}
+	return catalog.EncodeEnvelope(w, env)
 }
 
-// parseCompositeLit 解析结构体字面量，提取键值对
-func parseCompositeLit(info *types.Info, expr ast.Expr) *adapter.Metadata {
-	compLit, ok := expr.(*ast.CompositeLit)
-	if !ok {
-		return nil
+// verifyEnvelope 重新计算信封文件中适配器列表的校验和并与头部比较
+func verifyEnvelope(path string) error {
+	env, err := catalog.ReadEnvelope(path)
+	if err != nil {
+		return err
 	}
+	return env.Verify()
+}
 
-	var meta adapter.Metadata
-	for _, el := range compLit.Elts {
-		if kv, ok := el.(*ast.KeyValueExpr); ok {
-			keyName := fmt.Sprintf("%s", kv.Key)
-			value := getExprValue(info, kv.Value)
-
-			switch keyName {
-			case "Id":
-				meta.Id = value
-			case "Title":
-				meta.Title = value
-			case "Type":
-				meta.Type = adapter.AdapterType(value)
-			case "Version":
-				meta.Version = value
-			case "Author":
-				meta.Author = value
-			case "Description":
-				meta.Description = value
-			}
+// applyEnvOverrides 用环境变量设置未在命令行中显式指定的参数
+// 优先级为：命令行参数 > 环境变量 > 内置默认值；空的环境变量视为未设置
+func applyEnvOverrides(envByFlag map[string]string) error {
+	explicit := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+
+	for name, env := range envByFlag {
+		value := os.Getenv(env)
+		if explicit[name] || value == "" {
+			continue
+		}
+		if err := flag.Set(name, value); err != nil {
+			return fmt.Errorf("%s: %w", env, err)
 		}
 	}
-	if meta.Id == "" {
-		return nil
+	return nil
+}
+
+// parseFileMode 解析八进制的文件权限，如 0640；只接受权限位，不接受 setuid 等特殊位
+func parseFileMode(s string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not an octal mode", s)
 	}
-	return &meta
+	if mode&^uint64(os.ModePerm) != 0 {
+		return 0, fmt.Errorf("%q has bits outside of 0777", s)
+	}
+	return os.FileMode(mode), nil
 }
 
-// getExprValue 从 AST 节点中提取常量或字符串字面量的值
-func getExprValue(info *types.Info, expr ast.Expr) string {
-	if basicLit, ok := expr.(*ast.BasicLit); ok && basicLit.Kind == token.STRING {
-		return strings.Trim(basicLit.Value, `"`)
+// resolveConflicts 按“后扫描者优先”处理 Id 相同的适配器：每个 Id 只保留最后扫描到的一个，
+// 其余的丢弃并给出警告。扫描按包路径排序，因此结果不依赖于包加载的顺序
+// ignoreCase 为 true 时只有大小写不同的 Id 也视为相同
+func resolveConflicts(scanned []metascan.Result, ignoreCase bool, diags *diagnostics.Diagnostics) []metascan.Result {
+	last := make(map[string]int)
+	for i, result := range scanned {
+		last[catalog.IdKey(result.Metadata.Id, ignoreCase)] = i
 	}
 
-	if ident, ok := expr.(*ast.Ident); ok {
-		if obj := info.ObjectOf(ident); obj != nil {
-			if cnst, ok := obj.(*types.Const); ok {
-				return constant.StringVal(cnst.Val())
-			}
+	var kept []metascan.Result
+	for i, result := range scanned {
+		id := result.Metadata.Id
+		if winner := last[catalog.IdKey(id, ignoreCase)]; winner != i {
+			diags.Warnf(id, result.Pos, "ignoring adapter '%s' at %s, which conflicts with the one at %s (--allow-conflicts keeps the last scanned).", id, result.Pos, scanned[winner].Pos)
+			continue
 		}
+		kept = append(kept, result)
 	}
+	return kept
+}
 
-	if selExpr, ok := expr.(*ast.SelectorExpr); ok {
-		if obj := info.ObjectOf(selExpr.Sel); obj != nil {
-			if cnst, ok := obj.(*types.Const); ok {
-				return constant.StringVal(cnst.Val())
-			}
+// mergeWithExisting 将扫描结果合并到现有文件中的条目上
+// 同一 Id 以扫描结果为准；现有文件中未被扫描到的条目在 prune 为 true 时删除，否则保留并给出警告
+// 现有条目只按 Id 精确匹配：ignoreCase 时保留下来的、只有大小写不同的旧条目会由 catalog.Validate 的重复 Id 检查报告，
+// 扫描到的新 Id 另由 warnCaseRenames 给出大小写变化的警告
+func mergeWithExisting(scanned []catalog.Entry, filePath, format string, prune, ignoreCase bool, diags *diagnostics.Diagnostics) ([]catalog.Entry, error) {
+	// 合并会按 Id 去重，因此需要先检查扫描结果内部的重复
+	if err := catalog.CheckIdConflicts(scanned, ignoreCase); err != nil {
+		return nil, err
+	}
+
+	existingMetadata, err := readExistingMetadata(filePath, format)
+	if err != nil {
+		return nil, err
+	}
+
+	scannedIds := make(map[string]bool)
+	for _, entry := range scanned {
+		scannedIds[entry.Id] = true
+	}
+
+	merged := scanned
+	for _, entry := range existingMetadata {
+		if scannedIds[entry.Id] {
+			continue
+		}
+		if prune {
+			// 删除条目改变了输出内容，即使 --quiet-success 也需要留下记录
+			log.Printf("Pruned stale adapter '%s' not found in the current scan.", entry.Id)
+			continue
 		}
+		diags.Warnf(entry.Id, token.Position{}, "keeping stale adapter '%s' not found in the current scan (use --prune to drop it).", entry.Id)
+		merged = append(merged, entry)
 	}
+	return merged, nil
+}
 
-	return ""
+// reportDryRun 将本次扫描结果与现有文件比较，并输出变动摘要
+func reportDryRun(newMetadata []catalog.Entry, filePath, format string) error {
+	existingMetadata, err := readExistingMetadata(filePath, format)
+	if err != nil {
+		return err
+	}
+
+	report := metadiff.Compare(existingMetadata, newMetadata, metadiff.Options{})
+	log.Printf("Dry run: %s would change with %d added, %d removed, %d updated adapters (%d total).",
+		filePath, len(report.Added), len(report.Removed), len(report.Updated), len(newMetadata))
+	return nil
+}
+
+// readExistingMetadata 按输出格式读取已存在的元数据文件，文件不存在时返回空列表
+func readExistingMetadata(filePath, format string) ([]catalog.Entry, error) {
+	existingMetadata, err := catalog.ReadFileFormat(filePath, format)
+	if errors.Is(err, os.ErrNotExist) {
+		return []catalog.Entry{}, nil
+	}
+	return existingMetadata, err
+}
+
+// warnCaseRenames 在 ignoreCase 时对只改了大小写的 Id 给出警告：它们在不区分大小写的注册表中是同一个适配器，
+// 而按原样比较 Id 的客户端会把它当作一次删除加一次新增；精确比较时两者本就是不同的适配器
+func warnCaseRenames(newMetadata []catalog.Entry, filePath, format string, diags *diagnostics.Diagnostics) error {
+	existingMetadata, err := readExistingMetadata(filePath, format)
+	if err != nil {
+		return err
+	}
+	existingIds := make(map[string]string)
+	for _, meta := range existingMetadata {
+		existingIds[catalog.IdKey(meta.Id, true)] = meta.Id
+	}
+	for _, meta := range newMetadata {
+		if existing, ok := existingIds[catalog.IdKey(meta.Id, true)]; ok && existing != meta.Id {
+			diags.Warnf(meta.Id, token.Position{}, "adapter Id '%s' differs only in case from '%s' in %s; clients that compare Ids exactly will see it as removed and re-added.", meta.Id, existing, filePath)
+		}
+	}
+	return nil
 }
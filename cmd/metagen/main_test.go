@@ -0,0 +1,109 @@
+package main
+
+import (
+	"go/token"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+	"github.com/meloshub/meloshub-tools/diagnostics"
+	"github.com/meloshub/meloshub-tools/metascan"
+	"github.com/meloshub/meloshub/adapter"
+)
+
+func idEntries(ids ...string) []catalog.Entry {
+	entries := make([]catalog.Entry, 0, len(ids))
+	for _, id := range ids {
+		entries = append(entries, catalog.Entry{Metadata: adapter.Metadata{Id: id}})
+	}
+	return entries
+}
+
+func TestWarnCaseRenames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "adapters.yaml")
+	if err := os.WriteFile(path, []byte("- id: Spotify\n- id: deezer\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	diags := &diagnostics.Diagnostics{}
+	if err := warnCaseRenames(idEntries("spotify", "deezer", "tidal"), path, catalog.FormatYAML, diags); err != nil {
+		t.Fatal(err)
+	}
+	entries := diags.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d warnings, want 1: %v", len(entries), entries)
+	}
+	if entries[0].Id != "spotify" || !strings.Contains(entries[0].Message, "'spotify' differs only in case from 'Spotify'") {
+		t.Errorf("warning = %+v, want one about spotify and Spotify", entries[0])
+	}
+}
+
+func TestWarnCaseRenamesWithoutExistingFile(t *testing.T) {
+	diags := &diagnostics.Diagnostics{}
+	path := filepath.Join(t.TempDir(), "adapters.yaml")
+	if err := warnCaseRenames(idEntries("spotify"), path, catalog.FormatYAML, diags); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(diags.Entries()); n != 0 {
+		t.Errorf("got %d warnings, want none", n)
+	}
+}
+
+func TestReportUnresolvedFields(t *testing.T) {
+	scanned := []metascan.Result{
+		{
+			Metadata: adapter.Metadata{Id: "unres", Version: "1.0.0"},
+			Unresolved: []metascan.UnresolvedField{
+				{Field: "Title", Expr: "title()", Reason: "a function call, which is only evaluated at run time", Pos: token.Position{Filename: "unres.go", Line: 7, Column: 3}},
+				// 扫描之后已由 --version-from-module 补上
+				{Field: "Version", Expr: "version", Reason: "a variable without a statically known string value"},
+			},
+		},
+		{Metadata: adapter.Metadata{Id: "ok"}},
+	}
+	diags := &diagnostics.Diagnostics{}
+	if n := reportUnresolvedFields(scanned, diags); n != 1 {
+		t.Errorf("reported %d fields, want 1", n)
+	}
+	entries := diags.Entries()
+	want := "adapter 'unres' field Title at unres.go:7:3: title() is a function call, which is only evaluated at run time"
+	if len(entries) != 1 || entries[0].Message != want || entries[0].Severity != diagnostics.SeverityError {
+		t.Errorf("diagnostics = %+v, want one error %q", entries, want)
+	}
+}
+
+// captureQuietLogs 模拟 --quiet-success：丢弃 infoLog，并返回测试期间写入标准 log 的内容
+func captureQuietLogs(t *testing.T) *strings.Builder {
+	t.Helper()
+	var logs strings.Builder
+	log.SetOutput(&logs)
+	infoLog.SetOutput(io.Discard)
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+		infoLog.SetOutput(os.Stderr)
+	})
+	return &logs
+}
+
+func TestMergeWithExistingPruneLogsWhenQuiet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "adapters.yaml")
+	if err := os.WriteFile(path, []byte("- id: spotify\n- id: stale\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	logs := captureQuietLogs(t)
+
+	merged, err := mergeWithExisting(idEntries("spotify"), path, catalog.FormatYAML, true, false, &diagnostics.Diagnostics{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merged) != 1 || merged[0].Id != "spotify" {
+		t.Errorf("merged = %+v, want only spotify", merged)
+	}
+	if want := "Pruned stale adapter 'stale' not found in the current scan."; !strings.Contains(logs.String(), want) {
+		t.Errorf("logs = %q, want them to contain %q", logs.String(), want)
+	}
+}
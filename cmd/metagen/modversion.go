@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"os/exec"
+	"strings"
+
+	"github.com/meloshub/meloshub-tools/diagnostics"
+	"github.com/meloshub/meloshub-tools/metascan"
+	"golang.org/x/tools/go/packages"
+)
+
+// fillModuleVersions 为无法静态解析出 Version 的适配器填入其所在模块的版本
+// 这是模块级别的版本：同一模块中的所有适配器都会得到相同的值，无法区分各自的版本
+func fillModuleVersions(scanned []metascan.Result, diags *diagnostics.Diagnostics) error {
+	versions := make(map[string]string)
+	for i := range scanned {
+		result := &scanned[i]
+		if result.Metadata.Version != "" {
+			continue
+		}
+		if result.Module == nil {
+			diags.Warnf(result.Metadata.Id, token.Position{}, "adapter '%s' has no static Version and its module is unknown.", result.Metadata.Id)
+			continue
+		}
+
+		version, ok := versions[result.Module.Path]
+		if !ok {
+			var err error
+			version, err = moduleVersion(result.Module, diags)
+			if err != nil {
+				return fmt.Errorf("module %s: %w", result.Module.Path, err)
+			}
+			versions[result.Module.Path] = version
+		}
+		if version == "" {
+			diags.Warnf(result.Metadata.Id, token.Position{}, "adapter '%s' has no static Version and module %s has no version.", result.Metadata.Id, result.Module.Path)
+			continue
+		}
+		infoLog.Printf("Using module version %s for adapter '%s'.", version, result.Metadata.Id)
+		result.Metadata.Version = version
+	}
+	return nil
+}
+
+// moduleVersion 返回模块的版本，不带前缀 v
+// 依赖模块的版本来自 go list -m；主模块没有版本号，改为使用其仓库中最近的 git 标签
+func moduleVersion(mod *packages.Module, diags *diagnostics.Diagnostics) (string, error) {
+	version := mod.Version
+	if mod.Replace != nil && mod.Replace.Version != "" {
+		version = mod.Replace.Version
+	}
+	if version == "" && mod.Main {
+		cmd := exec.Command("git", "describe", "--tags", "--abbrev=0")
+		cmd.Dir = mod.Dir
+		out, err := cmd.Output()
+		if err != nil {
+			// 没有任何标签时不视为错误
+			diags.Warnf("", token.Position{}, "could not determine a git tag for main module %s: %v", mod.Path, err)
+			return "", nil
+		}
+		version = strings.TrimSpace(string(out))
+	}
+	return strings.TrimPrefix(version, "v"), nil
+}
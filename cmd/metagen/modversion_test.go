@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/meloshub/meloshub-tools/diagnostics"
+	"github.com/meloshub/meloshub-tools/metascan"
+	"github.com/meloshub/meloshub/adapter"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestFillModuleVersions(t *testing.T) {
+	dep := &packages.Module{Path: "example.com/dep", Version: "v1.4.2"}
+	replaced := &packages.Module{Path: "example.com/fork", Version: "v1.0.0", Replace: &packages.Module{Path: "example.com/fork", Version: "v1.0.1-fix"}}
+	scanned := []metascan.Result{
+		{Metadata: adapter.Metadata{Id: "static", Version: "0.9.0"}, Module: dep},
+		{Metadata: adapter.Metadata{Id: "dep-a"}, Module: dep},
+		{Metadata: adapter.Metadata{Id: "dep-b"}, Module: dep},
+		{Metadata: adapter.Metadata{Id: "fork"}, Module: replaced},
+		{Metadata: adapter.Metadata{Id: "orphan"}},
+	}
+	diags := &diagnostics.Diagnostics{}
+	if err := fillModuleVersions(scanned, diags); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{"static": "0.9.0", "dep-a": "1.4.2", "dep-b": "1.4.2", "fork": "1.0.1-fix", "orphan": ""}
+	for _, result := range scanned {
+		if got := result.Metadata.Version; got != want[result.Metadata.Id] {
+			t.Errorf("%s Version = %q, want %q", result.Metadata.Id, got, want[result.Metadata.Id])
+		}
+	}
+	entries := diags.Entries()
+	if len(entries) != 1 || entries[0].Id != "orphan" {
+		t.Errorf("diagnostics = %+v, want one warning for orphan", entries)
+	}
+}
+
+func TestFillModuleVersionsFromGitTag(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "--allow-empty", "-m", "initial"},
+		{"tag", "v3.1.4"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	scanned := []metascan.Result{{Metadata: adapter.Metadata{Id: "main"}, Module: &packages.Module{Path: "example.com/main", Main: true, Dir: dir}}}
+	if err := fillModuleVersions(scanned, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := scanned[0].Metadata.Version; got != "3.1.4" {
+		t.Errorf("Version = %q, want 3.1.4 from the latest tag", got)
+	}
+
+	// 没有标签的仓库只警告，不视为错误
+	untagged := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", untagged).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	scanned = []metascan.Result{{Metadata: adapter.Metadata{Id: "main"}, Module: &packages.Module{Path: "example.com/main", Main: true, Dir: untagged}}}
+	diags := &diagnostics.Diagnostics{}
+	if err := fillModuleVersions(scanned, diags); err != nil {
+		t.Fatal(err)
+	}
+	if got := scanned[0].Metadata.Version; got != "" {
+		t.Errorf("untagged Version = %q, want empty", got)
+	}
+	if n := len(diags.Entries()); n != 2 {
+		t.Errorf("untagged: %d diagnostics, want a git warning and a missing-version warning", n)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+	"github.com/meloshub/meloshub-tools/internal/atomicfile"
+)
+
+// writePerAdapter 在 dir 下为每个适配器写入 <id>.yaml，内容只包含该适配器的元数据
+// 已存在的文件会被覆盖；prune 为 true 时删除目录中不再对应任何适配器的 .yaml 文件
+func writePerAdapter(dir string, entries []catalog.Entry, prune bool, perm os.FileMode) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("could not create directory %s: %w", dir, err)
+	}
+
+	written := make(map[string]string)
+	for _, entry := range entries {
+		name := sanitizeFileName(entry.Id) + ".yaml"
+		if other, exists := written[name]; exists {
+			return fmt.Errorf("adapters '%s' and '%s' both map to file %s", other, entry.Id, name)
+		}
+		written[name] = entry.Id
+
+		data, err := catalog.MarshalEntry(entry)
+		if err != nil {
+			return fmt.Errorf("adapter %s: %w", entry.Id, err)
+		}
+		path := filepath.Join(dir, name)
+		if err := atomicfile.WriteFile(path, data, perm); err != nil {
+			return fmt.Errorf("could not write %s: %w", path, err)
+		}
+	}
+
+	if !prune {
+		return nil
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("could not list directory %s: %w", dir, err)
+	}
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasSuffix(name, ".yaml") || written[name] != "" {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			return fmt.Errorf("could not prune %s: %w", name, err)
+		}
+		// 与 --merge --prune 相同，删除文件的记录不受 --quiet-success 影响
+		log.Printf("Pruned stale per-adapter file %s.", filepath.Join(dir, name))
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+	"github.com/meloshub/meloshub/adapter"
+)
+
+func TestWritePerAdapter(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "adapters")
+	entries := []catalog.Entry{
+		{Metadata: adapter.Metadata{Id: "spotify", Title: "Spotify", Type: adapter.TypeOfficial, Version: "1.0.0", Author: "Jane <jane@x.com>"}, AuthorName: "Jane", AuthorEmail: "jane@x.com"},
+		{Metadata: adapter.Metadata{Id: "NetEase Cloud", Title: "NetEase", Type: adapter.TypeCommunity, Version: "0.1.0", Author: "bob"}, Titles: map[string]string{"zh": "网易云"}},
+	}
+	if err := writePerAdapter(dir, entries, false, 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]catalog.Entry{"spotify.yaml": entries[0], "netease-cloud.yaml": entries[1]} {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 文件内容是单个条目而不是列表，与 MarshalEntry 的输出逐字节相同
+		wantData, err := catalog.MarshalEntry(want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != string(wantData) {
+			t.Errorf("%s =\n%s\nwant\n%s", name, data, wantData)
+		}
+		if strings.HasPrefix(string(data), "- ") {
+			t.Errorf("%s holds a list, want a single adapter", name)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0640 {
+			t.Errorf("%s has mode %o, want 640", name, perm)
+		}
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 2 {
+		t.Errorf("wrote %d files, want 2", len(files))
+	}
+}
+
+func TestWritePerAdapterPrune(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"stale.yaml", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries := []catalog.Entry{{Metadata: adapter.Metadata{Id: "spotify"}}}
+
+	if err := writePerAdapter(dir, entries, false, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "stale.yaml")); err != nil {
+		t.Errorf("stale.yaml was removed without prune: %v", err)
+	}
+
+	logs := captureQuietLogs(t)
+	if err := writePerAdapter(dir, entries, true, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if want := "Pruned stale per-adapter file " + filepath.Join(dir, "stale.yaml"); !strings.Contains(logs.String(), want) {
+		t.Errorf("logs = %q, want them to contain %q", logs.String(), want)
+	}
+	var names []string
+	files, _ := os.ReadDir(dir)
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	if want := []string{"notes.txt", "spotify.yaml"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("after prune: %v, want %v", names, want)
+	}
+}
+
+func TestWritePerAdapterNameCollision(t *testing.T) {
+	entries := []catalog.Entry{{Metadata: adapter.Metadata{Id: "Net Ease"}}, {Metadata: adapter.Metadata{Id: "net-ease"}}}
+	err := writePerAdapter(t.TempDir(), entries, false, 0644)
+	if err == nil || !strings.Contains(err.Error(), "both map to file net-ease.yaml") {
+		t.Errorf("err = %v, want a file name collision", err)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/meloshub/meloshub-tools/metascan"
+)
+
+// platform 一个 GOOS/GOARCH 组合
+type platform struct {
+	goos, goarch string
+}
+
+func (p platform) String() string {
+	return p.goos + "/" + p.goarch
+}
+
+// parsePlatforms 解析逗号分隔的 GOOS/GOARCH 列表，如 linux/amd64,darwin/arm64
+func parsePlatforms(list string) ([]platform, error) {
+	var platforms []platform
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		goos, goarch, ok := strings.Cut(item, "/")
+		if !ok || goos == "" || goarch == "" || strings.Contains(goarch, "/") {
+			return nil, fmt.Errorf("%q is not of the form GOOS/GOARCH", item)
+		}
+		platforms = append(platforms, platform{goos: goos, goarch: goarch})
+	}
+	return platforms, nil
+}
+
+// scanPlatforms 按每个平台的构建约束各扫描一次 dir，并合并所有平台的结果
+// 同一处源码（Id 与位置都相同）在多个平台上被发现时只保留一份；Id 相同而位置不同的适配器都会保留，
+// 交给后续的冲突检查处理。只在部分平台上存在的适配器会在日志中列出其所在的平台
+func scanPlatforms(dir string, opts metascan.Options, platforms []platform) ([]metascan.Result, error) {
+	type origin struct {
+		id   string
+		file string
+		line int
+	}
+	var merged []metascan.Result
+	found := make(map[origin][]platform)
+
+	for _, p := range platforms {
+		infoLog.Printf("Scanning for platform %s", p)
+		platformOpts := opts
+		platformOpts.Env = append(os.Environ(), "GOOS="+p.goos, "GOARCH="+p.goarch)
+		scanned, err := metascan.Scan(dir, platformOpts)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		for _, result := range scanned {
+			key := origin{result.Metadata.Id, result.Pos.Filename, result.Pos.Line}
+			if _, seen := found[key]; !seen {
+				merged = append(merged, result)
+			}
+			found[key] = append(found[key], p)
+		}
+	}
+
+	for _, result := range merged {
+		on := found[origin{result.Metadata.Id, result.Pos.Filename, result.Pos.Line}]
+		if len(on) == len(platforms) {
+			continue
+		}
+		names := make([]string, len(on))
+		for i, p := range on {
+			names[i] = p.String()
+		}
+		infoLog.Printf("Adapter '%s' is only found on %s.", result.Metadata.Id, strings.Join(names, ", "))
+	}
+	return merged, nil
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePlatforms(t *testing.T) {
+	got, err := parsePlatforms(" linux/amd64, darwin/arm64,,windows/386 ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []platform{{"linux", "amd64"}, {"darwin", "arm64"}, {"windows", "386"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePlatforms = %v, want %v", got, want)
+	}
+
+	for _, bad := range []string{"linux", "linux/", "/amd64", "linux/amd64/v2"} {
+		if _, err := parsePlatforms(bad); err == nil {
+			t.Errorf("parsePlatforms(%q) succeeded, want an error", bad)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"log"
+	"log/slog"
+	"plugin"
+	"sort"
+	"strings"
+
+	"github.com/meloshub/meloshub-tools/metascan"
+	"github.com/meloshub/meloshub/adapter"
+)
+
+// loadPlugins 依次打开逗号分隔的 Go 插件，返回其中适配器的元数据
+func loadPlugins(list string) ([]metascan.Result, error) {
+	var results []metascan.Result
+	for _, path := range strings.Split(list, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		metas, err := loadPlugin(path)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", path, err)
+		}
+		for _, meta := range metas {
+			infoLog.Printf("Found metadata for adapter: %s (plugin %s)", meta.Id, path)
+			// 插件中没有源码位置，以插件文件本身作为位置
+			results = append(results, metascan.Result{Metadata: meta, Pos: token.Position{Filename: path}})
+		}
+	}
+	return results, nil
+}
+
+// loadPlugin 打开单个插件并在运行时读取元数据
+// 优先使用导出的 Adapters 符号，其次是 Metadata 符号，二者均可以是变量或无参函数；
+// 都没有导出时，使用插件的 init 通过 adapter.Register 注册的适配器
+func loadPlugin(path string) ([]adapter.Metadata, error) {
+	before := adapter.GetAll()
+	p, err := openPlugin(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if sym, err := p.Lookup("Adapters"); err == nil {
+		var adapters []adapter.Adapter
+		switch v := sym.(type) {
+		case *[]adapter.Adapter:
+			adapters = *v
+		case func() []adapter.Adapter:
+			adapters = v()
+		default:
+			return nil, fmt.Errorf("exported symbol Adapters has type %T, expected []adapter.Adapter or func() []adapter.Adapter", sym)
+		}
+		metas := make([]adapter.Metadata, 0, len(adapters))
+		for _, a := range adapters {
+			metas = append(metas, a.Metadata())
+		}
+		return metas, nil
+	}
+
+	if sym, err := p.Lookup("Metadata"); err == nil {
+		switch v := sym.(type) {
+		case *adapter.Metadata:
+			return []adapter.Metadata{*v}, nil
+		case func() adapter.Metadata:
+			return []adapter.Metadata{v()}, nil
+		case *[]adapter.Metadata:
+			return *v, nil
+		case func() []adapter.Metadata:
+			return v(), nil
+		default:
+			return nil, fmt.Errorf("exported symbol Metadata has type %T, expected adapter.Metadata, []adapter.Metadata or a function returning either", sym)
+		}
+	}
+
+	// 同一个插件只会被初始化一次，注册表中新出现的适配器即为该插件注册的适配器
+	var metas []adapter.Metadata
+	for id, a := range adapter.GetAll() {
+		if _, existed := before[id]; !existed {
+			metas = append(metas, a.Metadata())
+		}
+	}
+	if len(metas) == 0 {
+		return nil, fmt.Errorf("plugin exports neither Adapters nor Metadata and registers no adapters")
+	}
+	sort.Slice(metas, func(i, j int) bool { return metas[i].Id < metas[j].Id })
+	return metas, nil
+}
+
+// openPlugin 打开插件，并恢复插件中依赖包的 init 对全局日志所做的修改（如 slog.SetDefault），
+// 使之后的警告仍按 metagen 自己的格式输出
+func openPlugin(path string) (*plugin.Plugin, error) {
+	logger, writer, flags, prefix := slog.Default(), log.Writer(), log.Flags(), log.Prefix()
+	defer func() {
+		// slog.SetDefault 会接管 log 包的输出，因此先恢复 slog 再恢复 log
+		slog.SetDefault(logger)
+		log.SetOutput(writer)
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+	}()
+	return plugin.Open(path)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+	"github.com/meloshub/meloshub-tools/metadiff"
+)
+
+// hookCounts 通过环境变量传给 post-hook 的计数
+type hookCounts struct {
+	total, added, removed, updated int
+}
+
+// newHookCounts 统计本次生成的条目数，以及相对写入前的文件新增、移除与更新的适配器数
+func newHookCounts(before, after []catalog.Entry) hookCounts {
+	report := metadiff.Compare(before, after, metadiff.Options{})
+	return hookCounts{
+		total:   len(after),
+		added:   len(report.Added),
+		removed: len(report.Removed),
+		updated: len(report.Updated),
+	}
+}
+
+// runPostHook 通过 shell 执行 command，并将输出文件路径作为最后一个参数传入
+// 命令的标准输出与标准错误合并后逐行写入日志，非零退出状态作为错误返回
+func runPostHook(command, outputPath, format string, counts hookCounts) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command, outputPath)
+	} else {
+		// "$@" 展开为 sh -c 之后的参数（第一个参数成为 $0），使路径中的空格与特殊字符不被 shell 解释
+		cmd = exec.Command("sh", "-c", command+` "$@"`, "metagen-post-hook", outputPath)
+	}
+	cmd.Env = append(os.Environ(),
+		"METAGEN_HOOK_OUTPUT="+outputPath,
+		"METAGEN_HOOK_FORMAT="+format,
+		"METAGEN_HOOK_COUNT="+strconv.Itoa(counts.total),
+		"METAGEN_HOOK_ADDED="+strconv.Itoa(counts.added),
+		"METAGEN_HOOK_REMOVED="+strconv.Itoa(counts.removed),
+		"METAGEN_HOOK_UPDATED="+strconv.Itoa(counts.updated),
+	)
+
+	output, err := cmd.CombinedOutput()
+	// 失败时的输出用于排查原因，即使指定了 --quiet-success 也要输出
+	logger := infoLog
+	if err != nil {
+		logger = log.Default()
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		logger.Printf("Post-hook: %s", scanner.Text())
+	}
+	if err != nil {
+		return fmt.Errorf("post-hook %q: %w", command, err)
+	}
+	return nil
+}
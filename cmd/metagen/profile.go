@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/meloshub/meloshub-tools/internal/atomicfile"
+	"github.com/meloshub/meloshub-tools/metascan"
+)
+
+// scanProfile 收集 --profile 所需的各包扫描耗时
+type scanProfile struct {
+	timings []metascan.PackageTiming
+	// load packages.Load 加载与类型检查的耗时
+	load time.Duration
+}
+
+func (p *scanProfile) add(timing metascan.PackageTiming) {
+	p.timings = append(p.timings, timing)
+}
+
+func (p *scanProfile) setLoad(d time.Duration) {
+	p.load = d
+}
+
+// sortSlowest 将耗时按从长到短排序，耗时相同时按包路径排序
+func (p *scanProfile) sortSlowest() {
+	sort.SliceStable(p.timings, func(i, j int) bool {
+		if p.timings[i].Duration != p.timings[j].Duration {
+			return p.timings[i].Duration > p.timings[j].Duration
+		}
+		return p.timings[i].PkgPath < p.timings[j].PkgPath
+	})
+}
+
+// logSlowest 输出最慢的 n 个包
+// 并行扫描时各包的耗时相互重叠，其总和可能超过扫描的总耗时，因此加载与类型检查的耗时单独计时，而不是由总耗时减去分析耗时得出
+func (p *scanProfile) logSlowest(n int, total time.Duration) {
+	p.sortSlowest()
+	var analysis time.Duration
+	for _, t := range p.timings {
+		analysis += t.Duration
+	}
+	log.Printf("Profile: scan took %s, %s loading and type-checking, %s analysing %d packages (summed over packages).",
+		total.Round(time.Millisecond), p.load.Round(time.Millisecond), analysis.Round(time.Microsecond), len(p.timings))
+	for i, t := range p.timings {
+		if i == n {
+			break
+		}
+		log.Printf("Profile: %2d. %s: %s (%d files, %d adapters)", i+1, t.PkgPath, t.Duration.Round(time.Microsecond), t.Files, t.Adapters)
+	}
+}
+
+// writeCSV 将所有包的耗时写为 CSV，按耗时从长到短排列
+func (p *scanProfile) writeCSV(path string, perm os.FileMode) error {
+	p.sortSlowest()
+	return atomicfile.Write(path, perm, func(f io.Writer) error {
+		w := csv.NewWriter(f)
+		if err := w.Write([]string{"package", "files", "adapters", "duration_ms"}); err != nil {
+			return err
+		}
+		for _, t := range p.timings {
+			row := []string{
+				t.PkgPath,
+				strconv.Itoa(t.Files),
+				strconv.Itoa(t.Adapters),
+				strconv.FormatFloat(float64(t.Duration)/float64(time.Millisecond), 'f', 3, 64),
+			}
+			if err := w.Write(row); err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		return w.Error()
+	})
+}
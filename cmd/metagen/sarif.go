@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+	"github.com/meloshub/meloshub-tools/diagnostics"
+	"github.com/meloshub/meloshub-tools/internal/atomicfile"
+)
+
+// SARIF 2.1.0 报告中用到的最小结构子集
+type sarifReport struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationUri string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	Id               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleId    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	Uri string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// writeSarif 将诊断中的校验结果（带有规则的条目）写为 SARIF 2.1.0 报告，文件路径相对于 rootDir
+func writeSarif(path, rootDir string, entries []diagnostics.Entry, perm os.FileMode) error {
+	var ruleIds []string
+	for id := range catalog.RuleDescriptions {
+		ruleIds = append(ruleIds, id)
+	}
+	sort.Strings(ruleIds)
+
+	driver := sarifDriver{
+		Name:           "metagen",
+		InformationUri: "https://github.com/meloshub/meloshub-tools",
+	}
+	for _, id := range ruleIds {
+		driver.Rules = append(driver.Rules, sarifRule{Id: id, ShortDescription: sarifMessage{Text: catalog.RuleDescriptions[id]}})
+	}
+
+	results := []sarifResult{}
+	for _, e := range entries {
+		if e.Rule == "" {
+			continue
+		}
+		result := sarifResult{
+			RuleId:  e.Rule,
+			Level:   e.Severity,
+			Message: sarifMessage{Text: e.Message},
+		}
+		if e.File != "" {
+			uri := e.File
+			if rel, err := filepath.Rel(rootDir, e.File); err == nil {
+				uri = rel
+			}
+			result.Locations = []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{Uri: filepath.ToSlash(uri)},
+					Region:           sarifRegion{StartLine: e.Line, StartColumn: e.Column},
+				},
+			}}
+		}
+		results = append(results, result)
+	}
+
+	report := sarifReport{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not marshal SARIF report: %w", err)
+	}
+	return atomicfile.WriteFile(path, data, perm)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"hash/fnv"
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+	"github.com/meloshub/meloshub-tools/diagnostics"
+)
+
+// servePollInterval 检查源码是否变化的间隔
+const servePollInterval = 2 * time.Second
+
+// adapterCache 保存最近一次成功扫描得到的适配器
+type adapterCache struct {
+	// diags 报告扫描失败等警告，服务长期运行，通常为只写日志的 nil 收集器
+	diags *diagnostics.Diagnostics
+
+	mu      sync.RWMutex
+	scanned bool
+	entries []catalog.Entry
+	byId    map[string]catalog.Entry
+}
+
+// refresh 重新扫描并替换缓存；扫描失败时保留上一次的结果
+func (c *adapterCache) refresh(scan func() ([]catalog.Entry, error)) {
+	entries, err := scan()
+	if err != nil {
+		c.diags.Warnf("", token.Position{}, "scan failed, keeping the previous results: %v", err)
+		return
+	}
+
+	byId := make(map[string]catalog.Entry, len(entries))
+	for _, e := range entries {
+		byId[e.Id] = e
+	}
+
+	c.mu.Lock()
+	c.scanned = true
+	c.entries = entries
+	c.byId = byId
+	c.mu.Unlock()
+	infoLog.Printf("Cached metadata for %d adapters.", len(entries))
+}
+
+func (c *adapterCache) handleList(w http.ResponseWriter, r *http.Request) {
+	c.mu.RLock()
+	scanned, entries := c.scanned, c.entries
+	c.mu.RUnlock()
+
+	if !scanned {
+		http.Error(w, "no successful scan yet", http.StatusServiceUnavailable)
+		return
+	}
+	if entries == nil {
+		entries = []catalog.Entry{}
+	}
+	c.writeJSON(w, entries)
+}
+
+func (c *adapterCache) handleGet(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	c.mu.RLock()
+	scanned := c.scanned
+	entry, ok := c.byId[id]
+	c.mu.RUnlock()
+
+	if !scanned {
+		http.Error(w, "no successful scan yet", http.StatusServiceUnavailable)
+		return
+	}
+	if !ok {
+		http.Error(w, fmt.Sprintf("adapter %q not found", id), http.StatusNotFound)
+		return
+	}
+	c.writeJSON(w, entry)
+}
+
+// writeJSON 以 JSON 写出响应，与 ndjson 输出一样保留作者字段中的 <>
+func (c *adapterCache) writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		c.diags.Warnf("", token.Position{}, "could not write response: %v", err)
+	}
+}
+
+// serve 在 addr 上提供适配器元数据的 HTTP 服务，警告报告给 diags
+// 启动时先扫描一次，之后每隔 servePollInterval 检查 rootDir 下的源码，发生变化时重新扫描
+func serve(addr, rootDir string, scan func() ([]catalog.Entry, error), diags *diagnostics.Diagnostics) error {
+	cache := &adapterCache{diags: diags}
+	cache.refresh(scan)
+
+	go func() {
+		last, err := sourceFingerprint(rootDir)
+		if err != nil {
+			diags.Warnf("", token.Position{}, "could not watch %s for changes: %v", rootDir, err)
+		}
+		for range time.Tick(servePollInterval) {
+			current, err := sourceFingerprint(rootDir)
+			if err != nil {
+				diags.Warnf("", token.Position{}, "could not watch %s for changes: %v", rootDir, err)
+				continue
+			}
+			if current != last {
+				infoLog.Printf("Source change detected, rescanning %s", rootDir)
+				last = current
+				cache.refresh(scan)
+			}
+		}
+	}()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /adapters", cache.handleList)
+	mux.HandleFunc("GET /adapters/{id}", cache.handleGet)
+	infoLog.Printf("Serving adapter metadata on http://%s/adapters", addr)
+	return http.ListenAndServe(addr, mux)
+}
+
+// sourceFingerprint 根据 rootDir 下所有 Go 源文件与 go.mod、go.sum 的路径、大小和修改时间计算指纹
+// 与 go 命令的 ./... 一样跳过以 . 或 _ 开头的目录以及 testdata 目录
+func sourceFingerprint(rootDir string) (uint64, error) {
+	h := fnv.New64a()
+	err := filepath.WalkDir(rootDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if path != rootDir && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, ".go") && name != "go.mod" && name != "go.sum" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00%d\n", path, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	return h.Sum64(), err
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/meloshub/meloshub-tools/internal/atomicfile"
+	"github.com/meloshub/meloshub-tools/metascan"
+)
+
+// sourceHashRecord --include-source-hash 输出中单个适配器的记录
+type sourceHashRecord struct {
+	// File 元数据字面量所在的文件，相对于扫描目录
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	SHA256 string `json:"sha256"`
+}
+
+// writeSourceHashes 将每个适配器的源码哈希写为以 Id 为键的 JSON 对象，返回写入的适配器数量
+// 没有源码的适配器（如来自 --plugin）不会出现在结果中
+func writeSourceHashes(path, rootDir string, scanned []metascan.Result, perm os.FileMode) (int, error) {
+	records := make(map[string]sourceHashRecord)
+	for _, result := range scanned {
+		if result.SourceHash == "" {
+			continue
+		}
+		file := result.Pos.Filename
+		if rel, err := filepath.Rel(rootDir, file); err == nil {
+			file = rel
+		}
+		records[result.Metadata.Id] = sourceHashRecord{File: filepath.ToSlash(file), Line: result.Pos.Line, SHA256: result.SourceHash}
+	}
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	return len(records), atomicfile.WriteFile(path, append(data, '\n'), perm)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/meloshub/meloshub-tools/diagnostics"
+	"github.com/meloshub/meloshub-tools/internal/atomicfile"
+)
+
+// warningRecord --warnings 输出中的单条诊断
+type warningRecord struct {
+	Id       string `json:"id,omitempty"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Message  string `json:"message"`
+	Severity string `json:"severity"`
+}
+
+// warningFailure 供 --fail-on-warning 使用，记录过任何警告时返回汇总数量的错误
+func warningFailure(diags *diagnostics.Diagnostics) error {
+	if n := diags.Count(diagnostics.SeverityWarning); n > 0 {
+		return fmt.Errorf("%d warnings were emitted", n)
+	}
+	return nil
+}
+
+// writeWarnings 将所有诊断写为 JSON 数组，校验结果的规则附在消息之后，与日志中的形式相同；
+// 没有诊断时写入空数组
+func writeWarnings(path string, entries []diagnostics.Entry, perm os.FileMode) error {
+	records := make([]warningRecord, 0, len(entries))
+	for _, e := range entries {
+		records = append(records, warningRecord{
+			Id:       e.Id,
+			File:     e.File,
+			Line:     e.Line,
+			Message:  e.String(),
+			Severity: e.Severity,
+		})
+	}
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(path, append(data, '\n'), perm)
+}
@@ -0,0 +1,104 @@
+// Package diagnostics 收集扫描与校验过程中产生的警告和错误，供日志、--warnings、SARIF 等输出共用
+package diagnostics
+
+import (
+	"fmt"
+	"go/token"
+	"log"
+	"sync"
+)
+
+// 诊断的严重程度
+const (
+	SeverityWarning = "warning"
+	SeverityError   = "error"
+)
+
+// Entry 一条诊断
+type Entry struct {
+	Severity string
+	// Id 相关适配器的 Id，与具体适配器无关时为空
+	Id string
+	// File、Line、Column 相关的源码位置，未知时为零值
+	File   string
+	Line   int
+	Column int
+	// Rule 产生该诊断的校验规则，如 description-style；不是校验结果时为空
+	Rule    string
+	Message string
+}
+
+// String 返回写入日志的形式，带有规则时附在消息之后
+func (e Entry) String() string {
+	if e.Rule != "" {
+		return fmt.Sprintf("%s [%s]", e.Message, e.Rule)
+	}
+	return e.Message
+}
+
+// Diagnostics 并发安全的诊断收集器，零值即可使用
+// 每条诊断在记录的同时写入日志；nil 收集器只写日志、不记录，供不需要汇总输出的场景（如 --serve）使用
+type Diagnostics struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// Report 将诊断写入日志并记录下来
+func (d *Diagnostics) Report(e Entry) {
+	prefix := "Warning"
+	if e.Severity == SeverityError {
+		prefix = "Error"
+	}
+	if d == nil {
+		log.Printf("%s: %s", prefix, e)
+		return
+	}
+	// 在锁内写日志，使日志顺序与记录顺序一致
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	log.Printf("%s: %s", prefix, e)
+	d.entries = append(d.entries, e)
+}
+
+// Warnf 报告一条位于 pos 的警告，pos 可以为零值
+func (d *Diagnostics) Warnf(id string, pos token.Position, format string, args ...any) {
+	d.Report(newEntry(SeverityWarning, id, pos, fmt.Sprintf(format, args...)))
+}
+
+// Errorf 报告一条位于 pos 的错误，pos 可以为零值
+func (d *Diagnostics) Errorf(id string, pos token.Position, format string, args ...any) {
+	d.Report(newEntry(SeverityError, id, pos, fmt.Sprintf(format, args...)))
+}
+
+// Entries 按报告顺序返回所有诊断的副本
+func (d *Diagnostics) Entries() []Entry {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]Entry(nil), d.entries...)
+}
+
+// Count 返回指定严重程度的诊断数量
+func (d *Diagnostics) Count(severity string) int {
+	n := 0
+	for _, e := range d.Entries() {
+		if e.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// newEntry 根据源码位置创建诊断
+func newEntry(severity, id string, pos token.Position, message string) Entry {
+	return Entry{
+		Severity: severity,
+		Id:       id,
+		File:     pos.Filename,
+		Line:     pos.Line,
+		Column:   pos.Column,
+		Message:  message,
+	}
+}
@@ -4,7 +4,9 @@ go 1.24.2
 
 require (
 	github.com/meloshub/meloshub v0.2.0
+	golang.org/x/text v0.29.0
 	golang.org/x/tools v0.37.0
+	google.golang.org/protobuf v1.36.4
 	gopkg.in/yaml.v3 v3.0.1
 )
 
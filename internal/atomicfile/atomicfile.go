@@ -0,0 +1,50 @@
+// Package atomicfile 提供原子化的文件写入
+package atomicfile
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// WriteFile 将数据写入同目录下的临时文件，完整写入后再重命名到目标路径
+// 写入过程中被中断时目标文件保持原样，不会出现被截断的内容；出错时临时文件会被删除
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	return Write(path, perm, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// Write 与 WriteFile 相同，但由 write 回调以流的方式写入内容
+func Write(path string, perm os.FileMode, write func(w io.Writer) error) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("could not create temp file for %s: %w", path, err)
+	}
+	tmpName := tmp.Name()
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmpName)
+		}
+	}()
+
+	if err = write(tmp); err != nil {
+		return fmt.Errorf("could not write temp file %s: %w", tmpName, err)
+	}
+	if err = tmp.Sync(); err != nil {
+		return fmt.Errorf("could not sync temp file %s: %w", tmpName, err)
+	}
+	if err = tmp.Chmod(perm); err != nil {
+		return fmt.Errorf("could not set mode of temp file %s: %w", tmpName, err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("could not close temp file %s: %w", tmpName, err)
+	}
+	if err = os.Rename(tmpName, path); err != nil {
+		return fmt.Errorf("could not rename %s to %s: %w", tmpName, path, err)
+	}
+	return nil
+}
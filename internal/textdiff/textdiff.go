@@ -0,0 +1,169 @@
+// Package textdiff 生成两段文本之间按行比较的统一格式差异
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Unified 以统一格式输出从 oldData 到 newData 的差异，每个变动块前后保留 context 行上下文
+// 内容相同时返回空字符串
+func Unified(oldName string, oldData []byte, newName string, newData []byte, context int) string {
+	return unifiedDiff(oldName, splitLines(oldData), newName, splitLines(newData), context)
+}
+
+// splitLines 按行拆分文本，不保留换行符
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+// diffOp 编辑脚本中的一行，kind 为 ' '、'-' 或 '+'
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// diffLines 使用 Myers 算法计算从 a 到 b 的最短编辑脚本
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	limit := n + m
+	offset := limit + 1
+	v := make([]int, 2*limit+2)
+	// trace[d] 记录第 d 轮开始前的 v，用于回溯编辑路径
+	var trace [][]int
+
+search:
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	var ops []diffOp
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, diffOp{' ', a[x]})
+		}
+		if d == 0 {
+			break
+		}
+		if x == prevX {
+			y--
+			ops = append(ops, diffOp{'+', b[y]})
+		} else {
+			x--
+			ops = append(ops, diffOp{'-', a[x]})
+		}
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+// unifiedDiff 以统一格式输出差异，每个变动块前后保留 context 行上下文
+func unifiedDiff(oldName string, a []string, newName string, b []string, context int) string {
+	ops := diffLines(a, b)
+
+	var out strings.Builder
+	// oldLine 与 newLine 为 ops[i] 之前两侧已经消耗的行数
+	oldLine, newLine := 0, 0
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			oldLine++
+			newLine++
+			i++
+			continue
+		}
+
+		// 向前扩展上下文，并向后合并间隔不超过 2*context 行的变动
+		start := max(i-context, 0)
+		for start < i && ops[start].kind != ' ' {
+			start++
+		}
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == ' ' {
+				run++
+			}
+			if run == len(ops) || run-end > 2*context {
+				end = min(end+context, len(ops))
+				break
+			}
+			end = run
+		}
+
+		hunkOld, hunkNew := oldLine-(i-start), newLine-(i-start)
+		var oldCount, newCount int
+		var body strings.Builder
+		for _, op := range ops[start:end] {
+			body.WriteByte(op.kind)
+			body.WriteString(op.line)
+			body.WriteByte('\n')
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(hunkOld, oldCount), hunkRange(hunkNew, newCount))
+		out.WriteString(body.String())
+
+		oldLine, newLine = hunkOld+oldCount, hunkNew+newCount
+		i = end
+	}
+	return out.String()
+}
+
+// hunkRange 格式化变动块头部的行号范围；空范围的起始行号为其前一行
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
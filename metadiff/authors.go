@@ -0,0 +1,105 @@
+package metadiff
+
+import (
+	"sort"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+)
+
+// AuthorStat 单个作者在报告中的变动数量
+type AuthorStat struct {
+	// Author 规范化后的作者，形如 "Name <email>"；没有作者的适配器计入空字符串
+	Author  string `json:"author"`
+	Added   int    `json:"added"`
+	Updated int    `json:"updated"`
+	Removed int    `json:"removed"`
+	Total   int    `json:"total"`
+}
+
+// AuthorStats 按作者统计报告中新增、更新与移除的适配器数量，按总数从多到少排序，总数相同时按作者排序
+// 拥有多位作者的适配器计入每位作者。新增与更新按变动后的作者统计，移除按变动前的作者统计；
+// 重命名、移动与类型变动计为更新，预期内的移除计为移除。VersionBumps 只记录 Id，
+// 其作者从 newList 中按 Id 查找
+func (r *ChangeReport) AuthorStats(newList []catalog.Entry) []AuthorStat {
+	stats := make(map[string]*AuthorStat)
+	count := func(rawAuthor string, field func(*AuthorStat) *int) {
+		for _, author := range AuthorKeys(rawAuthor) {
+			stat, ok := stats[author]
+			if !ok {
+				stat = &AuthorStat{Author: author}
+				stats[author] = stat
+			}
+			*field(stat)++
+			stat.Total++
+		}
+	}
+	added := func(s *AuthorStat) *int { return &s.Added }
+	updated := func(s *AuthorStat) *int { return &s.Updated }
+	removed := func(s *AuthorStat) *int { return &s.Removed }
+
+	for _, e := range r.Added {
+		count(e.Author, added)
+	}
+	for _, e := range r.Removed {
+		count(e.Author, removed)
+	}
+	for _, e := range r.ExpectedRemovals {
+		count(e.Author, removed)
+	}
+	for _, u := range r.Updated {
+		count(u.After.Author, updated)
+	}
+	for _, u := range r.exclusiveTypes() {
+		count(u.After.Author, updated)
+	}
+	for _, u := range r.OwnershipOnly() {
+		count(u.After.Author, updated)
+	}
+	for _, u := range r.Renamed {
+		count(u.After.Author, updated)
+	}
+	for _, m := range r.Moved {
+		count(m.Entry.Author, updated)
+	}
+	authorById := make(map[string]string, len(newList))
+	for _, e := range newList {
+		authorById[e.Id] = e.Author
+	}
+	for _, bump := range r.VersionBumps {
+		count(authorById[bump.Id], updated)
+	}
+
+	list := make([]AuthorStat, 0, len(stats))
+	for _, stat := range stats {
+		list = append(list, *stat)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Total != list[j].Total {
+			return list[i].Total > list[j].Total
+		}
+		return list[i].Author < list[j].Author
+	})
+	return list
+}
+
+// AuthorKeys 将作者字段拆分为每位作者的规范化形式，同一字段中重复的作者只计一次
+// 无法解析的字段整体作为一位作者
+func AuthorKeys(raw string) []string {
+	authors, err := catalog.ParseAuthors(raw)
+	if err != nil {
+		return []string{catalog.NormalizeAuthor(raw)}
+	}
+	if len(authors) == 0 {
+		return []string{""}
+	}
+	var keys []string
+	seen := make(map[string]bool)
+	for _, author := range authors {
+		key := author.String()
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,41 @@
+package metadiff
+
+import (
+	"sort"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+)
+
+// CapabilityChanges 两个条目之间功能开关的变动，各列表均按名称排序
+type CapabilityChanges struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+	// Flipped 两侧均声明但取值不同的功能
+	Flipped []string `json:"flipped,omitempty"`
+}
+
+// CompareCapabilities 逐键比较两个条目的功能开关，没有变动时返回 nil
+func CompareCapabilities(before, after catalog.Entry) *CapabilityChanges {
+	var changes CapabilityChanges
+	for name, value := range after.Capabilities {
+		old, exists := before.Capabilities[name]
+		if !exists {
+			changes.Added = append(changes.Added, name)
+		} else if old != value {
+			changes.Flipped = append(changes.Flipped, name)
+		}
+	}
+	for name := range before.Capabilities {
+		if _, exists := after.Capabilities[name]; !exists {
+			changes.Removed = append(changes.Removed, name)
+		}
+	}
+
+	if len(changes.Added) == 0 && len(changes.Removed) == 0 && len(changes.Flipped) == 0 {
+		return nil
+	}
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Removed)
+	sort.Strings(changes.Flipped)
+	return &changes
+}
@@ -0,0 +1,31 @@
+package metadiff
+
+import "github.com/meloshub/meloshub-tools/catalog"
+
+// 元数据文件的两种形式，用于 CompareEnvelopes 报告形式的变化
+const (
+	shapeList     = "list"
+	shapeEnvelope = "envelope"
+)
+
+// shape 返回头部对应的文件形式
+func shape(meta *catalog.EnvelopeMeta) string {
+	if meta == nil {
+		return shapeList
+	}
+	return shapeEnvelope
+}
+
+// CompareEnvelopes 比较新旧文件的信封头部，meta 为 nil 表示该文件是平铺列表
+// 只报告不由适配器列表决定的字段：文件形式改变时只报告形式，两侧均为信封时比较 generator；
+// count 与 checksum 随适配器变动而变化，不单独列出
+func CompareEnvelopes(before, after *catalog.EnvelopeMeta) []FieldChange {
+	if (before == nil) != (after == nil) {
+		return []FieldChange{{Field: "format", Before: shape(before), After: shape(after)}}
+	}
+	var changes []FieldChange
+	if before != nil && before.Generator != after.Generator {
+		changes = append(changes, FieldChange{Field: "generator", Before: before.Generator, After: after.Generator})
+	}
+	return changes
+}
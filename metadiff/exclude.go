@@ -0,0 +1,50 @@
+package metadiff
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+)
+
+// ParsePatterns 解析逗号分隔的 Id 或通配符列表（可以来自多次指定的参数），并校验通配符语法
+func ParsePatterns(values []string) ([]string, error) {
+	var patterns []string
+	for _, value := range values {
+		for _, p := range strings.Split(value, ",") {
+			if p = strings.TrimSpace(p); p == "" {
+				continue
+			}
+			if _, err := path.Match(p, ""); err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+			}
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns, nil
+}
+
+// matchesAny 判断 id 是否匹配任一模式
+func matchesAny(id string, patterns []string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, id); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Exclude 移除 Id 匹配任一模式的条目
+func Exclude(entries []catalog.Entry, patterns []string) []catalog.Entry {
+	if len(patterns) == 0 {
+		return entries
+	}
+	kept := make([]catalog.Entry, 0, len(entries))
+	for _, e := range entries {
+		if !matchesAny(e.Id, patterns) {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
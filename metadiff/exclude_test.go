@@ -0,0 +1,36 @@
+package metadiff
+
+import (
+	"testing"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+	"github.com/meloshub/meloshub/adapter"
+)
+
+func TestExclude(t *testing.T) {
+	patterns, err := ParsePatterns([]string{"legacy-*, spotify", "", "x?"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"legacy-*", "spotify", "x?"}; len(patterns) != len(want) || patterns[0] != want[0] || patterns[1] != want[1] || patterns[2] != want[2] {
+		t.Fatalf("patterns = %q, want %q", patterns, want)
+	}
+
+	var entries []catalog.Entry
+	for _, id := range []string{"legacy-tidal", "spotify", "spotify-v2", "xy", "deezer"} {
+		entries = append(entries, catalog.Entry{Metadata: adapter.Metadata{Id: id}})
+	}
+	var kept []string
+	for _, e := range Exclude(entries, patterns) {
+		kept = append(kept, e.Id)
+	}
+	if len(kept) != 2 || kept[0] != "spotify-v2" || kept[1] != "deezer" {
+		t.Errorf("kept %v, want [spotify-v2 deezer]", kept)
+	}
+}
+
+func TestParsePatternsInvalid(t *testing.T) {
+	if _, err := ParsePatterns([]string{"a,[b"}); err == nil {
+		t.Error("ParsePatterns accepted a malformed glob")
+	}
+}
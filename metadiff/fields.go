@@ -0,0 +1,42 @@
+package metadiff
+
+import "github.com/meloshub/meloshub-tools/catalog"
+
+// FieldChange 单个字段的变动
+type FieldChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// FieldComparison 单个字段在两个条目之间的比较结果
+type FieldComparison struct {
+	FieldChange
+	Equal bool
+}
+
+// CompareFields 逐个比较两个条目的元数据字段，包括未变化的字段，作者字段按规范化形式比较
+func CompareFields(before, after catalog.Entry) []FieldComparison {
+	field := func(name, b, a string, equal bool) FieldComparison {
+		return FieldComparison{FieldChange: FieldChange{Field: name, Before: b, After: a}, Equal: equal}
+	}
+	return []FieldComparison{
+		field("id", before.Id, after.Id, before.Id == after.Id),
+		field("title", before.Title, after.Title, before.Title == after.Title),
+		field("type", string(before.Type), string(after.Type), before.Type == after.Type),
+		field("version", before.Version, after.Version, before.Version == after.Version),
+		field("author", before.Author, after.Author, catalog.NormalizeAuthor(before.Author) == catalog.NormalizeAuthor(after.Author)),
+		field("description", before.Description, after.Description, before.Description == after.Description),
+	}
+}
+
+// ChangedFields 返回两个条目之间发生变化的字段，作者字段按规范化形式比较
+func ChangedFields(before, after catalog.Entry) []FieldChange {
+	var changes []FieldChange
+	for _, f := range CompareFields(before, after) {
+		if !f.Equal {
+			changes = append(changes, f.FieldChange)
+		}
+	}
+	return changes
+}
@@ -0,0 +1,93 @@
+package metadiff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+)
+
+// Key 用于判断新旧两侧的条目是否为同一适配器的字段组合，nil 表示按 Id 匹配
+type Key []string
+
+// keyFields 可以作为匹配键的字段及其取值方式
+var keyFields = map[string]func(catalog.Entry) string{
+	"id":          func(e catalog.Entry) string { return e.Id },
+	"title":       func(e catalog.Entry) string { return e.Title },
+	"type":        func(e catalog.Entry) string { return string(e.Type) },
+	"version":     func(e catalog.Entry) string { return e.Version },
+	"author":      func(e catalog.Entry) string { return catalog.NormalizeAuthor(e.Author) },
+	"description": func(e catalog.Entry) string { return e.Description },
+}
+
+// ParseKey 解析逗号分隔的字段列表，如 "type,title"
+func ParseKey(spec string) (Key, error) {
+	var key Key
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field == "" {
+			continue
+		}
+		if _, ok := keyFields[field]; !ok {
+			return nil, fmt.Errorf("unknown key field %q", field)
+		}
+		if !seen[field] {
+			key = append(key, field)
+			seen[field] = true
+		}
+	}
+	if len(key) == 0 {
+		return nil, fmt.Errorf("empty key")
+	}
+	return key, nil
+}
+
+// Of 返回条目在该键下的值，各字段以 NUL 分隔以免相互混淆
+func (k Key) Of(e catalog.Entry) string {
+	if len(k) == 0 {
+		return e.Id
+	}
+	values := make([]string, len(k))
+	for i, field := range k {
+		values[i] = keyFields[field](e)
+	}
+	return strings.Join(values, "\x00")
+}
+
+// Describe 返回条目在该键下便于阅读的表示，如 type="official", title="Spotify"
+func (k Key) Describe(e catalog.Entry) string {
+	if len(k) == 0 {
+		return e.Id
+	}
+	parts := make([]string, len(k))
+	for i, field := range k {
+		parts[i] = fmt.Sprintf("%s=%q", field, keyFields[field](e))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// DuplicateKeys 返回列表中键值出现不止一次的条目描述，按首次重复出现的顺序排列
+func DuplicateKeys(list []catalog.Entry, key Key) []string {
+	seen := make(map[string]int)
+	var duplicates []string
+	for _, m := range list {
+		k := key.Of(m)
+		seen[k]++
+		if seen[k] == 2 {
+			duplicates = append(duplicates, key.Describe(m))
+		}
+	}
+	return duplicates
+}
+
+// CheckUniqueKeys 检查新旧两个列表中的键值各自唯一；重复的键无法确定应与哪个条目配对
+func CheckUniqueKeys(key Key, oldList, newList []catalog.Entry) error {
+	if duplicates := DuplicateKeys(oldList, key); len(duplicates) > 0 {
+		return fmt.Errorf("key is not unique in the old metadata: %s", strings.Join(duplicates, "; "))
+	}
+	if duplicates := DuplicateKeys(newList, key); len(duplicates) > 0 {
+		return fmt.Errorf("key is not unique in the new metadata: %s", strings.Join(duplicates, "; "))
+	}
+	return nil
+}
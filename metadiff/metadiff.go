@@ -0,0 +1,233 @@
+// Package metadiff 提供适配器元数据的比较逻辑，供 differ 与 metagen 共用
+package metadiff
+
+import (
+	"reflect"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+)
+
+type UpdateEntry struct {
+	Before catalog.Entry `json:"before"`
+	After  catalog.Entry `json:"after"`
+	// Capabilities 功能开关的逐项变动，没有变动时为空
+	Capabilities *CapabilityChanges `json:"capabilities,omitempty"`
+	// Titles 本地化标题的逐个语言变动，没有变动时为空
+	Titles *TitleChanges `json:"titles,omitempty"`
+}
+type ChangeReport struct {
+	// Envelope 文件头部的变动，由调用方通过 CompareEnvelopes 填充，Compare 本身不处理
+	Envelope []FieldChange `json:"envelope,omitempty"`
+
+	Added   []catalog.Entry `json:"added"`
+	Removed []catalog.Entry `json:"removed"`
+	Updated []UpdateEntry   `json:"updated"`
+	// OwnershipChanges 作者发生变化的适配器，可能意味着所有权转移
+	// 这些条目同时也会出现在 Updated 中，除非 Options.Precedence 将其归入了 VersionBumps 或 TypeChanges；
+	// 无论是否归类，作者变化的更新都会列在这里
+	OwnershipChanges []UpdateEntry `json:"ownership_changes"`
+	// Renamed 被识别为重命名的适配器，Before 与 After 的 Id 不同，仅在 Options.DetectRenames 时填充
+	// 这些条目不会出现在 Added 与 Removed 中
+	Renamed []UpdateEntry `json:"renamed,omitempty"`
+	// Moved 内容完全相同、只有 Id 改变的适配器，仅在 Options.DetectMoves 时填充
+	// 这些条目不会出现在 Added、Removed 与 Renamed 中
+	Moved []Move `json:"moved,omitempty"`
+	// ExpectedRemovals 已被确认将要移除的适配器，由 ApplyExpectedRemovals 从 Removed 中移出
+	ExpectedRemovals []catalog.Entry `json:"expected_removals,omitempty"`
+	// VersionBumps 只有版本号发生变化的适配器，仅在 Options.GroupVersionBumps 时填充，
+	// 或由 Options.Precedence 归入的更新；这些条目不会出现在 Updated 中
+	VersionBumps []VersionBump `json:"version_bumps,omitempty"`
+	// TypeChanges Type 发生变化的适配器，这些条目同时也会出现在 Updated 中，
+	// 除非 Options.Precedence 将其归入了其它分组；按 Type 归类的更新只出现在这里
+	TypeChanges []UpdateEntry `json:"type_changes,omitempty"`
+	// Unchanged 新旧文件中均存在且没有变化的适配器，仅在 Options.IncludeUnchanged 时填充
+	Unchanged []catalog.Entry `json:"unchanged,omitempty"`
+}
+
+// Options 控制比较行为
+type Options struct {
+	// IncludeUnchanged 在报告中附带未变化的适配器，使报告同时可作为完整清单使用
+	IncludeUnchanged bool
+	// GroupVersionBumps 将只有版本号变化的更新单独归入 VersionBumps，以缩短大版本发布时的报告
+	GroupVersionBumps bool
+	// Key 匹配新旧条目所用的字段组合，为空时按 Id 匹配
+	Key Key
+	// DetectRenames 将 Title、Type 与作者都相同的一对移除与新增条目识别为重命名
+	DetectRenames bool
+	// DetectMoves 将除 Id 外内容完全相同的一对移除与新增条目识别为移动，先于重命名识别
+	DetectMoves bool
+	// Precedence 字段优先级，由 ParsePrecedence 解析；非空时每个更新只归入其最高优先级的变动字段对应的分组
+	Precedence []string
+}
+
+// Compare 比较元数据变动
+func Compare(oldList, newList []catalog.Entry, opts Options) ChangeReport {
+	oldMap := make(map[string]catalog.Entry)
+	for _, m := range oldList {
+		oldMap[opts.Key.Of(m)] = m
+	}
+
+	newMap := make(map[string]catalog.Entry)
+	for _, m := range newList {
+		newMap[opts.Key.Of(m)] = m
+	}
+
+	report := ChangeReport{}
+
+	// 适配器新增与更新检查
+	for id, newMeta := range newMap {
+		oldMeta, exists := oldMap[id]
+		if !exists {
+			// 如果旧文件中不存在此ID，视为新增的适配器
+			report.Added = append(report.Added, newMeta)
+		} else if !equalEntries(oldMeta, newMeta) {
+			entry := UpdateEntry{
+				Before:       oldMeta,
+				After:        newMeta,
+				Capabilities: CompareCapabilities(oldMeta, newMeta),
+				Titles:       CompareTitles(oldMeta, newMeta),
+			}
+			if opts.GroupVersionBumps && isVersionOnly(entry) {
+				report.VersionBumps = append(report.VersionBumps, VersionBump{
+					Id:   newMeta.Id,
+					From: oldMeta.Version,
+					To:   newMeta.Version,
+					Kind: ClassifyBump(oldMeta.Version, newMeta.Version),
+				})
+				continue
+			}
+			report.Updated = append(report.Updated, entry)
+			if authorChanged(entry) {
+				report.OwnershipChanges = append(report.OwnershipChanges, entry)
+			}
+			if typeChanged(entry) {
+				report.TypeChanges = append(report.TypeChanges, entry)
+			}
+		} else if opts.IncludeUnchanged {
+			report.Unchanged = append(report.Unchanged, newMeta)
+		}
+	}
+
+	// 适配器移除检查
+	for id, oldMeta := range oldMap {
+		if _, exists := newMap[id]; !exists {
+			report.Removed = append(report.Removed, oldMeta)
+		}
+	}
+
+	if len(opts.Precedence) > 0 {
+		report.applyPrecedence(opts.Precedence)
+	}
+
+	// 移动的判定比重命名严格，先行配对的条目不会再被识别为重命名
+	if opts.DetectMoves {
+		report.detectMoves()
+	}
+	if opts.DetectRenames {
+		report.detectRenames()
+	}
+
+	// 遍历 map 的顺序是随机的，排序以保证报告的输出稳定
+	report.Sort(SortById)
+
+	return report
+}
+
+// DuplicateIds 返回列表中出现不止一次的 Id，按首次重复出现的顺序排列
+// Compare 按 Id 建立索引，重复的条目只会保留最后一个，调用方应在比较前检查
+func DuplicateIds(list []catalog.Entry) []string {
+	return DuplicateKeys(list, nil)
+}
+
+// isVersionOnly 判断更新是否只改变了版本号
+func isVersionOnly(u UpdateEntry) bool {
+	changes := ChangedFields(u.Before, u.After)
+	return u.Capabilities == nil && u.Titles == nil && len(changes) == 1 && changes[0].Field == "version"
+}
+
+// equalEntries 判断两个条目是否语义等价
+// 作者字段按规范化形式比较，派生字段不参与比较；其余字段按结构逐个比较，见 equalValues
+func equalEntries(a, b catalog.Entry) bool {
+	return equalValues(reflect.ValueOf(comparisonForm(a)), reflect.ValueOf(comparisonForm(b)))
+}
+
+// equalValues 递归比较两个同类型的值：结构体逐字段比较；map 按键比较，与书写顺序无关；
+// map 与切片的 nil 与空值等价（YAML 中省略与写成空值相同）。切片按元素顺序比较，因为列表的顺序可能有意义
+func equalValues(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Struct:
+		for i := range a.NumField() {
+			if !equalValues(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if a.Len() != b.Len() {
+			return false
+		}
+		iter := a.MapRange()
+		for iter.Next() {
+			other := b.MapIndex(iter.Key())
+			if !other.IsValid() || !equalValues(iter.Value(), other) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := range a.Len() {
+			if !equalValues(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Pointer, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return equalValues(a.Elem(), b.Elem())
+	default:
+		return a.Equal(b)
+	}
+}
+
+// comparisonForm 返回用于比较的条目副本
+func comparisonForm(e catalog.Entry) catalog.Entry {
+	e.Author = catalog.NormalizeAuthor(e.Author)
+	e.AuthorName = ""
+	e.AuthorEmail = ""
+	e.ShortDescription = ""
+	return e
+}
+
+// ApplyExpectedRemovals 将 Id 在 ids 中的移除条目从 Removed 移到 ExpectedRemovals，
+// 并返回列在 ids 中却没有被移除的 Id
+func (r *ChangeReport) ApplyExpectedRemovals(ids []string) []string {
+	expected := make(map[string]bool)
+	for _, id := range ids {
+		expected[id] = true
+	}
+
+	var removed []catalog.Entry
+	for _, e := range r.Removed {
+		if expected[e.Id] {
+			r.ExpectedRemovals = append(r.ExpectedRemovals, e)
+			delete(expected, e.Id)
+		} else {
+			removed = append(removed, e)
+		}
+	}
+	r.Removed = removed
+
+	var notRemoved []string
+	for _, id := range ids {
+		if expected[id] {
+			notRemoved = append(notRemoved, id)
+			delete(expected, id)
+		}
+	}
+	return notRemoved
+}
@@ -0,0 +1,127 @@
+package metadiff
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+)
+
+// readYAML 将 YAML 内容写入临时文件并按 differ 读取 --new 的方式解析
+func readYAML(t *testing.T, content string) []catalog.Entry {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "adapters.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := catalog.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+func TestDuplicateIdsInNewFile(t *testing.T) {
+	newList := readYAML(t, `- id: spotify
+  title: First
+- id: deezer
+  title: Deezer
+- id: spotify
+  title: Second
+- id: tidal
+  title: Tidal
+- id: spotify
+  title: Third
+- id: tidal
+  title: Tidal again
+`)
+	// 每个重复的 Id 只报告一次，按第一次重复出现的顺序
+	if got := DuplicateIds(newList); !equalStrings(got, []string{"spotify", "tidal"}) {
+		t.Errorf("DuplicateIds = %v, want [spotify tidal]", got)
+	}
+	if got := DuplicateIds(readYAML(t, "- id: a\n- id: b\n")); len(got) != 0 {
+		t.Errorf("DuplicateIds without duplicates = %v, want none", got)
+	}
+
+	// 比较时只使用最后一个条目
+	report := Compare(nil, newList, Options{})
+	for _, entry := range report.Added {
+		if entry.Id == "spotify" && entry.Title != "Third" {
+			t.Errorf("added spotify has Title %q, want the last entry's Third", entry.Title)
+		}
+	}
+	if len(report.Added) != 3 {
+		t.Errorf("Added %d adapters, want 3", len(report.Added))
+	}
+}
+
+func TestCompareIgnoresMapOrder(t *testing.T) {
+	oldList := readYAML(t, `- id: spotify
+  version: 1.0.0
+  capabilities:
+    search: true
+    lyrics: false
+    playlists: true
+  titles:
+    zh: 声田
+    en: Spotify
+`)
+	newList := readYAML(t, `- id: spotify
+  titles:
+    en: Spotify
+    zh: 声田
+  capabilities:
+    playlists: true
+    search: true
+    lyrics: false
+  version: 1.0.0
+`)
+	report := Compare(oldList, newList, Options{IncludeUnchanged: true})
+	if len(report.Updated) != 0 {
+		t.Errorf("reordered maps: updated = %+v, want none", report.Updated)
+	}
+	if len(report.Unchanged) != 1 {
+		t.Errorf("reordered maps: unchanged = %+v, want spotify", report.Unchanged)
+	}
+}
+
+func TestCompareNilAndEmptyMaps(t *testing.T) {
+	before := readYAML(t, "- id: spotify\n")
+	after := readYAML(t, "- id: spotify\n  capabilities: {}\n  titles: {}\n")
+	if report := Compare(before, after, Options{}); len(report.Updated) != 0 {
+		t.Errorf("omitted and empty maps: updated = %+v, want none", report.Updated)
+	}
+}
+
+func TestCompareReportsMapChanges(t *testing.T) {
+	before := readYAML(t, "- id: spotify\n  capabilities:\n    search: true\n    lyrics: false\n")
+	after := readYAML(t, "- id: spotify\n  capabilities:\n    lyrics: true\n    search: true\n")
+	report := Compare(before, after, Options{})
+	if len(report.Updated) != 1 {
+		t.Fatalf("updated = %+v, want spotify", report.Updated)
+	}
+	update := report.Updated[0]
+	if want := (&CapabilityChanges{Flipped: []string{"lyrics"}}); !reflect.DeepEqual(update.Capabilities, want) {
+		t.Errorf("capability changes = %+v, want %+v", update.Capabilities, want)
+	}
+	// 元数据字段本身没有变化
+	if changes := ChangedFields(update.Before, update.After); len(changes) != 0 {
+		t.Errorf("changed fields = %+v, want none", changes)
+	}
+}
+
+func TestEqualValuesSlicesKeepOrder(t *testing.T) {
+	type tagged struct{ Tags []string }
+	equal := func(a, b tagged) bool { return equalValues(reflect.ValueOf(a), reflect.ValueOf(b)) }
+	if !equal(tagged{}, tagged{Tags: []string{}}) {
+		t.Error("nil and empty slices compare unequal")
+	}
+	if !equal(tagged{Tags: []string{"a", "b"}}, tagged{Tags: []string{"a", "b"}}) {
+		t.Error("identical slices compare unequal")
+	}
+	if equal(tagged{Tags: []string{"a", "b"}}, tagged{Tags: []string{"b", "a"}}) {
+		t.Error("reordered slices compare equal, want order to matter")
+	}
+}
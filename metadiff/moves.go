@@ -0,0 +1,43 @@
+package metadiff
+
+import "github.com/meloshub/meloshub-tools/catalog"
+
+// Move 内容完全相同、只有 Id 改变的适配器
+type Move struct {
+	From  string        `json:"from"`
+	To    string        `json:"to"`
+	Entry catalog.Entry `json:"entry"`
+}
+
+// detectMoves 将内容哈希（catalog.ContentHash）相同的一对移除与新增条目视为移动，移入 Moved
+// 与 detectRenames 一样只配对双方都唯一的匹配
+func (r *ChangeReport) detectMoves() {
+	removedByHash := make(map[string][]int)
+	for i, e := range r.Removed {
+		if h := catalog.ContentHash(e); h != "" {
+			removedByHash[h] = append(removedByHash[h], i)
+		}
+	}
+	addedByHash := make(map[string][]int)
+	for i, e := range r.Added {
+		if h := catalog.ContentHash(e); h != "" {
+			addedByHash[h] = append(addedByHash[h], i)
+		}
+	}
+
+	movedRemoved := make(map[int]bool)
+	movedAdded := make(map[int]bool)
+	for h, removed := range removedByHash {
+		added := addedByHash[h]
+		if len(removed) != 1 || len(added) != 1 {
+			continue
+		}
+		after := r.Added[added[0]]
+		r.Moved = append(r.Moved, Move{From: r.Removed[removed[0]].Id, To: after.Id, Entry: after})
+		movedRemoved[removed[0]] = true
+		movedAdded[added[0]] = true
+	}
+
+	r.Removed = withoutIndexes(r.Removed, movedRemoved)
+	r.Added = withoutIndexes(r.Added, movedAdded)
+}
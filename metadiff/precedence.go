@@ -0,0 +1,142 @@
+package metadiff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+)
+
+// 可参与优先级归类的字段，名称与 ChangedFields 中的字段名一致
+const (
+	PrecedenceVersion = "version"
+	PrecedenceType    = "type"
+	PrecedenceAuthor  = "author"
+)
+
+// ParsePrecedence 解析逗号分隔的字段优先级列表，越靠前优先级越高；空字符串表示不使用优先级
+func ParsePrecedence(spec string) ([]string, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+	var fields []string
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.ToLower(strings.TrimSpace(field))
+		switch field {
+		case PrecedenceVersion, PrecedenceType, PrecedenceAuthor:
+		default:
+			return nil, fmt.Errorf("unknown precedence field %q (expected %s, %s or %s)", field, PrecedenceVersion, PrecedenceType, PrecedenceAuthor)
+		}
+		if seen[field] {
+			return nil, fmt.Errorf("precedence field %q is listed more than once", field)
+		}
+		seen[field] = true
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// applyPrecedence 按字段优先级将每个更新只归入一个分组
+// 更新由 precedence 中第一个发生变化的字段决定去向：version 归入 VersionBumps（VersionBump.Update 保留完整的更新），
+// type 只保留在 TypeChanges，author 只保留在 OwnershipChanges；被归类的更新不再出现在 Updated 与其它分组中，
+// 但作者发生变化的更新始终留在 OwnershipChanges，使所有权转移的警告与 --fail-on ownership 不会被归类掩盖。
+// 列出的字段都没有变化的更新保持默认行为
+func (r *ChangeReport) applyPrecedence(precedence []string) {
+	var updated, types, ownership []UpdateEntry
+	for _, u := range r.Updated {
+		switch claimingField(u, precedence) {
+		case PrecedenceVersion:
+			bump := updateBump(u)
+			bump.Update = &u
+			r.VersionBumps = append(r.VersionBumps, bump)
+			if authorChanged(u) {
+				ownership = append(ownership, u)
+			}
+		case PrecedenceType:
+			types = append(types, u)
+			if authorChanged(u) {
+				ownership = append(ownership, u)
+			}
+		case PrecedenceAuthor:
+			ownership = append(ownership, u)
+		default:
+			updated = append(updated, u)
+			if typeChanged(u) {
+				types = append(types, u)
+			}
+			if authorChanged(u) {
+				ownership = append(ownership, u)
+			}
+		}
+	}
+	r.Updated = updated
+	r.TypeChanges = types
+	r.OwnershipChanges = ownership
+}
+
+// claimingField 返回 precedence 中第一个在更新中发生变化的字段，没有时返回空字符串
+func claimingField(u UpdateEntry, precedence []string) string {
+	changed := make(map[string]bool)
+	for _, change := range ChangedFields(u.Before, u.After) {
+		changed[change.Field] = true
+	}
+	for _, field := range precedence {
+		if changed[field] {
+			return field
+		}
+	}
+	return ""
+}
+
+// authorChanged 判断更新是否按规范化形式改变了作者
+func authorChanged(u UpdateEntry) bool {
+	return catalog.NormalizeAuthor(u.Before.Author) != catalog.NormalizeAuthor(u.After.Author)
+}
+
+// typeChanged 判断更新是否改变了 Type
+func typeChanged(u UpdateEntry) bool {
+	return u.Before.Type != u.After.Type
+}
+
+// UpdatedCount 返回发生更新的适配器数量，包括被归入 VersionBumps
+// 或只出现在 TypeChanges、OwnershipChanges 中的更新，每个适配器只计一次
+func (r *ChangeReport) UpdatedCount() int {
+	return len(r.Updated) + len(r.VersionBumps) + len(r.exclusiveTypes()) + len(r.OwnershipOnly())
+}
+
+// exclusiveTypes 返回只出现在 TypeChanges 而不在 Updated 中的更新，即按 Type 归类的更新
+func (r *ChangeReport) exclusiveTypes() []UpdateEntry {
+	listed := make(map[string]bool)
+	for _, u := range r.Updated {
+		listed[u.After.Id] = true
+	}
+	return notListed(r.TypeChanges, listed)
+}
+
+// OwnershipOnly 返回只出现在 OwnershipChanges 中的更新，即按作者归类的更新；
+// 同时在 Updated、VersionBumps 或 TypeChanges 中的更新已经计入，不在结果中
+func (r *ChangeReport) OwnershipOnly() []UpdateEntry {
+	listed := make(map[string]bool)
+	for _, u := range r.Updated {
+		listed[u.After.Id] = true
+	}
+	for _, u := range r.TypeChanges {
+		listed[u.After.Id] = true
+	}
+	for _, bump := range r.VersionBumps {
+		listed[bump.Id] = true
+	}
+	return notListed(r.OwnershipChanges, listed)
+}
+
+// notListed 返回 updates 中 Id 不在 listed 里的更新
+func notListed(updates []UpdateEntry, listed map[string]bool) []UpdateEntry {
+	var kept []UpdateEntry
+	for _, u := range updates {
+		if !listed[u.After.Id] {
+			kept = append(kept, u)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,132 @@
+package metadiff
+
+import (
+	"testing"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+	"github.com/meloshub/meloshub/adapter"
+)
+
+func precedenceEntry(id, typ, version, author, description string) catalog.Entry {
+	return catalog.Entry{Metadata: adapter.Metadata{
+		Id:          id,
+		Title:       id,
+		Type:        adapter.AdapterType(typ),
+		Version:     version,
+		Author:      author,
+		Description: description,
+	}}
+}
+
+// precedenceFixture a 改变了版本与描述，b 改变了 Type、版本与作者，c 只改变了 Type
+func precedenceFixture() (before, after []catalog.Entry) {
+	before = []catalog.Entry{
+		precedenceEntry("a", "official", "1.0.0", "bob", "old"),
+		precedenceEntry("b", "official", "1.0.0", "bob", "same"),
+		precedenceEntry("c", "official", "1.0.0", "carol", "same"),
+	}
+	after = []catalog.Entry{
+		precedenceEntry("a", "official", "1.1.0", "bob", "new"),
+		precedenceEntry("b", "community", "2.0.0", "alice", "same"),
+		precedenceEntry("c", "community", "1.0.0", "carol", "same"),
+	}
+	return before, after
+}
+
+func updateIds(updates []UpdateEntry) []string {
+	ids := []string{}
+	for _, u := range updates {
+		ids = append(ids, u.After.Id)
+	}
+	return ids
+}
+
+func bumpIds(bumps []VersionBump) []string {
+	ids := []string{}
+	for _, b := range bumps {
+		ids = append(ids, b.Id)
+	}
+	return ids
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCompareDefaultListsTypeChanges(t *testing.T) {
+	before, after := precedenceFixture()
+	report := Compare(before, after, Options{})
+
+	if got := updateIds(report.Updated); !equalStrings(got, []string{"a", "b", "c"}) {
+		t.Errorf("Updated = %v, want [a b c]", got)
+	}
+	if got := updateIds(report.TypeChanges); !equalStrings(got, []string{"b", "c"}) {
+		t.Errorf("TypeChanges = %v, want [b c]", got)
+	}
+	if got := updateIds(report.OwnershipChanges); !equalStrings(got, []string{"b"}) {
+		t.Errorf("OwnershipChanges = %v, want [b]", got)
+	}
+	if got := report.UpdatedCount(); got != 3 {
+		t.Errorf("UpdatedCount() = %d, want 3", got)
+	}
+}
+
+func TestCompareVersionPrecedenceKeepsOtherChanges(t *testing.T) {
+	before, after := precedenceFixture()
+	report := Compare(before, after, Options{Precedence: []string{PrecedenceVersion}})
+
+	if got := updateIds(report.Updated); !equalStrings(got, []string{"c"}) {
+		t.Errorf("Updated = %v, want [c]", got)
+	}
+	if got := bumpIds(report.VersionBumps); !equalStrings(got, []string{"a", "b"}) {
+		t.Fatalf("VersionBumps = %v, want [a b]", got)
+	}
+	for _, bump := range report.VersionBumps {
+		if bump.Update == nil {
+			t.Fatalf("VersionBumps[%s].Update is nil, want the full update", bump.Id)
+		}
+	}
+	if fields := fieldNames(*report.VersionBumps[0].Update); !equalStrings(fields, []string{"version", "description"}) {
+		t.Errorf("a changed fields = %v, want [version description]", fields)
+	}
+	if fields := fieldNames(*report.VersionBumps[1].Update); !equalStrings(fields, []string{"type", "version", "author"}) {
+		t.Errorf("b changed fields = %v, want [type version author]", fields)
+	}
+	if got := updateIds(report.OwnershipChanges); !equalStrings(got, []string{"b"}) {
+		t.Errorf("OwnershipChanges = %v, want [b]", got)
+	}
+	if got := report.UpdatedCount(); got != 3 {
+		t.Errorf("UpdatedCount() = %d, want 3", got)
+	}
+}
+
+func TestCompareAuthorPrecedence(t *testing.T) {
+	before, after := precedenceFixture()
+	report := Compare(before, after, Options{Precedence: []string{PrecedenceAuthor}})
+
+	if got := updateIds(report.Updated); !equalStrings(got, []string{"a", "c"}) {
+		t.Errorf("Updated = %v, want [a c]", got)
+	}
+	if got := updateIds(report.OwnershipOnly()); !equalStrings(got, []string{"b"}) {
+		t.Errorf("OwnershipOnly() = %v, want [b]", got)
+	}
+	if got := report.UpdatedCount(); got != 3 {
+		t.Errorf("UpdatedCount() = %d, want 3", got)
+	}
+}
+
+func fieldNames(u UpdateEntry) []string {
+	var names []string
+	for _, change := range ChangedFields(u.Before, u.After) {
+		names = append(names, change.Field)
+	}
+	return names
+}
@@ -0,0 +1,71 @@
+package metadiff
+
+import "github.com/meloshub/meloshub-tools/catalog"
+
+// renameKey 判断重命名时比较的字段：Title、Type 与规范化后的作者
+type renameKey struct {
+	title, typ, author string
+}
+
+func renameKeyOf(e catalog.Entry) renameKey {
+	return renameKey{title: e.Title, typ: string(e.Type), author: catalog.NormalizeAuthor(e.Author)}
+}
+
+// detectRenames 将 Title、Type 与作者都相同的一对移除与新增条目视为重命名，移入 Renamed
+// 只有双方都是唯一匹配时才配对，存在歧义的条目保持原样
+func (r *ChangeReport) detectRenames() {
+	removedByKey := make(map[renameKey][]int)
+	for i, e := range r.Removed {
+		k := renameKeyOf(e)
+		removedByKey[k] = append(removedByKey[k], i)
+	}
+	addedByKey := make(map[renameKey][]int)
+	for i, e := range r.Added {
+		k := renameKeyOf(e)
+		addedByKey[k] = append(addedByKey[k], i)
+	}
+
+	renamedRemoved := make(map[int]bool)
+	renamedAdded := make(map[int]bool)
+	for k, removed := range removedByKey {
+		added := addedByKey[k]
+		if len(removed) != 1 || len(added) != 1 {
+			continue
+		}
+		before, after := r.Removed[removed[0]], r.Added[added[0]]
+		r.Renamed = append(r.Renamed, UpdateEntry{
+			Before:       before,
+			After:        after,
+			Capabilities: CompareCapabilities(before, after),
+			Titles:       CompareTitles(before, after),
+		})
+		renamedRemoved[removed[0]] = true
+		renamedAdded[added[0]] = true
+	}
+
+	r.Removed = withoutIndexes(r.Removed, renamedRemoved)
+	r.Added = withoutIndexes(r.Added, renamedAdded)
+}
+
+// withoutIndexes 返回去掉指定下标后的条目
+func withoutIndexes(entries []catalog.Entry, drop map[int]bool) []catalog.Entry {
+	var kept []catalog.Entry
+	for i, e := range entries {
+		if !drop[i] {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+// RenameMap 返回重命名与移动前后 Id 的对应关系，可直接供迁移脚本使用
+func (r *ChangeReport) RenameMap() map[string]string {
+	renames := make(map[string]string, len(r.Renamed)+len(r.Moved))
+	for _, u := range r.Renamed {
+		renames[u.Before.Id] = u.After.Id
+	}
+	for _, m := range r.Moved {
+		renames[m.From] = m.To
+	}
+	return renames
+}
@@ -0,0 +1,52 @@
+package metadiff
+
+import "github.com/meloshub/meloshub-tools/catalog"
+
+// Subtract 从报告中去掉与 prior 中完全相同的变动，只保留自上一份报告以来新出现的变动
+// 条目按 Id 与内容（与 Compare 相同的等价规则）匹配，更新需要变动前后都相同；
+// Unchanged 不受影响。prior 通常是上一次运行写出的 standard 或 full 报告
+func (r *ChangeReport) Subtract(prior ChangeReport) {
+	sameEntry := func(a, b catalog.Entry) bool {
+		return a.Id == b.Id && equalEntries(a, b)
+	}
+	sameUpdate := func(a, b UpdateEntry) bool {
+		return sameEntry(a.Before, b.Before) && sameEntry(a.After, b.After)
+	}
+
+	r.Envelope = subtract(r.Envelope, prior.Envelope, func(a, b FieldChange) bool { return a == b })
+	r.Added = subtract(r.Added, prior.Added, sameEntry)
+	r.Removed = subtract(r.Removed, prior.Removed, sameEntry)
+	r.ExpectedRemovals = subtract(r.ExpectedRemovals, prior.ExpectedRemovals, sameEntry)
+	r.Updated = subtract(r.Updated, prior.Updated, sameUpdate)
+	r.TypeChanges = subtract(r.TypeChanges, prior.TypeChanges, sameUpdate)
+	r.OwnershipChanges = subtract(r.OwnershipChanges, prior.OwnershipChanges, sameUpdate)
+	r.Renamed = subtract(r.Renamed, prior.Renamed, sameUpdate)
+	r.Moved = subtract(r.Moved, prior.Moved, func(a, b Move) bool {
+		return a.From == b.From && a.To == b.To && equalEntries(a.Entry, b.Entry)
+	})
+	r.VersionBumps = subtract(r.VersionBumps, prior.VersionBumps, func(a, b VersionBump) bool {
+		return a.Id == b.Id && a.From == b.From && a.To == b.To
+	})
+}
+
+// subtract 返回 list 中在 prior 里找不到相同元素的部分，保持原有顺序
+// list 不为 nil 时结果也不为 nil，使 JSON 中必有的分组在全部被去掉后仍输出为 []
+func subtract[T any](list, prior []T, same func(a, b T) bool) []T {
+	if list == nil {
+		return nil
+	}
+	kept := make([]T, 0, len(list))
+	for _, item := range list {
+		found := false
+		for _, p := range prior {
+			if same(item, p) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
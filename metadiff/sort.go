@@ -0,0 +1,77 @@
+package metadiff
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+)
+
+// 报告排序方式
+const (
+	SortById       = "id"
+	SortByTitle    = "title"
+	SortBySeverity = "severity"
+)
+
+// Sort 按指定方式对报告中的各个列表排序
+// severity 仅影响更新与版本变动列表，按 major、minor、patch 的顺序排列，其余列表按 Id 排序
+func (r *ChangeReport) Sort(by string) error {
+	var less func(a, b catalog.Entry) bool
+	switch by {
+	case SortById, SortBySeverity:
+		less = func(a, b catalog.Entry) bool { return a.Id < b.Id }
+	case SortByTitle:
+		less = func(a, b catalog.Entry) bool {
+			if a.Title != b.Title {
+				return a.Title < b.Title
+			}
+			return a.Id < b.Id
+		}
+	default:
+		return fmt.Errorf("unknown sort key %q (expected %s, %s or %s)", by, SortById, SortByTitle, SortBySeverity)
+	}
+
+	sortEntries(r.Added, less)
+	sortEntries(r.Removed, less)
+	sortEntries(r.ExpectedRemovals, less)
+	sortEntries(r.Unchanged, less)
+	sortUpdates(r.OwnershipChanges, less)
+	sortUpdates(r.Updated, less)
+	sortUpdates(r.TypeChanges, less)
+	sortUpdates(r.Renamed, less)
+	sort.SliceStable(r.Moved, func(i, j int) bool {
+		return less(r.Moved[i].Entry, r.Moved[j].Entry)
+	})
+	// 版本变动只有 Id 可供排序
+	sort.SliceStable(r.VersionBumps, func(i, j int) bool {
+		return r.VersionBumps[i].Id < r.VersionBumps[j].Id
+	})
+
+	if by == SortBySeverity {
+		sort.SliceStable(r.Updated, func(i, j int) bool {
+			return severityOf(r.Updated[i]) > severityOf(r.Updated[j])
+		})
+		sort.SliceStable(r.VersionBumps, func(i, j int) bool {
+			return r.VersionBumps[i].Kind > r.VersionBumps[j].Kind
+		})
+	}
+	return nil
+}
+
+// severityOf 返回更新条目的版本变动级别
+func severityOf(u UpdateEntry) BumpKind {
+	return ClassifyBump(u.Before.Version, u.After.Version)
+}
+
+func sortEntries(entries []catalog.Entry, less func(a, b catalog.Entry) bool) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return less(entries[i], entries[j])
+	})
+}
+
+func sortUpdates(updates []UpdateEntry, less func(a, b catalog.Entry) bool) {
+	sort.SliceStable(updates, func(i, j int) bool {
+		return less(updates[i].After, updates[j].After)
+	})
+}
@@ -0,0 +1,41 @@
+package metadiff
+
+import (
+	"sort"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+)
+
+// TitleChanges 两个条目之间本地化标题的变动，各列表均为语言代码并按字典序排序
+type TitleChanges struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+	// Changed 两侧均声明但标题不同的语言
+	Changed []string `json:"changed,omitempty"`
+}
+
+// CompareTitles 逐个语言比较两个条目的本地化标题，没有变动时返回 nil
+func CompareTitles(before, after catalog.Entry) *TitleChanges {
+	var changes TitleChanges
+	for locale, title := range after.Titles {
+		old, exists := before.Titles[locale]
+		if !exists {
+			changes.Added = append(changes.Added, locale)
+		} else if old != title {
+			changes.Changed = append(changes.Changed, locale)
+		}
+	}
+	for locale := range before.Titles {
+		if _, exists := after.Titles[locale]; !exists {
+			changes.Removed = append(changes.Removed, locale)
+		}
+	}
+
+	if len(changes.Added) == 0 && len(changes.Removed) == 0 && len(changes.Changed) == 0 {
+		return nil
+	}
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Removed)
+	sort.Strings(changes.Changed)
+	return &changes
+}
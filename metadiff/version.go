@@ -0,0 +1,132 @@
+package metadiff
+
+import (
+	"fmt"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+)
+
+// BumpKind 版本变动的级别
+type BumpKind int
+
+const (
+	BumpNone BumpKind = iota
+	BumpPatch
+	BumpMinor
+	BumpMajor
+)
+
+func (k BumpKind) String() string {
+	switch k {
+	case BumpPatch:
+		return "patch"
+	case BumpMinor:
+		return "minor"
+	case BumpMajor:
+		return "major"
+	default:
+		return "none"
+	}
+}
+
+// ParseBumpKind 解析 patch、minor、major 等级别名称
+func ParseBumpKind(name string) (BumpKind, error) {
+	for _, k := range []BumpKind{BumpNone, BumpPatch, BumpMinor, BumpMajor} {
+		if k.String() == name {
+			return k, nil
+		}
+	}
+	return BumpNone, fmt.Errorf("unknown bump kind %q (expected patch, minor or major)", name)
+}
+
+// MarshalText 使 BumpKind 在 JSON 中输出为 patch、minor 等名称
+func (k BumpKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText 解析 MarshalText 输出的名称，使报告可以被重新读入
+func (k *BumpKind) UnmarshalText(text []byte) error {
+	kind, err := ParseBumpKind(string(text))
+	if err != nil {
+		return err
+	}
+	*k = kind
+	return nil
+}
+
+// VersionBump 仅有版本号发生变化的更新，或由 Options.Precedence 按 Version 归类的更新
+type VersionBump struct {
+	Id   string   `json:"id"`
+	From string   `json:"from"`
+	To   string   `json:"to"`
+	Kind BumpKind `json:"kind"`
+	// Update 由 Options.Precedence 归入时的完整更新，保留除版本外其它字段的变动；只有版本变化时为空
+	Update *UpdateEntry `json:"update,omitempty"`
+}
+
+// ClassifyBump 判断版本从 before 变为 after 属于哪一级变动
+// 任一版本号无法解析时按 major 处理，以免低估变动；版本回退同样按其所在级别计算
+func ClassifyBump(before, after string) BumpKind {
+	if before == after {
+		return BumpNone
+	}
+	b, okBefore := catalog.ParseVersion(before)
+	a, okAfter := catalog.ParseVersion(after)
+	if !okBefore || !okAfter {
+		return BumpMajor
+	}
+	switch {
+	case a.Major != b.Major:
+		return BumpMajor
+	case a.Minor != b.Minor:
+		return BumpMinor
+	default:
+		// 只有补丁号或预发布标识发生变化
+		return BumpPatch
+	}
+}
+
+// AdapterBumps 返回报告中每个发生变化的适配器对发布级别的影响
+// 移除适配器、重命名、移动与改变 Type 均视为 major；新增适配器不计入
+func (r *ChangeReport) AdapterBumps() []VersionBump {
+	var bumps []VersionBump
+	for _, e := range r.Removed {
+		bumps = append(bumps, VersionBump{Id: e.Id, From: e.Version, Kind: BumpMajor})
+	}
+	for _, m := range r.Moved {
+		bumps = append(bumps, VersionBump{Id: m.From, From: m.Entry.Version, To: m.Entry.Version, Kind: BumpMajor})
+	}
+	for _, u := range r.Renamed {
+		bumps = append(bumps, VersionBump{Id: u.Before.Id, From: u.Before.Version, To: u.After.Version, Kind: BumpMajor})
+	}
+	for _, u := range r.Updated {
+		bumps = append(bumps, updateBump(u))
+	}
+	// 按优先级只归入 TypeChanges 或 OwnershipChanges 的更新不在 Updated 中，需要单独计入
+	for _, u := range r.exclusiveTypes() {
+		bumps = append(bumps, updateBump(u))
+	}
+	for _, u := range r.OwnershipOnly() {
+		bumps = append(bumps, updateBump(u))
+	}
+	bumps = append(bumps, r.VersionBumps...)
+	return bumps
+}
+
+// updateBump 返回单个更新对发布级别的影响，改变 Type 视为 major
+func updateBump(u UpdateEntry) VersionBump {
+	kind := ClassifyBump(u.Before.Version, u.After.Version)
+	if u.Before.Type != u.After.Type {
+		kind = BumpMajor
+	}
+	return VersionBump{Id: u.After.Id, From: u.Before.Version, To: u.After.Version, Kind: kind}
+}
+
+// Severity 返回报告整体对发布级别的影响，即 AdapterBumps 中的最高级别；没有计入的变动时为 none
+func (r *ChangeReport) Severity() BumpKind {
+	severity := BumpNone
+	for _, bump := range r.AdapterBumps() {
+		severity = max(severity, bump.Kind)
+	}
+	return severity
+}
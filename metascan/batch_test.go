@@ -0,0 +1,19 @@
+package metascan
+
+import "testing"
+
+func TestScanBatchRegister(t *testing.T) {
+	results, entries := scanFixture(t, "batch", Options{})
+	wantNoDiagnostics(t, entries)
+	// 默认只识别 RegisterAll，custom 包的 registerEach 不被展开
+	wantIds(t, results, "batch-one", "batch-two")
+
+	results, entries = scanFixture(t, "batch", Options{BatchRegister: "registerEach"})
+	wantNoDiagnostics(t, entries)
+	wantIds(t, results, "custom-a", "custom-b")
+
+	// 每个元素的实参分别代入构造函数
+	if got := resultsById(t, results)["custom-b"].Metadata.Title; got != "Custom B" {
+		t.Errorf("custom-b Title = %q, want Custom B", got)
+	}
+}
@@ -0,0 +1,33 @@
+package metascan
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScanCapabilities(t *testing.T) {
+	results, entries := scanFixture(t, "capabilities", Options{})
+	wantNoDiagnostics(t, entries)
+	wantIds(t, results, "streaming", "lossless", "plain")
+
+	byId := resultsById(t, results)
+	tests := []struct {
+		id   string
+		want map[string]bool
+	}{
+		// 键与值可以是常量
+		{"streaming", map[string]bool{"search": true, "stream": false, "lyrics": true}},
+		// 引用包级变量的 map 字面量
+		{"lossless", map[string]bool{"flac": true, "alac": false}},
+		{"plain", nil},
+	}
+	for _, tt := range tests {
+		if got := byId[tt.id].Capabilities; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s Capabilities = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+
+	if got := Entries(results); len(got) != 3 || !reflect.DeepEqual(got[0].Capabilities, byId[got[0].Id].Capabilities) {
+		t.Errorf("Entries did not carry the capabilities: %+v", got)
+	}
+}
@@ -0,0 +1,14 @@
+package metascan
+
+import "testing"
+
+func TestScanReturnedClosures(t *testing.T) {
+	results, entries := scanFixture(t, "closures", Options{})
+	wantNoDiagnostics(t, entries)
+	wantIds(t, results, "closure", "factory", "factoryvar", "funcref", "ifacemethod")
+
+	// 工厂函数的实参代入其返回的闭包
+	if got := resultsById(t, results)["factory"].Metadata.Version; got != "1.2.0" {
+		t.Errorf("factory Version = %q, want the 1.2.0 passed to makeFactory", got)
+	}
+}
@@ -0,0 +1,30 @@
+package metascan
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/meloshub/meloshub-tools/diagnostics"
+)
+
+const wrongTypeMessage = "constructor New returns *github.com/meloshub/meloshub-tools/metascan/testdata/ctortype/wrong.Incomplete, which is not assignable to github.com/meloshub/meloshub/adapter.Adapter expected by Register"
+
+func TestScanConstructorTypeMismatch(t *testing.T) {
+	results, entries := scanFixture(t, "ctortype", Options{})
+	// 不匹配只是警告，适配器仍会被发现
+	wantIds(t, results, "good", "wrong")
+	if len(entries) != 1 || entries[0].Message != wrongTypeMessage || entries[0].Severity != diagnostics.SeverityWarning {
+		t.Fatalf("diagnostics = %+v, want one warning %q", entries, wrongTypeMessage)
+	}
+	if e := entries[0]; !strings.HasSuffix(e.File, "wrong.go") || e.Line != 14 {
+		t.Errorf("warning position = %s:%d, want the constructor in wrong.go:14", e.File, e.Line)
+	}
+}
+
+func TestScanConstructorTypeMismatchStrict(t *testing.T) {
+	requireLoader(t)
+	_, err := Scan(fixtureDir(t, "ctortype"), Options{Strict: true, Quiet: true, Diagnostics: &diagnostics.Diagnostics{}})
+	if err == nil || !strings.Contains(err.Error(), "wrong.go:14:1: "+wrongTypeMessage) {
+		t.Errorf("err = %v, want the mismatch as a scan error", err)
+	}
+}
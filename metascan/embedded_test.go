@@ -0,0 +1,21 @@
+package metascan
+
+import "testing"
+
+func TestScanEmbeddedBaseStruct(t *testing.T) {
+	results, entries := scanFixture(t, "embedded", Options{})
+	wantNoDiagnostics(t, entries)
+	wantIds(t, results, "shared", "override", "field")
+
+	byId := resultsById(t, results)
+	want := map[string]string{
+		"shared":   "Shared Team <team@example.com>",
+		"override": "Direct <d@example.com>",
+		"field":    "Shared Team <team@example.com>",
+	}
+	for id, author := range want {
+		if got := byId[id].Metadata.Author; got != author {
+			t.Errorf("%s Author = %q, want %q", id, got, author)
+		}
+	}
+}
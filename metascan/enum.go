@@ -0,0 +1,276 @@
+package metascan
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strconv"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// enumStringer 从枚举类型的 String 方法推导出的取值表，支持 Type: adapter.AdapterType(KindCommunity.String()) 这样的写法
+type enumStringer struct {
+	// values 常量值（constant.Value.ExactString()）到 String() 结果的映射
+	values map[string]string
+	// fallback switch 中 default 分支或 switch 之后的 return 给出的结果，没有时为 nil
+	fallback *string
+	// reason String 方法过于复杂、无法推导时的原因，不为空时 values 无效
+	reason string
+}
+
+// lookup 返回常量值对应的 String() 结果
+func (e enumStringer) lookup(value constant.Value) (string, bool) {
+	if result, ok := e.values[value.ExactString()]; ok {
+		return result, true
+	}
+	if e.fallback != nil {
+		return *e.fallback, true
+	}
+	return "", false
+}
+
+// collectEnumStringers 收集所有包中整数枚举类型的 String 方法，键为 包路径.类型名
+// 只有已加载源码的包（即扫描目录下的包）可以推导，依赖包中的枚举类型不会出现在结果中
+func collectEnumStringers(pkgs []*packages.Package) map[string]enumStringer {
+	stringers := make(map[string]enumStringer)
+	for _, pkg := range pkgs {
+		if pkg.TypesInfo == nil {
+			continue
+		}
+		vars := packageVars(pkg)
+		for _, file := range pkg.Syntax {
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Recv == nil || fn.Name.Name != "String" || fn.Body == nil {
+					continue
+				}
+				method, ok := pkg.TypesInfo.Defs[fn.Name].(*types.Func)
+				if !ok {
+					continue
+				}
+				named := enumType(method)
+				if named == nil {
+					continue
+				}
+				stringers[pkg.PkgPath+"."+named.Obj().Name()] = deriveEnumStringer(pkg.TypesInfo, fn, vars)
+			}
+		}
+	}
+	return stringers
+}
+
+// enumType 返回 String 方法的接收者类型；只接受值接收者、没有参数且返回 string 的方法，
+// 接收者的底层类型须为整数，否则不是 iota 枚举，返回 nil
+func enumType(method *types.Func) *types.Named {
+	sig := method.Type().(*types.Signature)
+	if sig.Params().Len() != 0 || sig.Results().Len() != 1 || !isString(sig.Results().At(0).Type()) {
+		return nil
+	}
+	named, ok := sig.Recv().Type().(*types.Named)
+	if !ok {
+		return nil
+	}
+	basic, ok := named.Underlying().(*types.Basic)
+	if !ok || basic.Info()&types.IsInteger == 0 {
+		return nil
+	}
+	return named
+}
+
+// isString 判断类型的底层类型是否为 string
+func isString(typ types.Type) bool {
+	basic, ok := typ.Underlying().(*types.Basic)
+	return ok && basic.Info()&types.IsString != 0
+}
+
+// deriveEnumStringer 推导 String 方法的取值表，只支持两种简单形式：
+// 以接收者为条件、每个分支只返回字符串常量的 switch（之后可以再返回一个常量作为默认值），
+// 以及 return names[k] 这样以接收者为下标读取包级 map 或数组字面量；vars 为所在包的包级变量
+func deriveEnumStringer(info *types.Info, fn *ast.FuncDecl, vars bindings) enumStringer {
+	recv := fn.Recv.List[0]
+	if len(recv.Names) == 0 {
+		return enumStringer{reason: "its receiver is unnamed"}
+	}
+	recvObj := info.Defs[recv.Names[0]]
+
+	stmts := fn.Body.List
+	if len(stmts) == 1 {
+		if ret, ok := stmts[0].(*ast.ReturnStmt); ok && len(ret.Results) == 1 {
+			if index, ok := ret.Results[0].(*ast.IndexExpr); ok && isReceiver(info, index.Index, recvObj) {
+				return tableStringer(info, index.X, vars)
+			}
+		}
+	}
+
+	if len(stmts) == 0 || len(stmts) > 2 {
+		return enumStringer{reason: "it is neither a switch over the receiver nor a table lookup"}
+	}
+	sw, ok := stmts[0].(*ast.SwitchStmt)
+	if !ok || sw.Init != nil || !isReceiver(info, sw.Tag, recvObj) {
+		return enumStringer{reason: "it is neither a switch over the receiver nor a table lookup"}
+	}
+	stringer := enumStringer{values: make(map[string]string)}
+	if len(stmts) == 2 {
+		value, ok := returnedString(info, stmts[1])
+		if !ok {
+			return enumStringer{reason: "the statement after the switch is not a return of a string constant"}
+		}
+		stringer.fallback = &value
+	}
+	for _, stmt := range sw.Body.List {
+		clause := stmt.(*ast.CaseClause)
+		if len(clause.Body) != 1 {
+			return enumStringer{reason: "a case does more than return a string constant"}
+		}
+		value, ok := returnedString(info, clause.Body[0])
+		if !ok {
+			return enumStringer{reason: "a case does more than return a string constant"}
+		}
+		if clause.List == nil {
+			stringer.fallback = &value
+			continue
+		}
+		for _, expr := range clause.List {
+			tv := info.Types[expr]
+			if tv.Value == nil {
+				return enumStringer{reason: "a case value is not a constant"}
+			}
+			stringer.values[tv.Value.ExactString()] = value
+		}
+	}
+	return stringer
+}
+
+// tableStringer 从包级 map 或数组变量的字面量推导取值表，数组元素的下标即常量值
+func tableStringer(info *types.Info, table ast.Expr, vars bindings) enumStringer {
+	ident, ok := table.(*ast.Ident)
+	if !ok {
+		return enumStringer{reason: "the table is not a package-level variable"}
+	}
+	lit := resolveCompositeLit(info, ident, vars)
+	if lit == nil {
+		return enumStringer{reason: "the table is not initialized with a map or array literal"}
+	}
+
+	stringer := enumStringer{values: make(map[string]string)}
+	next := int64(0)
+	for _, el := range lit.Elts {
+		key := constant.MakeInt64(next)
+		value := el
+		if kv, ok := el.(*ast.KeyValueExpr); ok {
+			tv := info.Types[kv.Key]
+			if tv.Value == nil {
+				return enumStringer{reason: "a table key is not a constant"}
+			}
+			key, value = tv.Value, kv.Value
+		}
+		result, ok := resolveExprValue(info, value, nil)
+		if !ok {
+			return enumStringer{reason: "a table value is not a string constant"}
+		}
+		stringer.values[key.ExactString()] = result
+		if n, ok := constant.Int64Val(key); ok {
+			next = n + 1
+		}
+	}
+	return stringer
+}
+
+// isReceiver 判断表达式是否为接收者本身，允许外层的类型转换，如 int(k)
+func isReceiver(info *types.Info, expr ast.Expr, recvObj types.Object) bool {
+	if call, ok := expr.(*ast.CallExpr); ok && len(call.Args) == 1 {
+		if tv, ok := info.Types[call.Fun]; ok && tv.IsType() {
+			expr = call.Args[0]
+		}
+	}
+	ident, ok := expr.(*ast.Ident)
+	return ok && recvObj != nil && info.Uses[ident] == recvObj
+}
+
+// returnedString 返回 return 语句中的字符串常量
+func returnedString(info *types.Info, stmt ast.Stmt) (string, bool) {
+	ret, ok := stmt.(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return "", false
+	}
+	return resolveExprValue(info, ret.Results[0], nil)
+}
+
+// stringCall 判断表达式是否为无参数的 x.String() 调用，返回接收者表达式
+func stringCall(expr ast.Expr) (ast.Expr, bool) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 0 {
+		return nil, false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "String" {
+		return nil, false
+	}
+	return sel.X, true
+}
+
+// receiverObject 返回 x.String() 中接收者引用的常量、形参或变量
+func receiverObject(info *types.Info, recv ast.Expr) types.Object {
+	switch r := recv.(type) {
+	case *ast.Ident:
+		return info.ObjectOf(r)
+	case *ast.SelectorExpr:
+		return info.ObjectOf(r.Sel)
+	}
+	return nil
+}
+
+// bindEnumStrings 将函数体中 x.String() 调用的接收者绑定为 String() 的结果，x 须为枚举常量，
+// 或绑定到枚举常量的构造函数形参；resolveExprValue 求值 x.String() 时读取该绑定。
+// 接收者类型的 String 方法过于复杂或没有对应的结果时输出警告，对应字段保持为空
+func (s *scanner) bindEnumStrings(pkg *packages.Package, body *ast.BlockStmt, params bindings) {
+	info := pkg.TypesInfo
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		recv, ok := stringCall(call)
+		if !ok {
+			return true
+		}
+		method, ok := info.Uses[call.Fun.(*ast.SelectorExpr).Sel].(*types.Func)
+		if !ok {
+			return true
+		}
+		named := enumType(method)
+		obj := receiverObject(info, recv)
+		if named == nil || named.Obj().Pkg() == nil || obj == nil {
+			return true
+		}
+		value := info.Types[recv].Value
+		if bound, ok := params[obj]; ok && value == nil {
+			value = info.Types[bound].Value
+		}
+		if value == nil {
+			return true
+		}
+
+		name := named.Obj().Pkg().Path() + "." + named.Obj().Name()
+		stringer, ok := s.enumStringers[name]
+		if !ok {
+			s.tracef("%s: %s.String() is declared outside the scanned packages and cannot be evaluated", pkg.PkgPath, name)
+			return true
+		}
+		pos := pkg.Fset.Position(call.Pos())
+		if stringer.reason != "" {
+			s.warnf(pos, "could not evaluate %s.String() statically: %s", name, stringer.reason)
+			return true
+		}
+		result, ok := stringer.lookup(value)
+		if !ok {
+			s.warnf(pos, "could not evaluate %s.String() statically: no result for value %s", name, value.ExactString())
+			return true
+		}
+		s.tracef("%s: %s.String() of %s resolved to %q", pkg.PkgPath, name, value.ExactString(), result)
+		params[obj] = &ast.BasicLit{ValuePos: call.Pos(), Kind: token.STRING, Value: strconv.Quote(result)}
+		return true
+	})
+}
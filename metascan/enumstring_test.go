@@ -0,0 +1,44 @@
+package metascan
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/meloshub/meloshub-tools/diagnostics"
+)
+
+func TestScanEnumString(t *testing.T) {
+	results, entries := scanFixture(t, "enumstring", Options{})
+	wantIds(t, results, "switch", "table", "p1", "p2", "complex", "outofrange")
+
+	byId := resultsById(t, results)
+	tests := []struct{ id, field, got, want string }{
+		{"switch", "Type", string(byId["switch"].Metadata.Type), "community"},
+		// 数组查表
+		{"table", "Description", byId["table"].Metadata.Description, "high"},
+		// 接收者是绑定到枚举常量的形参
+		{"p1", "Type", string(byId["p1"].Metadata.Type), "official"},
+		{"p2", "Type", string(byId["p2"].Metadata.Type), "unknown"},
+		// 无法推导的 String 方法使字段保持为空
+		{"complex", "Type", string(byId["complex"].Metadata.Type), ""},
+		{"outofrange", "Description", byId["outofrange"].Metadata.Description, ""},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s %s = %q, want %q", tt.id, tt.field, tt.got, tt.want)
+		}
+	}
+
+	const prefix = "could not evaluate github.com/meloshub/meloshub-tools/metascan/testdata/enumstring/kind."
+	var warnings []diagnostics.Entry
+	for _, e := range entries {
+		if e.Severity == diagnostics.SeverityWarning && strings.HasPrefix(e.Message, prefix) {
+			warnings = append(warnings, e)
+		}
+	}
+	if len(warnings) != 2 {
+		t.Errorf("String() warnings = %v, want two", warnings)
+	}
+	wantDiagnostic(t, warnings, prefix+"Odd.String() statically: it is neither a switch over the receiver nor a table lookup")
+	wantDiagnostic(t, warnings, prefix+"Level.String() statically: no result for value 2")
+}
@@ -0,0 +1,30 @@
+package metascan
+
+import "testing"
+
+func TestScanForeignVars(t *testing.T) {
+	results, entries := scanFixture(t, "foreignvars", Options{})
+	wantIds(t, results, "family-member")
+	if len(results) != 1 {
+		return
+	}
+
+	meta := results[0].Metadata
+	if meta.Version != "2.3.0" {
+		t.Errorf("Version = %q, want 2.3.0 from base.Version", meta.Version)
+	}
+	if want := `"Family" Member \ Deluxe`; meta.Title != want {
+		t.Errorf("Title = %q, want %q with its quotes and backslash kept", meta.Title, want)
+	}
+	if meta.Author != "Base Team <base@example.com>" {
+		t.Errorf("Author = %q, want the value of base.Maintainer through base.Author", meta.Author)
+	}
+	// 无法静态求值的变量保持为空并给出警告
+	if meta.Description != "" {
+		t.Errorf("Description = %q, want it left empty", meta.Description)
+	}
+	wantDiagnostic(t, entries, "could not determine the value of variable github.com/meloshub/meloshub-tools/metascan/testdata/foreignvars/base.Build statically")
+	if len(entries) != 1 {
+		t.Errorf("got %d diagnostics, want only the base.Build warning", len(entries))
+	}
+}
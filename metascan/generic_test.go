@@ -0,0 +1,17 @@
+package metascan
+
+import "testing"
+
+func TestScanGenericConstructors(t *testing.T) {
+	results, entries := scanFixture(t, "generic", Options{})
+	wantNoDiagnostics(t, entries)
+	wantIds(t, results, "lastfm", "listenbrainz", "pair", "made", "genmethod")
+
+	byId := resultsById(t, results)
+	if got := byId["listenbrainz"].Metadata.Title; got != "ListenBrainz" {
+		t.Errorf("listenbrainz Title = %q, want the argument bound to the generic constructor's parameter", got)
+	}
+	if got := byId["made"].Metadata.Title; got != "Made" {
+		t.Errorf("made Title = %q, want Made", got)
+	}
+}
@@ -0,0 +1,20 @@
+package metascan
+
+import (
+	"testing"
+
+	"github.com/meloshub/meloshub/adapter"
+)
+
+func TestScanMetadataType(t *testing.T) {
+	results, _ := scanFixture(t, "metadatatype", Options{})
+	wantIds(t, results)
+
+	results, entries := scanFixture(t, "metadatatype", Options{MetadataType: "adapter.AdapterInfo"})
+	wantNoDiagnostics(t, entries)
+	wantIds(t, results, "forked")
+	want := adapter.Metadata{Id: "forked", Title: "Forked", Type: adapter.TypeCommunity, Version: "0.1.0", Author: "Fork <f@example.com>"}
+	if len(results) == 1 && results[0].Metadata != want {
+		t.Errorf("forked = %+v, want %+v", results[0].Metadata, want)
+	}
+}
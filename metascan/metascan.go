@@ -0,0 +1,1311 @@
+// Package metascan 通过静态分析 Go 源码扫描适配器的注册调用，提取其元数据
+package metascan
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"log"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+	"github.com/meloshub/meloshub-tools/diagnostics"
+	"github.com/meloshub/meloshub/adapter"
+	"golang.org/x/tools/go/packages"
+)
+
+// Result 扫描得到的单个适配器
+type Result struct {
+	Metadata adapter.Metadata
+	// Capabilities 元数据字面量中声明的功能开关
+	Capabilities map[string]bool
+	// Titles 元数据字面量中声明的本地化标题，键为语言代码
+	Titles map[string]string
+	// Pos 元数据结构体字面量在源码中的位置
+	Pos token.Position
+	// Module 适配器所在的模块，无法确定时为 nil
+	Module *packages.Module
+	// Unresolved 字面量中给出了值、却无法静态求值而留空的字符串字段
+	Unresolved []UnresolvedField
+	// SourceHash 定义元数据字面量的顶层声明（通常是构造函数）的 SHA-256，仅在 Options.SourceHash 时填充；
+	// 源码改变而元数据不变时同样会改变，可用于缓存失效与来源追踪
+	SourceHash string
+
+	// unresolved 尚未转换为源码位置的 Unresolved
+	unresolved []unresolvedExpr
+}
+
+// UnresolvedField 元数据字面量中无法静态求值的字段
+type UnresolvedField struct {
+	// Field 字段的 Go 名称，如 Version
+	Field string
+	// Expr 源码中的表达式
+	Expr string
+	// Reason 无法求值的原因
+	Reason string
+	Pos    token.Position
+}
+
+// PendingFields 返回 Unresolved 中目前仍为空的字段；扫描之后才补上的值
+// （如 metagen --version-from-module 填入的版本）不再计入
+func (r Result) PendingFields() []UnresolvedField {
+	meta := reflect.ValueOf(r.Metadata)
+	var pending []UnresolvedField
+	for _, u := range r.Unresolved {
+		if meta.FieldByIndex(metadataFields[u.Field]).IsZero() {
+			pending = append(pending, u)
+		}
+	}
+	return pending
+}
+
+// unresolvedExpr 无法静态求值的字段及其表达式
+type unresolvedExpr struct {
+	field string
+	expr  ast.Expr
+}
+
+// Options 控制扫描行为
+type Options struct {
+	// Trace 为 true 时逐步输出每个包的解析过程，用于排查未被发现的适配器
+	Trace bool
+	// BatchRegister 批量注册函数的名称，其参数为适配器的切片字面量；为空时使用 DefaultBatchRegister
+	BatchRegister string
+	// RegisterArgIndex adapter.Register 调用中作为适配器或构造函数追踪的参数下标，默认为第一个参数；
+	// 供 Register(name string, ctor func() Adapter) 这类签名不同的分支使用
+	RegisterArgIndex int
+	// IncludeTests 为 true 时扫描包含 _test.go 文件的测试变体包，默认完全排除测试包
+	IncludeTests bool
+	// MetadataType 元数据结构体类型名称须匹配的后缀，为空时使用 DefaultMetadataType
+	// 将 Metadata 重命名的分支（如 adapter.AdapterInfo）可以借此复用扫描逻辑
+	MetadataType string
+	// Strict 为 true 时，构造函数返回类型与注册函数参数类型不匹配等注册错误会使扫描失败，而不只是警告
+	Strict bool
+	// Diagnostics 扫描过程中的警告通过它写入日志并记录；为 nil 时只写入日志
+	Diagnostics *diagnostics.Diagnostics
+	// OnWarning 不为 nil 时，扫描过程中的每条警告在写入日志的同时也会传给它
+	OnWarning func(Warning)
+	// OnPackage 不为 nil 时，每扫描完一个包都会传入其耗时；为 nil 时不计时
+	OnPackage func(PackageTiming)
+	// OnLoad 不为 nil 时，在 packages.Load 完成后传入加载与类型检查的耗时
+	OnLoad func(time.Duration)
+	// Quiet 不输出 "Found metadata" 等信息性日志，警告、Trace 与 MaxMemory 的限流日志不受影响
+	Quiet bool
+	// SourceHash 为 true 时为每个适配器计算 Result.SourceHash
+	SourceHash bool
+	// Workers 同时扫描的包数，小于等于 0 时使用 runtime.GOMAXPROCS(0)
+	// 结果、日志与警告的顺序与逐个扫描时相同；开启 Trace 时每个包的调试信息在该包扫描完成后才输出
+	Workers int
+	// MaxMemory 大于 0 时，堆内存占用达到该字节数后减少同时扫描的包数，回落后再恢复
+	// 这是软性上限：只限制对已加载的包的分析，packages.Load 本身的内存占用不受控制
+	MaxMemory uint64
+	// Env 加载包时使用的环境变量，如设置 GOOS、GOARCH 以按其它平台的构建约束扫描；为 nil 时使用当前进程的环境
+	// 只对 Scan 与 ScanWithOverlay 有效，ScanPackagesJSON 的文件列表已由 go list 确定
+	Env []string
+}
+
+// PackageTiming 扫描单个包的耗时
+// 只包含在已加载的语法树中寻找元数据的时间，不包含 packages.Load 加载与类型检查的时间；
+// 并行扫描时为该包自身的实际耗时，不包含等待空闲 goroutine 的时间，但与其他包的扫描相互重叠
+type PackageTiming struct {
+	PkgPath  string
+	Files    int
+	Adapters int
+	Duration time.Duration
+}
+
+// Warning 扫描过程中产生的一条警告
+type Warning struct {
+	// Pos 警告相关的源码位置，未知时为零值
+	Pos     token.Position
+	Message string
+}
+
+// DefaultBatchRegister 默认识别的批量注册函数名称
+const DefaultBatchRegister = "RegisterAll"
+
+// batchRegister 返回实际使用的批量注册函数名称
+func (o Options) batchRegister() string {
+	if o.BatchRegister == "" {
+		return DefaultBatchRegister
+	}
+	return o.BatchRegister
+}
+
+// DefaultMetadataType 默认识别的元数据类型名称后缀
+const DefaultMetadataType = "adapter.Metadata"
+
+// metadataType 返回实际使用的元数据类型名称后缀
+func (o Options) metadataType() string {
+	if o.MetadataType == "" {
+		return DefaultMetadataType
+	}
+	return o.MetadataType
+}
+
+// scanner 保存一次扫描的配置
+type scanner struct {
+	opts Options
+	// errs 严格模式下记录的注册错误
+	errs []error
+	// stringVars 已加载的包中值可以静态确定的包级字符串变量，键为 包路径.变量名
+	stringVars map[string]string
+	// enumStringers 已加载的包中整数枚举类型的 String 方法，键为 包路径.类型名
+	enumStringers map[string]enumStringer
+	// pending 不为 nil 时，警告与调试信息不立即输出，而是按顺序缓存在其中，见 scanPackages
+	pending *[]func()
+}
+
+// emit 输出一条警告或调试信息；扫描单个包时缓存起来，由 scanPackages 按包的顺序输出
+func (s *scanner) emit(fn func()) {
+	if s.pending == nil {
+		fn()
+		return
+	}
+	*s.pending = append(*s.pending, fn)
+}
+
+// registrationErrorf 报告一个注册错误：严格模式下记录为扫描错误，否则只输出警告
+func (s *scanner) registrationErrorf(pos token.Position, format string, args ...any) {
+	if !s.opts.Strict {
+		s.warnf(pos, format, args...)
+		return
+	}
+	err := fmt.Errorf(format, args...)
+	if pos.IsValid() {
+		err = fmt.Errorf("%s: %w", pos, err)
+	}
+	s.errs = append(s.errs, err)
+}
+
+// warnf 将警告报告给 Options.Diagnostics，并交给 Options.OnWarning
+func (s *scanner) warnf(pos token.Position, format string, args ...any) {
+	message := fmt.Sprintf(format, args...)
+	s.emit(func() {
+		s.opts.Diagnostics.Warnf("", pos, "%s", message)
+		if s.opts.OnWarning != nil {
+			s.opts.OnWarning(Warning{Pos: pos, Message: message})
+		}
+	})
+}
+
+// tracef 在开启 Trace 时输出调试信息
+func (s *scanner) tracef(format string, args ...any) {
+	if s.opts.Trace {
+		message := fmt.Sprintf(format, args...)
+		s.emit(func() { log.Printf("Trace: %s", message) })
+	}
+}
+
+// Scan 加载 dir 下的所有包，并返回其中发现的适配器元数据
+func Scan(dir string, opts Options) ([]Result, error) {
+	return ScanWithOverlay(dir, nil, opts)
+}
+
+// ScanWithOverlay 与 Scan 相同，但使用 overlay 中的内容代替磁盘上的对应文件
+// overlay 的键为文件的绝对路径，编辑器可以借此在不保存文件的情况下预览元数据；
+// 返回结果中的 Pos 指向 overlay 中的内容
+func ScanWithOverlay(dir string, overlay map[string][]byte, opts Options) ([]Result, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedModule,
+		Dir:  dir,
+		Env:  opts.Env,
+		// 开启后 packages.Load 会额外返回测试变体包，由 scanPackages 负责去重
+		Tests:   opts.IncludeTests,
+		Overlay: overlay,
+	}
+	loadStart := time.Now()
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		return nil, fmt.Errorf("error loading packages: %w", err)
+	}
+	if opts.OnLoad != nil {
+		opts.OnLoad(time.Since(loadStart))
+	}
+
+	s := &scanner{opts: opts}
+	results := s.scanPackages(pkgs)
+	return results, errors.Join(s.errs...)
+}
+
+// scanPackages 在已加载的包中寻找适配器元数据
+// 包按 PkgPath 排序后由 Options.Workers 个 goroutine 并行扫描；每个包的结果、警告、调试信息与注册错误
+// 先缓存起来，全部完成后再按包的顺序输出，使结果、日志与警告的顺序不依赖于包加载或扫描完成的顺序
+func (s *scanner) scanPackages(pkgs []*packages.Package) []Result {
+	pkgs = selectTestVariants(pkgs, s.opts.IncludeTests)
+	s.stringVars = collectStringVars(pkgs)
+	s.enumStringers = collectEnumStringers(pkgs)
+	sort.SliceStable(pkgs, func(i, j int) bool {
+		return pkgs[i].PkgPath < pkgs[j].PkgPath
+	})
+
+	scans := make([]packageScan, len(pkgs))
+	forEach(len(pkgs), s.opts.Workers, s.opts.MaxMemory, func(i int) {
+		scans[i] = s.scanPackage(pkgs[i])
+	})
+
+	var results []Result
+	for i, scan := range scans {
+		for _, fn := range scan.pending {
+			fn()
+		}
+		s.errs = append(s.errs, scan.errs...)
+		if scan.skipped {
+			continue
+		}
+		if s.opts.OnPackage != nil {
+			s.opts.OnPackage(PackageTiming{PkgPath: pkgs[i].PkgPath, Files: len(pkgs[i].Syntax), Adapters: len(scan.results), Duration: scan.duration})
+		}
+		for _, result := range scan.results {
+			result.Module = pkgs[i].Module
+			results = append(results, result)
+			if !s.opts.Quiet {
+				log.Printf("Found metadata for adapter: %s", result.Metadata.Id)
+			}
+		}
+	}
+
+	return results
+}
+
+// packageScan 扫描单个包得到的、尚未输出的内容
+type packageScan struct {
+	skipped  bool
+	results  []Result
+	pending  []func()
+	errs     []error
+	duration time.Duration
+}
+
+// scanPackage 使用只属于该包的 scanner 扫描 pkg，使多个包可以并行扫描
+func (s *scanner) scanPackage(pkg *packages.Package) packageScan {
+	var scan packageScan
+	ps := &scanner{opts: s.opts, stringVars: s.stringVars, enumStringers: s.enumStringers, pending: &scan.pending}
+	if isIrrelevantPackage(pkg) {
+		ps.tracef("%s: skipped as irrelevant package", pkg.PkgPath)
+		scan.skipped = true
+		return scan
+	}
+
+	ps.tracef("%s: scanning %d files", pkg.PkgPath, len(pkg.Syntax))
+	var start time.Time
+	if s.opts.OnPackage != nil {
+		start = time.Now()
+	}
+	scan.results = ps.findMetadataInPackage(pkg)
+	if s.opts.OnPackage != nil {
+		scan.duration = time.Since(start)
+	}
+	scan.errs = ps.errs
+	return scan
+}
+
+// Entries 将扫描结果转换为尚未填充派生字段的目录条目，顺序与 results 一致
+func Entries(results []Result) []catalog.Entry {
+	entries := make([]catalog.Entry, 0, len(results))
+	for _, result := range results {
+		entries = append(entries, catalog.Entry{Metadata: result.Metadata, Capabilities: result.Capabilities, Titles: result.Titles})
+	}
+	return entries
+}
+
+// selectTestVariants 根据 includeTests 选择参与扫描的包变体
+// 不包含测试时丢弃所有测试相关的包；包含测试时用测试变体（普通包加上包内 _test.go 文件）
+// 替换对应的普通包，避免同一适配器被发现两次，并始终丢弃生成的 .test 主包。
+// 这一步只处理测试变体，/tests 目录等由 isIrrelevantPackage 过滤的包无论如何都不会被扫描
+func selectTestVariants(pkgs []*packages.Package, includeTests bool) []*packages.Package {
+	hasTestVariant := make(map[string]bool)
+	for _, pkg := range pkgs {
+		if isTestVariant(pkg) {
+			hasTestVariant[pkg.PkgPath] = true
+		}
+	}
+
+	var selected []*packages.Package
+	for _, pkg := range pkgs {
+		isTestPackage := isTestVariant(pkg) || strings.HasSuffix(pkg.PkgPath, "_test") || strings.HasSuffix(pkg.PkgPath, ".test")
+		switch {
+		case !includeTests && isTestPackage:
+			continue
+		case includeTests && strings.HasSuffix(pkg.PkgPath, ".test"):
+			continue
+		case includeTests && !isTestPackage && hasTestVariant[pkg.PkgPath]:
+			continue
+		}
+		selected = append(selected, pkg)
+	}
+	return selected
+}
+
+// isTestVariant 判断包是否为带有测试文件的变体，其 ID 形如 "p [p.test]"
+func isTestVariant(pkg *packages.Package) bool {
+	return strings.Contains(pkg.ID, " [")
+}
+
+// isIrrelevantPackage 过滤无需扫描的包
+func isIrrelevantPackage(pkg *packages.Package) bool {
+	// 过滤测试目录与适配器全集
+	hasIrrelevantSuffix := strings.HasSuffix(pkg.PkgPath, "/tests") || strings.HasSuffix(pkg.PkgPath, "/all")
+
+	isEmptyPackage := len(pkg.GoFiles) == 0
+
+	return hasIrrelevantSuffix || isEmptyPackage
+}
+
+// findMetadataInPackage 遍历包中的所有文件，寻找元数据
+func (s *scanner) findMetadataInPackage(pkg *packages.Package) []Result {
+	var results []Result
+	for _, file := range pkg.Syntax {
+		results = append(results, s.findMetadataInFile(pkg, file)...)
+	}
+	if len(results) == 0 {
+		s.tracef("%s: no adapter metadata found", pkg.PkgPath)
+	}
+	return results
+}
+
+// findMetadataInFile 遍历文件中所有的 init 函数（Go 允许同一文件声明多个），并从中追踪每一个 Register 调用
+func (s *scanner) findMetadataInFile(pkg *packages.Package, file *ast.File) []Result {
+	var found []Result
+
+	for _, decl := range file.Decls {
+		// 只有包级的 init 函数才会在导入时执行，同名的方法不算
+		initFunc, ok := decl.(*ast.FuncDecl)
+		if !ok || initFunc.Name.Name != "init" || initFunc.Recv != nil || initFunc.Body == nil {
+			continue
+		}
+		s.tracef("%s: init function found at %s", pkg.PkgPath, pkg.Fset.Position(initFunc.Pos()))
+
+		registrations := s.findRegisterCallArguments(pkg, initFunc.Body)
+		if len(registrations) == 0 {
+			s.tracef("%s: no adapter.Register call in init", pkg.PkgPath)
+			continue
+		}
+
+		for _, reg := range registrations {
+			s.tracef("%s: Register call found at %s", pkg.PkgPath, pkg.Fset.Position(reg.arg.Pos()))
+			if result := s.resolveRegistration(pkg, file, reg); result != nil {
+				found = append(found, *result)
+			}
+		}
+	}
+
+	return found
+}
+
+// resolveRegistration 追踪单个 Register 调用的参数，找到其对应的元数据
+func (s *scanner) resolveRegistration(pkg *packages.Package, file *ast.File, reg registration) *Result {
+	var meta *Result
+	var pos token.Pos
+	registerArg := reg.arg
+
+	constructorFunc, call := findConstructorFunc(pkg.TypesInfo, file, registerArg)
+	if constructorFunc != nil {
+		s.tracef("%s: constructor resolved to %s at %s", pkg.PkgPath, constructorFunc.Name.Name, pkg.Fset.Position(constructorFunc.Pos()))
+		s.checkConstructorType(pkg, constructorFunc, call, reg.want)
+		// 将调用处的实参代入构造函数的形参，以支持同一个带参构造函数被多次注册
+		params := packageVars(pkg)
+		s.bindForeignVars(pkg, constructorFunc.Body, params)
+		for obj, arg := range bindParams(pkg.TypesInfo, constructorFunc, call) {
+			params[obj] = arg
+		}
+		s.bindEnumStrings(pkg, constructorFunc.Body, params)
+		meta, pos = findMetadataInFuncBody(pkg.TypesInfo, constructorFunc.Body, params, s.opts.metadataType())
+		if meta == nil {
+			s.tracef("%s: no Metadata literal with an Id found in %s", pkg.PkgPath, constructorFunc.Name.Name)
+		}
+	}
+
+	// 构造函数中没有元数据时，尝试从注册值自身的 Metadata() 方法中寻找
+	if meta == nil {
+		if method := findMetadataMethod(pkg, registerArg); method != nil {
+			s.tracef("%s: Metadata method resolved at %s", pkg.PkgPath, pkg.Fset.Position(method.Pos()))
+			params := packageVars(pkg)
+			s.bindForeignVars(pkg, method.Body, params)
+			s.bindEnumStrings(pkg, method.Body, params)
+			meta, pos = findMetadataInFuncBody(pkg.TypesInfo, method.Body, params, s.opts.metadataType())
+			if meta == nil {
+				s.tracef("%s: no Metadata literal with an Id found in Metadata method", pkg.PkgPath)
+			}
+		} else if constructorFunc != nil {
+			meta, pos = s.findMetadataInReturns(pkg, constructorFunc)
+		} else {
+			s.warnf(pkg.Fset.Position(registerArg.Pos()), "Found adapter.Register call in %s, but could not trace its constructor function.", pkg.Fset.File(file.Pos()).Name())
+			return nil
+		}
+	}
+
+	if meta == nil {
+		return nil
+	}
+	s.tracef("%s: Metadata literal found at %s", pkg.PkgPath, pkg.Fset.Position(pos))
+	meta.Pos = pkg.Fset.Position(pos)
+	if s.opts.SourceHash {
+		meta.SourceHash = sourceHash(pkg, pos)
+	}
+	for _, u := range meta.unresolved {
+		field := UnresolvedField{
+			Field:  u.field,
+			Expr:   types.ExprString(u.expr),
+			Reason: unresolvedReason(pkg.TypesInfo, u.expr),
+			Pos:    pkg.Fset.Position(u.expr.Pos()),
+		}
+		s.tracef("%s: field %s of adapter '%s' at %s is left empty: %s is %s", pkg.PkgPath, field.Field, meta.Metadata.Id, field.Pos, field.Expr, field.Reason)
+		meta.Unresolved = append(meta.Unresolved, field)
+	}
+	meta.unresolved = nil
+	return meta
+}
+
+// bindings 构造函数形参到调用处实参表达式的映射，也包含包级变量到其初始值的映射
+type bindings map[types.Object]ast.Expr
+
+// packageVars 收集包中带有初始值的包级变量，使元数据可以引用共享的变量，如 Author: common.Author
+func packageVars(pkg *packages.Package) bindings {
+	vars := make(bindings)
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				valueSpec, ok := spec.(*ast.ValueSpec)
+				if !ok || len(valueSpec.Names) != len(valueSpec.Values) {
+					continue
+				}
+				for i, name := range valueSpec.Names {
+					if obj := pkg.TypesInfo.Defs[name]; obj != nil {
+						vars[obj] = valueSpec.Values[i]
+					}
+				}
+			}
+		}
+	}
+	return vars
+}
+
+// collectStringVars 求出所有包中包级字符串变量的值，供其他包中的元数据引用，如 Version: spotifybase.Version
+// 初始值无法静态确定的变量不会出现在结果中；变量在运行时被重新赋值的情况不做处理
+func collectStringVars(pkgs []*packages.Package) map[string]string {
+	values := make(map[string]string)
+	for _, pkg := range pkgs {
+		if pkg.TypesInfo == nil {
+			continue
+		}
+		vars := packageVars(pkg)
+		for obj, expr := range vars {
+			if !isStringVar(obj) {
+				continue
+			}
+			value, ok := resolveExprValue(pkg.TypesInfo, expr, vars)
+			if !ok {
+				continue
+			}
+			values[obj.Pkg().Path()+"."+obj.Name()] = value
+		}
+	}
+	return values
+}
+
+// isStringVar 判断对象是否为底层类型为 string 的包级变量
+func isStringVar(obj types.Object) bool {
+	v, ok := obj.(*types.Var)
+	if !ok || v.Pkg() == nil || v.Parent() != v.Pkg().Scope() {
+		return false
+	}
+	basic, ok := v.Type().Underlying().(*types.Basic)
+	return ok && basic.Info()&types.IsString != 0
+}
+
+// bindForeignVars 将函数体中引用的其他包的字符串变量绑定为其值
+// 依赖包来自导出数据，其中的对象与被引用包自身的 TypesInfo 不同，因此按 包路径.变量名 查找；
+// 值无法静态确定时输出警告，对应字段保持为空
+func (s *scanner) bindForeignVars(pkg *packages.Package, body *ast.BlockStmt, params bindings) {
+	ast.Inspect(body, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		obj := pkg.TypesInfo.ObjectOf(sel.Sel)
+		if obj == nil || obj.Pkg() == pkg.Types || !isStringVar(obj) {
+			return true
+		}
+		if _, bound := params[obj]; bound {
+			return true
+		}
+		name := obj.Pkg().Path() + "." + obj.Name()
+		value, ok := s.stringVars[name]
+		if !ok {
+			s.warnf(pkg.Fset.Position(sel.Pos()), "could not determine the value of variable %s statically", name)
+			return true
+		}
+		s.tracef("%s: variable %s resolved to %q", pkg.PkgPath, name, value)
+		params[obj] = &ast.BasicLit{ValuePos: sel.Pos(), Kind: token.STRING, Value: strconv.Quote(value)}
+		return true
+	})
+}
+
+// bindParams 将构造函数的形参与调用处的实参一一对应；可变参数不做绑定
+func bindParams(info *types.Info, fn *ast.FuncDecl, call *ast.CallExpr) bindings {
+	if call == nil || fn.Type.Params == nil {
+		return nil
+	}
+
+	params := make(bindings)
+	i := 0
+	for _, field := range fn.Type.Params.List {
+		if _, variadic := field.Type.(*ast.Ellipsis); variadic {
+			break
+		}
+		for _, name := range field.Names {
+			if i >= len(call.Args) {
+				return params
+			}
+			if obj := info.Defs[name]; obj != nil {
+				params[obj] = call.Args[i]
+			}
+			i++
+		}
+	}
+	return params
+}
+
+// registration 一次注册调用的参数
+type registration struct {
+	arg ast.Expr
+	// want 注册函数期望的参数类型，无法确定时为 nil
+	want types.Type
+}
+
+// findRegisterCallArguments 在函数体内寻找所有 adapter.Register 的调用，并返回它们各自下标为
+// Options.RegisterArgIndex 的参数，参数不足的调用输出警告后跳过。
+// 批量注册调用会展开其切片字面量参数，每个元素视为一次单独的注册
+func (s *scanner) findRegisterCallArguments(pkg *packages.Package, body *ast.BlockStmt) []registration {
+	info := pkg.TypesInfo
+	batchName := s.opts.batchRegister()
+	index := s.opts.RegisterArgIndex
+	var registrations []registration
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		callExpr, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+
+		if calleeName(callExpr) == batchName {
+			// 批量注册函数可能是包内的辅助函数，因此只按名称匹配
+			for _, arg := range callExpr.Args {
+				if sliceLit, ok := arg.(*ast.CompositeLit); ok {
+					var want types.Type
+					if slice, ok := typeUnder(info.TypeOf(sliceLit)).(*types.Slice); ok {
+						want = slice.Elem()
+					}
+					for _, elt := range sliceLit.Elts {
+						registrations = append(registrations, registration{arg: elt, want: want})
+					}
+				}
+			}
+			return false
+		}
+
+		selExpr, ok := callExpr.Fun.(*ast.SelectorExpr)
+		if !ok || selExpr.Sel.Name != "Register" {
+			return true
+		}
+
+		if obj := info.ObjectOf(selExpr.Sel); obj != nil {
+			if obj.Pkg() != nil && strings.HasSuffix(obj.Pkg().Path(), "meloshub/adapter") {
+				if len(callExpr.Args) > index {
+					var want types.Type
+					if sig, ok := info.TypeOf(callExpr.Fun).(*types.Signature); ok && sig.Params().Len() > index {
+						want = sig.Params().At(index).Type()
+					}
+					registrations = append(registrations, registration{arg: callExpr.Args[index], want: want})
+					return false
+				}
+				if len(callExpr.Args) > 0 {
+					s.warnf(pkg.Fset.Position(callExpr.Pos()), "adapter.Register call at %s has %d arguments, but the register argument index is %d", pkg.Fset.Position(callExpr.Pos()), len(callExpr.Args), index)
+					return false
+				}
+			}
+		}
+		return true
+	})
+
+	return registrations
+}
+
+// typeUnder 返回类型的底层类型，typ 为 nil 时返回 nil
+func typeUnder(typ types.Type) types.Type {
+	if typ == nil {
+		return nil
+	}
+	return typ.Underlying()
+}
+
+// checkConstructorType 检查构造函数的第一个返回值能否赋值给注册函数期望的参数类型
+// 能够通过编译的代码必然满足这一点，因此只有在包存在类型错误时才会报告问题
+// 泛型构造函数按调用处实例化后的签名检查，否则返回类型形参的构造函数会被误报；
+// 返回闭包的工厂函数按闭包的返回值检查。call 为 nil 时注册的是构造函数本身，按函数类型检查
+func (s *scanner) checkConstructorType(pkg *packages.Package, constructorFunc *ast.FuncDecl, call *ast.CallExpr, want types.Type) {
+	fn, ok := pkg.TypesInfo.Defs[constructorFunc.Name].(*types.Func)
+	if !ok || want == nil || !isValidType(want) {
+		return
+	}
+	// 注册的是构造函数本身（如 Register("x", New)），函数类型须能赋值给参数类型
+	if call == nil {
+		if !types.AssignableTo(fn.Type(), want) {
+			s.registrationErrorf(pkg.Fset.Position(constructorFunc.Pos()), "constructor %s has type %s, which is not assignable to %s expected by Register", constructorFunc.Name.Name, fn.Type(), want)
+		}
+		return
+	}
+	sig := fn.Signature()
+	if ident := funcIdent(call.Fun); ident != nil {
+		if inst, ok := pkg.TypesInfo.Instances[ident]; ok {
+			if instSig, ok := inst.Type.(*types.Signature); ok {
+				sig = instSig
+			}
+		}
+	}
+	results := sig.Results()
+	if results.Len() == 0 {
+		s.registrationErrorf(pkg.Fset.Position(constructorFunc.Pos()), "constructor %s returns no value, but Register expects %s", constructorFunc.Name.Name, want)
+		return
+	}
+	got := results.At(0).Type()
+	// 返回闭包的工厂函数（如 makeFactory()()），注册的是闭包被调用后的结果
+	for {
+		closure, ok := typeUnder(got).(*types.Signature)
+		if !ok || closure.Results().Len() == 0 {
+			break
+		}
+		if _, wantFunc := typeUnder(want).(*types.Signature); wantFunc {
+			break
+		}
+		got = closure.Results().At(0).Type()
+	}
+	// 注册的是调用构造函数的闭包字面量，构造函数的返回值即闭包的返回值
+	if wantSig, ok := typeUnder(want).(*types.Signature); ok && wantSig.Results().Len() > 0 {
+		if _, gotFunc := typeUnder(got).(*types.Signature); !gotFunc {
+			want = wantSig.Results().At(0).Type()
+		}
+	}
+	if isValidType(got) && !types.AssignableTo(got, want) {
+		s.registrationErrorf(pkg.Fset.Position(constructorFunc.Pos()), "constructor %s returns %s, which is not assignable to %s expected by Register", constructorFunc.Name.Name, got, want)
+	}
+}
+
+// isValidType 报告类型是否为类型检查成功得到的类型
+func isValidType(typ types.Type) bool {
+	basic, ok := typ.(*types.Basic)
+	return !ok || basic.Kind() != types.Invalid
+}
+
+// calleeName 返回被调用函数的名称，支持 f() 与 pkg.f() 两种形式
+func calleeName(call *ast.CallExpr) string {
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		return fun.Name
+	case *ast.SelectorExpr:
+		return fun.Sel.Name
+	}
+	return ""
+}
+
+// findConstructorFunc 根据 Register 的参数，找到对应的构造函数 AST 及其调用表达式。
+func findConstructorFunc(info *types.Info, file *ast.File, arg ast.Expr) (*ast.FuncDecl, *ast.CallExpr) {
+	constructorName, constructorCall := traceConstructorCall(info, file, arg, make(map[types.Object]bool))
+	if constructorName == "" {
+		return nil, nil
+	}
+
+	var constructorFunc *ast.FuncDecl
+	ast.Inspect(file, func(n ast.Node) bool {
+		funcDecl, ok := n.(*ast.FuncDecl)
+		if ok && funcDecl.Name.Name == constructorName {
+			constructorFunc = funcDecl
+			return false
+		}
+		return true
+	})
+
+	return constructorFunc, constructorCall
+}
+
+// traceConstructorCall 返回 expr 最终调用的本包函数名及其调用表达式；expr 为变量时追踪其赋值
+// 调用工厂函数返回的闭包时（如 makeFactory()() 或 build := makeFactory(); build()），追踪到工厂函数本身，
+// 闭包字面量位于其函数体中。注册构造函数本身的分支（如 Register("x", New) 或
+// Register("x", func() adapter.Adapter { return New() })）分别追踪到 New 与闭包的返回值，前者没有调用表达式。
+// seen 记录已追踪的变量，避免 f = f() 这类赋值导致无限递归
+func traceConstructorCall(info *types.Info, file *ast.File, expr ast.Expr, seen map[types.Object]bool) (string, *ast.CallExpr) {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.CallExpr:
+		fun := ast.Unparen(e.Fun)
+		if inner, ok := fun.(*ast.CallExpr); ok {
+			return traceConstructorCall(info, file, inner, seen)
+		}
+		ident := funcIdent(fun)
+		if ident == nil {
+			return "", nil
+		}
+		if _, isVar := info.ObjectOf(ident).(*types.Var); isVar {
+			return traceConstructorCall(info, file, ident, seen)
+		}
+		return ident.Name, e
+	case *ast.Ident:
+		obj := info.ObjectOf(e)
+		if obj == nil || seen[obj] {
+			return "", nil
+		}
+		if _, isFunc := obj.(*types.Func); isFunc {
+			return e.Name, nil
+		}
+		seen[obj] = true
+		if call := assignedCall(info, file, obj); call != nil {
+			return traceConstructorCall(info, file, call, seen)
+		}
+	case *ast.FuncLit:
+		if ret := firstReturn(e.Body); ret != nil && len(ret.Results) > 0 {
+			return traceConstructorCall(info, file, ret.Results[0], seen)
+		}
+	}
+	return "", nil
+}
+
+// firstReturn 返回函数体中第一个 return 语句，不进入嵌套的闭包
+func firstReturn(body *ast.BlockStmt) *ast.ReturnStmt {
+	var found *ast.ReturnStmt
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			if found == nil {
+				found = n
+			}
+			return false
+		}
+		return found == nil
+	})
+	return found
+}
+
+// assignedCall 返回文件中第一个把函数调用结果赋给 obj 的调用表达式
+// 构造函数可能返回多个值，如 a, err := New()，此时只追踪第一个返回值
+func assignedCall(info *types.Info, file *ast.File, obj types.Object) *ast.CallExpr {
+	var found *ast.CallExpr
+	ast.Inspect(file, func(n ast.Node) bool {
+		if found != nil {
+			return false
+		}
+		var lhs []ast.Expr
+		var rhs []ast.Expr
+		switch stmt := n.(type) {
+		case *ast.AssignStmt:
+			lhs, rhs = stmt.Lhs, stmt.Rhs
+		case *ast.ValueSpec:
+			for _, name := range stmt.Names {
+				lhs = append(lhs, name)
+			}
+			rhs = stmt.Values
+		default:
+			return true
+		}
+		if len(lhs) == 0 || len(rhs) != 1 {
+			return true
+		}
+		if lhsIdent, ok := lhs[0].(*ast.Ident); ok && info.ObjectOf(lhsIdent) == obj {
+			if call, ok := rhs[0].(*ast.CallExpr); ok {
+				found = call
+				return false
+			}
+		}
+		return true
+	})
+	return found
+}
+
+// funcIdent 返回被调用的本包函数名，显式给出类型实参的泛型调用（如 New[T]() 或 New[K, V]()）
+// 同样返回函数名；其它形式返回 nil
+func funcIdent(fun ast.Expr) *ast.Ident {
+	switch f := fun.(type) {
+	case *ast.Ident:
+		return f
+	case *ast.IndexExpr:
+		return funcIdent(f.X)
+	case *ast.IndexListExpr:
+		return funcIdent(f.X)
+	}
+	return nil
+}
+
+// findMetadataMethod 当 Register 的参数类型在当前包中声明了 Metadata() 方法时，返回该方法的声明
+// 通过嵌入 adapter.Base 等外部类型继承的方法不会被返回
+func findMetadataMethod(pkg *packages.Package, arg ast.Expr) *ast.FuncDecl {
+	typ := pkg.TypesInfo.TypeOf(arg)
+	if typ == nil {
+		return nil
+	}
+
+	obj, _, _ := types.LookupFieldOrMethod(typ, true, pkg.Types, "Metadata")
+	method, ok := obj.(*types.Func)
+	if !ok || method.Pkg() != pkg.Types {
+		return nil
+	}
+	// 泛型类型实例化后的方法是新的对象，声明处记录的是未实例化的原始方法
+	return findFuncDecl(pkg, method.Origin())
+}
+
+// findFuncDecl 返回本包中 fn 的带函数体的声明，找不到时返回 nil
+func findFuncDecl(pkg *packages.Package, fn *types.Func) *ast.FuncDecl {
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if ok && funcDecl.Body != nil && pkg.TypesInfo.Defs[funcDecl.Name] == fn {
+				return funcDecl
+			}
+		}
+	}
+	return nil
+}
+
+// findMetadataInReturns 在构造函数的返回值中寻找元数据，用于返回类型为接口、元数据在调用方法或闭包时才构建的构造函数：
+// 返回值的具体类型在本包中声明了 Metadata() 方法时在该方法中寻找，返回值引用了本包的函数
+// （如 &Lazy{Build: buildMetadata}）时在被引用的函数中寻找
+func (s *scanner) findMetadataInReturns(pkg *packages.Package, constructorFunc *ast.FuncDecl) (*Result, token.Pos) {
+	var candidates []*ast.FuncDecl
+	ast.Inspect(constructorFunc.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			// 闭包中的 return 不是构造函数的返回值
+			return false
+		case *ast.ReturnStmt:
+			if len(n.Results) == 0 {
+				return true
+			}
+			result := n.Results[0]
+			if method := findMetadataMethod(pkg, result); method != nil {
+				candidates = append(candidates, method)
+			}
+			ast.Inspect(result, func(n ast.Node) bool {
+				ident, ok := n.(*ast.Ident)
+				if !ok {
+					return true
+				}
+				if fn, ok := pkg.TypesInfo.Uses[ident].(*types.Func); ok && fn.Pkg() == pkg.Types {
+					if decl := findFuncDecl(pkg, fn.Origin()); decl != nil {
+						candidates = append(candidates, decl)
+					}
+				}
+				return true
+			})
+		}
+		return true
+	})
+
+	for _, decl := range candidates {
+		params := packageVars(pkg)
+		s.bindForeignVars(pkg, decl.Body, params)
+		s.bindEnumStrings(pkg, decl.Body, params)
+		if meta, pos := findMetadataInFuncBody(pkg.TypesInfo, decl.Body, params, s.opts.metadataType()); meta != nil {
+			s.tracef("%s: Metadata found in %s returned by constructor %s", pkg.PkgPath, decl.Name.Name, constructorFunc.Name.Name)
+			return meta, pos
+		}
+	}
+	return nil, token.NoPos
+}
+
+// findMetadataInFuncBody 在任意函数体中寻找类型名称以 typeName 结尾的结构体字面量，并返回字面量所在位置
+// params 为构造函数形参到实参的绑定，可以为 nil
+func findMetadataInFuncBody(info *types.Info, body *ast.BlockStmt, params bindings, typeName string) (*Result, token.Pos) {
+	var foundMeta *Result
+	var foundPos token.Pos
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		compLit, ok := n.(*ast.CompositeLit)
+		if !ok {
+			return true
+		}
+
+		if typ := info.TypeOf(compLit); typ != nil {
+			if strings.HasSuffix(typeString(typ), typeName) {
+				meta := parseCompositeLit(info, compLit, params)
+				if meta != nil {
+					foundMeta = meta
+					foundPos = compLit.Pos()
+					return false
+				}
+			}
+		}
+		return true
+	})
+
+	return foundMeta, foundPos
+}
+
+// typeString 返回类型的完整名称；实例化的泛型类型去掉类型实参，
+// 使 Meta[T] 与 Meta[string] 都能按 --metadata-type 给出的名称 Meta 匹配
+func typeString(typ types.Type) string {
+	if named, ok := typ.(*types.Named); ok && named.TypeArgs().Len() > 0 {
+		if obj := named.Obj(); obj.Pkg() != nil {
+			return obj.Pkg().Path() + "." + obj.Name()
+		}
+	}
+	return typ.String()
+}
+
+// parseCompositeLit 解析结构体字面量，提取键值对
+func parseCompositeLit(info *types.Info, expr ast.Expr, params bindings) *Result {
+	compLit, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return nil
+	}
+
+	var result Result
+	applyCompositeLit(info, compLit, params, &result)
+	if result.Metadata.Id == "" {
+		return nil
+	}
+	// 嵌入的基础结构体可能补上了直接声明处无法求值的字段
+	meta := reflect.ValueOf(result.Metadata)
+	var unresolved []unresolvedExpr
+	for _, u := range result.unresolved {
+		if meta.FieldByIndex(metadataFields[u.field]).IsZero() {
+			unresolved = append(unresolved, u)
+		}
+	}
+	result.unresolved = unresolved
+	return &result
+}
+
+// metadataFields adapter.Metadata 中可以从字面量提取的字段，键为 Go 字段名，值为反射用的字段下标
+// 表由编译时依赖的 adapter.Metadata 生成，其中的字符串（包括 AdapterType 等具名字符串类型）、
+// 布尔与字符串切片字段都会被提取，因此元数据结构体新增这类字段后无需修改扫描器
+var metadataFields = func() map[string][]int {
+	fields := make(map[string][]int)
+	for _, field := range reflect.VisibleFields(reflect.TypeOf(adapter.Metadata{})) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		switch field.Type.Kind() {
+		case reflect.String, reflect.Bool:
+		case reflect.Slice:
+			if field.Type.Elem().Kind() != reflect.String {
+				continue
+			}
+		default:
+			continue
+		}
+		fields[field.Name] = field.Index
+	}
+	return fields
+}()
+
+// applyCompositeLit 将结构体字面量中的字段写入 result
+// 嵌入的结构体字段（如 BaseMeta: common）会在直接声明的字段之后展开，且不会覆盖已有的值，
+// 因此直接声明的字段优先于共享的基础结构体
+func applyCompositeLit(info *types.Info, compLit *ast.CompositeLit, params bindings, result *Result) {
+	meta := reflect.ValueOf(&result.Metadata).Elem()
+
+	var embedded []*ast.CompositeLit
+	for _, el := range compLit.Elts {
+		kv, ok := el.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			continue
+		}
+
+		// 功能开关与本地化标题不属于 adapter.Metadata，单独保存在 Result 中
+		if key.Name == "Capabilities" {
+			if result.Capabilities == nil {
+				result.Capabilities = parseBoolMap(info, kv.Value, params)
+			}
+			continue
+		}
+		if key.Name == "Titles" {
+			if result.Titles == nil {
+				result.Titles = parseStringMap(info, kv.Value, params)
+			}
+			continue
+		}
+
+		if isEmbeddedField(info, key) {
+			if lit := resolveCompositeLit(info, kv.Value, params); lit != nil {
+				embedded = append(embedded, lit)
+			}
+			continue
+		}
+
+		index, ok := metadataFields[key.Name]
+		if !ok {
+			continue
+		}
+		if field := meta.FieldByIndex(index); field.IsZero() {
+			if !setFieldValue(info, field, kv.Value, params) {
+				result.unresolved = append(result.unresolved, unresolvedExpr{field: key.Name, expr: kv.Value})
+			}
+		}
+	}
+
+	for _, lit := range embedded {
+		applyCompositeLit(info, lit, params, result)
+	}
+}
+
+// setFieldValue 按字段的类型对表达式求值并写入 field，无法静态求值时保持零值
+// 只有字符串字段会报告无法求值（返回 false），布尔与切片字段始终返回 true
+func setFieldValue(info *types.Info, field reflect.Value, expr ast.Expr, params bindings) bool {
+	switch field.Kind() {
+	case reflect.String:
+		value, ok := resolveExprValue(info, expr, params)
+		field.SetString(value)
+		return ok
+	case reflect.Bool:
+		if value, ok := getBoolValue(info, resolveBoundExpr(info, expr, params)); ok {
+			field.SetBool(value)
+		}
+	case reflect.Slice:
+		if values := parseStringSlice(info, expr, params); values != nil {
+			field.Set(reflect.ValueOf(values).Convert(field.Type()))
+		}
+	}
+	return true
+}
+
+// resolveBoundExpr 将引用构造函数形参或包级变量的标识符替换为其绑定的表达式
+func resolveBoundExpr(info *types.Info, expr ast.Expr, params bindings) ast.Expr {
+	if ident, ok := expr.(*ast.Ident); ok {
+		if obj := info.ObjectOf(ident); obj != nil {
+			if bound, ok := params[obj]; ok {
+				return bound
+			}
+		}
+	}
+	return expr
+}
+
+// isEmbeddedField 判断结构体字面量的键是否为嵌入字段
+func isEmbeddedField(info *types.Info, key ast.Expr) bool {
+	ident, ok := key.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	field, ok := info.ObjectOf(ident).(*types.Var)
+	return ok && field.Embedded()
+}
+
+// resolveCompositeLit 返回表达式对应的结构体字面量，支持直接的字面量、取地址以及引用包级变量
+func resolveCompositeLit(info *types.Info, expr ast.Expr, params bindings) *ast.CompositeLit {
+	switch e := expr.(type) {
+	case *ast.CompositeLit:
+		return e
+	case *ast.UnaryExpr:
+		if e.Op == token.AND {
+			return resolveCompositeLit(info, e.X, params)
+		}
+	case *ast.Ident:
+		if obj := info.ObjectOf(e); obj != nil {
+			if bound, ok := params[obj]; ok {
+				// 绑定的表达式不再套用绑定，避免变量之间的循环引用
+				return resolveCompositeLit(info, bound, nil)
+			}
+		}
+	}
+	return nil
+}
+
+// fieldValue 在结构体字面量中查找指定字段的值表达式
+func fieldValue(compLit *ast.CompositeLit, name string) ast.Expr {
+	for _, el := range compLit.Elts {
+		if kv, ok := el.(*ast.KeyValueExpr); ok {
+			if ident, ok := kv.Key.(*ast.Ident); ok && ident.Name == name {
+				return kv.Value
+			}
+		}
+	}
+	return nil
+}
+
+// parseBoolMap 解析 map[string]bool 字面量，无法静态求值的键值对会被忽略
+func parseBoolMap(info *types.Info, expr ast.Expr, params bindings) map[string]bool {
+	compLit := resolveCompositeLit(info, expr, params)
+	if compLit == nil {
+		return nil
+	}
+
+	values := make(map[string]bool)
+	for _, el := range compLit.Elts {
+		kv, ok := el.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key := getExprValue(info, kv.Key, params)
+		value, ok := getBoolValue(info, kv.Value)
+		if key == "" || !ok {
+			continue
+		}
+		values[key] = value
+	}
+	return values
+}
+
+// parseStringMap 解析 map[string]string 字面量，无法静态求值的键值对会被忽略
+func parseStringMap(info *types.Info, expr ast.Expr, params bindings) map[string]string {
+	compLit := resolveCompositeLit(info, expr, params)
+	if compLit == nil {
+		return nil
+	}
+
+	values := make(map[string]string)
+	for _, el := range compLit.Elts {
+		kv, ok := el.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key := getExprValue(info, kv.Key, params)
+		value := getExprValue(info, kv.Value, params)
+		if key == "" || value == "" {
+			continue
+		}
+		values[key] = value
+	}
+	return values
+}
+
+// parseStringSlice 解析 []string 字面量，无法静态求值的元素会被忽略
+func parseStringSlice(info *types.Info, expr ast.Expr, params bindings) []string {
+	compLit := resolveCompositeLit(info, expr, params)
+	if compLit == nil {
+		return nil
+	}
+
+	values := []string{}
+	for _, el := range compLit.Elts {
+		if value := getExprValue(info, el, params); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getBoolValue 提取布尔常量（包括 true/false 与具名布尔常量）的值
+func getBoolValue(info *types.Info, expr ast.Expr) (bool, bool) {
+	tv, ok := info.Types[expr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.Bool {
+		return false, false
+	}
+	return constant.BoolVal(tv.Value), true
+}
+
+// constantString 返回字符串常量的值；底层类型为 string 的具名类型常量同样是字符串常量，
+// 其它种类的常量（如整数）与 nil 返回 false，而不是让 constant.StringVal panic
+func constantString(value constant.Value) (string, bool) {
+	if value == nil || value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(value), true
+}
+
+// unresolvedReason 描述表达式为何无法被静态求值，用于 --trace 与 --strict-fields 的诊断信息
+func unresolvedReason(info *types.Info, expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.CallExpr:
+		return "a function call, which is only evaluated at run time"
+	case *ast.Ident:
+		if _, ok := info.ObjectOf(e).(*types.Var); ok {
+			return "a variable without a statically known string value"
+		}
+	case *ast.SelectorExpr:
+		if _, ok := info.ObjectOf(e.Sel).(*types.Var); ok {
+			return "a variable or struct field without a statically known string value"
+		}
+	case *ast.BinaryExpr:
+		return "a non-constant expression"
+	case *ast.IndexExpr:
+		return "an index expression"
+	}
+	if tv, ok := info.Types[expr]; ok && tv.Value != nil && tv.Value.Kind() != constant.String {
+		return "a constant that is not a string"
+	}
+	return fmt.Sprintf("an unsupported expression (%T)", expr)
+}
+
+// getExprValue 从 AST 节点中提取常量或字符串字面量的值，无法静态求值时返回空字符串
+// 引用构造函数形参的表达式会被替换为 params 中绑定的实参再求值
+func getExprValue(info *types.Info, expr ast.Expr, params bindings) string {
+	value, _ := resolveExprValue(info, expr, params)
+	return value
+}
+
+// resolveExprValue 与 getExprValue 相同，但额外报告表达式能否静态求值，
+// 以区分有意写出的空字符串与无法求值的表达式
+func resolveExprValue(info *types.Info, expr ast.Expr, params bindings) (string, bool) {
+	// 类型检查器已求出值的常量表达式，包括具名字符串类型的常量、类型转换与常量拼接，
+	// 如 Type: SourceType、adapter.AdapterType("source") 或 Prefix + "-v2"
+	if value, ok := constantString(info.Types[expr].Value); ok {
+		return value, true
+	}
+
+	if basicLit, ok := expr.(*ast.BasicLit); ok && basicLit.Kind == token.STRING {
+		value, err := strconv.Unquote(basicLit.Value)
+		return value, err == nil
+	}
+
+	if ident, ok := expr.(*ast.Ident); ok {
+		if obj := info.ObjectOf(ident); obj != nil {
+			if arg, bound := params[obj]; bound {
+				// 实参位于调用方，求值时不再套用绑定
+				return resolveExprValue(info, arg, nil)
+			}
+			if cnst, ok := obj.(*types.Const); ok {
+				return constantString(cnst.Val())
+			}
+		}
+	}
+
+	if selExpr, ok := expr.(*ast.SelectorExpr); ok {
+		if obj := info.ObjectOf(selExpr.Sel); obj != nil {
+			if cnst, ok := obj.(*types.Const); ok {
+				return constantString(cnst.Val())
+			}
+			// 其他包的变量，由 bindForeignVars 绑定为其值
+			if arg, bound := params[obj]; bound {
+				return resolveExprValue(info, arg, nil)
+			}
+		}
+		// 读取包级结构体变量的字段，如 common.Author
+		if lit := resolveCompositeLit(info, selExpr.X, params); lit != nil {
+			if value := fieldValue(lit, selExpr.Sel.Name); value != nil {
+				return resolveExprValue(info, value, nil)
+			}
+		}
+	}
+
+	if call, ok := expr.(*ast.CallExpr); ok {
+		// 类型转换，如 adapter.AdapterType(kind.String())
+		if tv, ok := info.Types[call.Fun]; ok && tv.IsType() && len(call.Args) == 1 {
+			return resolveExprValue(info, call.Args[0], params)
+		}
+		// 枚举常量的 String()，接收者由 bindEnumStrings 绑定为其结果
+		if recv, ok := stringCall(call); ok {
+			if obj := receiverObject(info, recv); obj != nil {
+				if result, bound := params[obj]; bound {
+					return resolveExprValue(info, result, nil)
+				}
+			}
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,126 @@
+package metascan
+
+import (
+	"bufio"
+	"fmt"
+	"go/token"
+	"go/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/meloshub/meloshub-tools/diagnostics"
+	"golang.org/x/tools/go/gcexportdata"
+)
+
+var (
+	loaderOnce sync.Once
+	loaderErr  error
+)
+
+// requireLoader 在当前 go 命令生成的导出数据无法被 golang.org/x/tools 读取时跳过测试：
+// 此时 packages.Load 会直接以 log.Fatal 退出，整个测试进程都会中止
+func requireLoader(t *testing.T) {
+	t.Helper()
+	loaderOnce.Do(func() {
+		out, err := exec.Command("go", "list", "-export", "-f", "{{.Export}}", "github.com/meloshub/meloshub/adapter").Output()
+		if err != nil {
+			loaderErr = fmt.Errorf("go list failed: %w", err)
+			return
+		}
+		f, err := os.Open(strings.TrimSpace(string(out)))
+		if err != nil {
+			loaderErr = err
+			return
+		}
+		defer f.Close()
+		r, err := gcexportdata.NewReader(bufio.NewReader(f))
+		if err == nil {
+			_, err = gcexportdata.Read(r, token.NewFileSet(), make(map[string]*types.Package), "github.com/meloshub/meloshub/adapter")
+		}
+		loaderErr = err
+	})
+	if loaderErr != nil {
+		t.Skipf("the go command's export data cannot be read by golang.org/x/tools: %v", loaderErr)
+	}
+}
+
+// fixtureDir 返回 testdata 下夹具目录的绝对路径；夹具是主模块中的普通包，
+// 位于 testdata 下因而不参与 go build ./... 与 go vet ./...
+func fixtureDir(t *testing.T, name string) string {
+	t.Helper()
+	dir, err := filepath.Abs(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// scanFixture 扫描夹具目录，返回扫描结果与扫描过程中报告的诊断；扫描出错时测试失败
+func scanFixture(t *testing.T, name string, opts Options) ([]Result, []diagnostics.Entry) {
+	t.Helper()
+	requireLoader(t)
+	diags := &diagnostics.Diagnostics{}
+	opts.Diagnostics = diags
+	opts.Quiet = true
+	results, err := Scan(fixtureDir(t, name), opts)
+	if err != nil {
+		t.Fatalf("Scan(%s) failed: %v", name, err)
+	}
+	return results, diags.Entries()
+}
+
+// resultsById 按 Id 索引扫描结果，Id 重复时测试失败
+func resultsById(t *testing.T, results []Result) map[string]Result {
+	t.Helper()
+	byId := make(map[string]Result, len(results))
+	for _, result := range results {
+		if _, ok := byId[result.Metadata.Id]; ok {
+			t.Fatalf("adapter %q was found more than once", result.Metadata.Id)
+		}
+		byId[result.Metadata.Id] = result
+	}
+	return byId
+}
+
+// sortedIds 返回扫描结果中排序后的 Id
+func sortedIds(results []Result) []string {
+	ids := make([]string, 0, len(results))
+	for _, result := range results {
+		ids = append(ids, result.Metadata.Id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// wantIds 检查扫描结果恰好包含 want 中的 Id
+func wantIds(t *testing.T, results []Result, want ...string) {
+	t.Helper()
+	sort.Strings(want)
+	if got := sortedIds(results); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("found adapters %v, want %v", got, want)
+	}
+}
+
+// wantNoDiagnostics 检查扫描没有报告任何警告
+func wantNoDiagnostics(t *testing.T, entries []diagnostics.Entry) {
+	t.Helper()
+	for _, e := range entries {
+		t.Errorf("unexpected %s: %s", e.Severity, e.Message)
+	}
+}
+
+// wantDiagnostic 检查扫描报告了消息中包含 substr 的诊断
+func wantDiagnostic(t *testing.T, entries []diagnostics.Entry, substr string) {
+	t.Helper()
+	for _, e := range entries {
+		if strings.Contains(e.Message, substr) {
+			return
+		}
+	}
+	t.Errorf("no diagnostic contains %q; got %v", substr, entries)
+}
@@ -0,0 +1,21 @@
+package metascan
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestScanMetadataMethod(t *testing.T) {
+	results, entries := scanFixture(t, "method", Options{})
+	wantNoDiagnostics(t, entries)
+	wantIds(t, results, "literal", "ctor")
+
+	byId := resultsById(t, results)
+	// literal 的 Metadata 方法声明在包内的另一个文件中
+	if got := filepath.Base(byId["literal"].Pos.Filename); got != "metadata.go" {
+		t.Errorf("literal position is in %s, want metadata.go", got)
+	}
+	if got := byId["ctor"].Metadata.Version; got != "1.2.0" {
+		t.Errorf("ctor Version = %q, want 1.2.0 from the value receiver method", got)
+	}
+}
@@ -0,0 +1,21 @@
+package metascan
+
+import "testing"
+
+func TestScanRecordsModule(t *testing.T) {
+	results, entries := scanFixture(t, "modversion", Options{})
+	wantNoDiagnostics(t, entries)
+	wantIds(t, results, "buildinfo")
+
+	result := results[0]
+	if result.Metadata.Version != "" {
+		t.Errorf("Version = %q, want it left empty", result.Metadata.Version)
+	}
+	if pending := result.PendingFields(); len(pending) != 1 || pending[0].Field != "Version" {
+		t.Errorf("pending fields = %+v, want only Version", pending)
+	}
+	// --version-from-module 依据 Module 补上版本
+	if result.Module == nil || result.Module.Path != "github.com/meloshub/meloshub-tools" || !result.Module.Main {
+		t.Errorf("Module = %+v, want the main module", result.Module)
+	}
+}
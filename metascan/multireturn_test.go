@@ -0,0 +1,17 @@
+package metascan
+
+import "testing"
+
+func TestScanMultiValueConstructors(t *testing.T) {
+	results, entries := scanFixture(t, "multireturn", Options{})
+	wantNoDiagnostics(t, entries)
+	wantIds(t, results, "assign", "varspec")
+
+	byId := resultsById(t, results)
+	if got := byId["assign"].Metadata.Title; got != "Assign" {
+		t.Errorf("assign Title = %q, want the literal from the constructor returning (adapter, error)", got)
+	}
+	if got := byId["varspec"].Metadata.Version; got != "2.0.0" {
+		t.Errorf("varspec Version = %q, want 2.0.0 from the var declaration's first value", got)
+	}
+}
@@ -0,0 +1,27 @@
+package metascan
+
+import "testing"
+
+func TestScanNamedStringConstants(t *testing.T) {
+	results, entries := scanFixture(t, "namedconst", Options{})
+	wantNoDiagnostics(t, entries)
+	wantIds(t, results, "source", "converted", "builtin")
+
+	byId := resultsById(t, results)
+	tests := []struct{ id, field, got, want string }{
+		{"source", "Type", string(byId["source"].Metadata.Type), "source"},
+		{"converted", "Type", string(byId["converted"].Metadata.Type), "official"},
+		{"converted", "Title", byId["converted"].Metadata.Title, "beta"},
+		{"builtin", "Type", string(byId["builtin"].Metadata.Type), "official"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s %s = %q, want %q", tt.id, tt.field, tt.got, tt.want)
+		}
+	}
+	for id, result := range byId {
+		if len(result.Unresolved) != 0 {
+			t.Errorf("%s has unresolved fields %+v", id, result.Unresolved)
+		}
+	}
+}
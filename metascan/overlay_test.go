@@ -0,0 +1,44 @@
+package metascan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScanWithOverlay(t *testing.T) {
+	requireLoader(t)
+	dir := fixtureDir(t, "overlay")
+	path := filepath.Join(dir, "simple", "simple.go")
+	src, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 在构造函数之前插入两行，检查位置指向 overlay 中的内容而不是磁盘上的文件
+	edited := strings.Replace(string(src), `Version: "1.0.0"`, `Version: "2.0.0-rc.1"`, 1)
+	edited = strings.Replace(edited, "func New()", "// unsaved\n// edit\nfunc New()", 1)
+
+	onDisk, _ := scanFixture(t, "overlay", Options{})
+	results, err := ScanWithOverlay(dir, map[string][]byte{path: []byte(edited)}, Options{Quiet: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(onDisk) != 1 || len(results) != 1 {
+		t.Fatalf("found %d adapters on disk and %d with the overlay, want 1 each", len(onDisk), len(results))
+	}
+	if got := onDisk[0].Metadata.Version; got != "1.0.0" {
+		t.Errorf("on-disk Version = %q, want 1.0.0", got)
+	}
+	if got := results[0].Metadata.Version; got != "2.0.0-rc.1" {
+		t.Errorf("overlay Version = %q, want 2.0.0-rc.1", got)
+	}
+	if got, want := results[0].Pos.Line, onDisk[0].Pos.Line+2; got != want {
+		t.Errorf("overlay Pos line = %d, want %d", got, want)
+	}
+
+	// overlay 不会写入磁盘
+	if data, err := os.ReadFile(path); err != nil || string(data) != string(src) {
+		t.Errorf("the overlay changed %s on disk", path)
+	}
+}
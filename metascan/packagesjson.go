@@ -0,0 +1,192 @@
+package metascan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/gcexportdata"
+	"golang.org/x/tools/go/packages"
+)
+
+// listedPackage go list -json 输出中扫描所需的字段
+type listedPackage struct {
+	ImportPath string
+	Name       string
+	Dir        string
+	GoFiles    []string
+	Export     string
+	ImportMap  map[string]string
+	Standard   bool
+	DepOnly    bool
+	Module     *packages.Module
+}
+
+// ScanPackagesJSON 根据预先生成的 go list -json 输出扫描适配器，不再调用 packages.Load
+// 文件通常由 go list -json -deps -export ./... 生成；带有 Export 字段的依赖会直接读取其导出数据，
+// 缺少导出数据的依赖则回退到从源码进行类型检查。
+// 只有在文件由 go list -test 生成时才会包含测试变体，是否扫描它们同样由 opts.IncludeTests 决定
+func ScanPackagesJSON(path string, opts Options) ([]Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open packages file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	// go list -json 输出的是连续的 JSON 对象而不是数组
+	var listed []listedPackage
+	decoder := json.NewDecoder(f)
+	for {
+		var lp listedPackage
+		if err := decoder.Decode(&lp); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("could not parse packages file %s: %w", path, err)
+		}
+		listed = append(listed, lp)
+	}
+
+	fset := token.NewFileSet()
+	imp := newExportImporter(fset, listed)
+
+	s := &scanner{opts: opts}
+	var pkgs []*packages.Package
+	for _, lp := range listed {
+		// 生成的测试主包只包含测试入口，类型检查时也无法解析其对测试变体的导入
+		if lp.Standard || lp.DepOnly || strings.HasSuffix(lp.ImportPath, ".test") {
+			continue
+		}
+		pkg, err := s.typeCheckListed(fset, imp, lp)
+		if err != nil {
+			return nil, err
+		}
+		pkgs = append(pkgs, pkg)
+	}
+
+	results := s.scanPackages(pkgs)
+	return results, errors.Join(s.errs...)
+}
+
+// typeCheckListed 解析并类型检查 go list 描述的单个包，构造扫描所需的 packages.Package
+func (s *scanner) typeCheckListed(fset *token.FileSet, imp *exportImporter, lp listedPackage) (*packages.Package, error) {
+	// go list -test 输出的测试变体 ImportPath 形如 "p [p.test]"，与 packages.Load 的 ID 一致
+	pkgPath, _, _ := strings.Cut(lp.ImportPath, " [")
+	pkg := &packages.Package{
+		ID:      lp.ImportPath,
+		Name:    lp.Name,
+		PkgPath: pkgPath,
+		Module:  lp.Module,
+		Fset:    fset,
+		// 与 packages.Load 的 NeedTypesInfo 填充相同的映射；泛型构造函数的检查需要 Instances 中实例化后的签名
+		TypesInfo: &types.Info{
+			Types:        make(map[ast.Expr]types.TypeAndValue),
+			Defs:         make(map[*ast.Ident]types.Object),
+			Uses:         make(map[*ast.Ident]types.Object),
+			Implicits:    make(map[ast.Node]types.Object),
+			Instances:    make(map[*ast.Ident]types.Instance),
+			Scopes:       make(map[ast.Node]*types.Scope),
+			Selections:   make(map[*ast.SelectorExpr]*types.Selection),
+			FileVersions: make(map[*ast.File]string),
+		},
+	}
+
+	for _, name := range lp.GoFiles {
+		// 生成的文件（如测试主包的 _testmain.go）以位于构建缓存中的绝对路径给出
+		filename := name
+		if !filepath.IsAbs(filename) {
+			filename = filepath.Join(lp.Dir, name)
+		}
+		file, err := parser.ParseFile(fset, filename, nil, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse %s: %w", filename, err)
+		}
+		pkg.GoFiles = append(pkg.GoFiles, filename)
+		pkg.Syntax = append(pkg.Syntax, file)
+	}
+
+	cfg := &types.Config{
+		Importer: imp.forPackage(lp),
+		// 与 packages.Load 一样容忍类型错误，尽可能保留已得到的类型信息
+		Error: func(err error) {
+			var pos token.Position
+			if typeErr, ok := err.(types.Error); ok {
+				pos = typeErr.Fset.Position(typeErr.Pos)
+			}
+			s.warnf(pos, "type error in %s: %v", lp.ImportPath, err)
+		},
+	}
+	pkg.Types, _ = cfg.Check(pkgPath, fset, pkg.Syntax, pkg.TypesInfo)
+	return pkg, nil
+}
+
+// exportImporter 优先使用 go list 提供的导出数据导入依赖包
+type exportImporter struct {
+	fset     *token.FileSet
+	exports  map[string]string
+	imported map[string]*types.Package
+	fallback types.Importer
+}
+
+func newExportImporter(fset *token.FileSet, listed []listedPackage) *exportImporter {
+	imp := &exportImporter{
+		fset:     fset,
+		exports:  make(map[string]string),
+		imported: make(map[string]*types.Package),
+		fallback: importer.ForCompiler(fset, "source", nil),
+	}
+	for _, lp := range listed {
+		if lp.Export != "" {
+			imp.exports[lp.ImportPath] = lp.Export
+		}
+	}
+	return imp
+}
+
+// forPackage 返回处理 lp 中导入路径映射（如 vendor 目录）的导入器
+func (imp *exportImporter) forPackage(lp listedPackage) types.Importer {
+	return importerFunc(func(path string) (*types.Package, error) {
+		if mapped, ok := lp.ImportMap[path]; ok {
+			path = mapped
+		}
+		return imp.importPath(path)
+	})
+}
+
+func (imp *exportImporter) importPath(path string) (*types.Package, error) {
+	if path == "unsafe" {
+		return types.Unsafe, nil
+	}
+	if pkg, ok := imp.imported[path]; ok && pkg.Complete() {
+		return pkg, nil
+	}
+
+	exportFile, ok := imp.exports[path]
+	if !ok {
+		return imp.fallback.Import(path)
+	}
+
+	f, err := os.Open(exportFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not open export data for %s: %w", path, err)
+	}
+	defer f.Close()
+
+	r, err := gcexportdata.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("could not read export data for %s: %w", path, err)
+	}
+	return gcexportdata.Read(r, imp.fset, imp.imported, path)
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
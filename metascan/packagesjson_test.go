@@ -0,0 +1,47 @@
+package metascan
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// writePackagesJSON 在夹具目录中运行 go list -json -deps，将输出写入临时文件
+// 不带 -export，使依赖从源码进行类型检查，测试不受 gcexportdata 所支持的导出数据版本影响
+func writePackagesJSON(t *testing.T, name string) string {
+	t.Helper()
+	cmd := exec.Command("go", "list", "-json", "-deps", "./...")
+	cmd.Dir = fixtureDir(t, name)
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("go list failed: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "packages.json")
+	if err := os.WriteFile(path, out, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestScanPackagesJSONGenericConstructors(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not available")
+	}
+	fromLoad, loadDiags := scanFixture(t, "generic", Options{})
+	wantNoDiagnostics(t, loadDiags)
+
+	var warnings []string
+	results, err := ScanPackagesJSON(writePackagesJSON(t, "generic"), Options{
+		Quiet:     true,
+		OnWarning: func(w Warning) { warnings = append(warnings, w.Message) },
+	})
+	if err != nil {
+		t.Fatalf("ScanPackagesJSON failed: %v", err)
+	}
+	// 实例化后的签名来自 TypesInfo.Instances，缺少时泛型构造函数会被误报为类型不匹配
+	for _, w := range warnings {
+		t.Errorf("unexpected warning: %s", w)
+	}
+	wantIds(t, results, sortedIds(fromLoad)...)
+}
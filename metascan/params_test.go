@@ -0,0 +1,24 @@
+package metascan
+
+import (
+	"testing"
+
+	"github.com/meloshub/meloshub/adapter"
+)
+
+func TestScanParameterizedConstructor(t *testing.T) {
+	results, entries := scanFixture(t, "params", Options{})
+	wantNoDiagnostics(t, entries)
+	wantIds(t, results, "spotify", "deezer", "tidal")
+
+	want := map[string]adapter.Metadata{
+		"spotify": {Id: "spotify", Title: "Spotify", Type: adapter.TypeOfficial, Version: "1.0.0", Author: "Streaming Team"},
+		"deezer":  {Id: "deezer", Title: "Deezer", Type: adapter.TypeCommunity, Version: "1.0.0", Author: "Streaming Team"},
+		"tidal":   {Id: "tidal", Title: "Tidal", Type: adapter.TypeCommunity, Version: "1.0.0", Author: "Streaming Team"},
+	}
+	for id, result := range resultsById(t, results) {
+		if result.Metadata != want[id] {
+			t.Errorf("%s = %+v, want %+v", id, result.Metadata, want[id])
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package metascan
+
+import (
+	"os"
+	"testing"
+)
+
+func TestScanPlatformEnv(t *testing.T) {
+	tests := []struct {
+		goos string
+		want []string
+	}{
+		{"linux", []string{"common", "alsa"}},
+		{"darwin", []string{"common", "coreaudio"}},
+		{"windows", []string{"common"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			env := append(os.Environ(), "GOOS="+tt.goos, "GOARCH=amd64")
+			results, entries := scanFixture(t, "platforms", Options{Env: env})
+			wantNoDiagnostics(t, entries)
+			wantIds(t, results, tt.want...)
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package metascan
+
+import "testing"
+
+func TestScanRegisterArgIndex(t *testing.T) {
+	// 默认追踪第一个参数，即注册名称，无法找到构造函数
+	results, entries := scanFixture(t, "registerarg", Options{})
+	wantIds(t, results)
+	wantDiagnostic(t, entries, "registerarg/one/one.go, but could not trace its constructor function")
+
+	results, entries = scanFixture(t, "registerarg", Options{RegisterArgIndex: 1})
+	wantIds(t, results, "one", "two")
+	byId := resultsById(t, results)
+	// 第二个参数可以是构造函数本身，也可以是调用构造函数的函数字面量
+	if got := byId["two"].Metadata.Version; got != "2.0.0" {
+		t.Errorf("two Version = %q, want %q", got, "2.0.0")
+	}
+	if len(entries) != 1 {
+		t.Errorf("diagnostics = %v, want only the one for the nil constructor", entries)
+	}
+	wantDiagnostic(t, entries, "registerarg/three/three.go, but could not trace its constructor function")
+}
+
+func TestScanRegisterArgIndexOutOfRange(t *testing.T) {
+	results, entries := scanFixture(t, "registerarg", Options{RegisterArgIndex: 2})
+	wantIds(t, results)
+	if len(entries) != 3 {
+		t.Errorf("diagnostics = %v, want one per Register call", entries)
+	}
+	wantDiagnostic(t, entries, "registerarg/one/one.go:7:15 has 2 arguments, but the register argument index is 2")
+}
@@ -0,0 +1,59 @@
+package metascan
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go/ast"
+	"go/printer"
+	goscanner "go/scanner"
+	"go/token"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// sourceHash 返回包含 pos 的顶层声明（构造函数、Metadata 方法或包级变量）的 SHA-256，十六进制编码
+// 哈希针对声明的词法单元序列，不包含注释与空白，因此只改动格式或注释不会改变结果；找不到声明时返回空字符串
+func sourceHash(pkg *packages.Package, pos token.Pos) string {
+	decl := enclosingDecl(pkg, pos)
+	if decl == nil {
+		return ""
+	}
+	// 打印出的声明仍带有文档注释，且注释所占的行会变成空行，因此再按词法单元计算
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, pkg.Fset, decl); err != nil {
+		return ""
+	}
+	src := buf.Bytes()
+	var s goscanner.Scanner
+	s.Init(token.NewFileSet().AddFile("", -1, len(src)), src, nil, 0)
+	h := sha256.New()
+	for {
+		_, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		// 自动插入的分号的字面值为换行符，与显式写出的分号视为相同
+		if !tok.IsLiteral() {
+			lit = ""
+		}
+		fmt.Fprintf(h, "%s %s\n", tok, lit)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// enclosingDecl 在包的所有文件中寻找包含 pos 的顶层声明
+func enclosingDecl(pkg *packages.Package, pos token.Pos) ast.Decl {
+	for _, file := range pkg.Syntax {
+		if pos < file.Pos() || pos >= file.End() {
+			continue
+		}
+		for _, decl := range file.Decls {
+			if decl.Pos() <= pos && pos < decl.End() {
+				return decl
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package metascan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSourceHashStability(t *testing.T) {
+	requireLoader(t)
+	dir := fixtureDir(t, "overlay")
+	path := filepath.Join(dir, "simple", "simple.go")
+	src, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// hashOf 扫描以 edit 修改后的源码，返回唯一适配器的元数据版本与源码哈希
+	hashOf := func(edit func(src string) string) (string, string) {
+		t.Helper()
+		results, err := ScanWithOverlay(dir, map[string][]byte{path: []byte(edit(string(src)))}, Options{Quiet: true, SourceHash: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != 1 {
+			t.Fatalf("found %d adapters, want 1", len(results))
+		}
+		return results[0].Metadata.Version, results[0].SourceHash
+	}
+	unchanged := func(src string) string { return src }
+
+	_, first := hashOf(unchanged)
+	if len(first) != 64 {
+		t.Fatalf("SourceHash = %q, want 64 hex digits", first)
+	}
+	if _, again := hashOf(unchanged); again != first {
+		t.Errorf("second scan of unchanged source: hash %s, want %s", again, first)
+	}
+
+	// 只改动注释、格式以及其它声明不影响哈希
+	cosmetic := func(src string) string {
+		src = strings.Replace(src, "func New() *Simple {", "// New builds the adapter.\nfunc New() *Simple {\n\t// metadata below", 1)
+		src = strings.Replace(src, "\treturn a\n", "\n\treturn a\n", 1)
+		return strings.Replace(src, "func init() {", "func helper() {}\n\nfunc init() {", 1)
+	}
+	if _, got := hashOf(cosmetic); got != first {
+		t.Errorf("after a comment and formatting edit: hash %s, want %s", got, first)
+	}
+
+	// 构造函数的代码变化时，即使元数据相同，哈希也会改变
+	version, got := hashOf(func(src string) string {
+		return strings.Replace(src, "a := &Simple{}", "a := new(Simple)", 1)
+	})
+	if version != "1.0.0" {
+		t.Errorf("Version = %q, want the unchanged 1.0.0", version)
+	}
+	if got == first {
+		t.Error("a code change in the constructor kept the same hash")
+	}
+
+	if results, _ := scanFixture(t, "overlay", Options{}); len(results) != 1 || results[0].SourceHash != "" {
+		t.Errorf("without SourceHash: results = %+v, want one without a hash", results)
+	}
+}
@@ -0,0 +1,31 @@
+package custom
+
+import (
+	"github.com/meloshub/meloshub/adapter"
+	"github.com/meloshub/meloshub/model"
+)
+
+type C struct {
+	adapter.Base
+}
+
+func init() {
+	registerEach([]adapter.Adapter{New("custom-a", "Custom A"), New("custom-b", "Custom B")})
+}
+
+func registerEach(as []adapter.Adapter) {
+	for _, a := range as {
+		adapter.Register(a)
+	}
+}
+
+func New(id, title string) *C {
+	c := &C{}
+	c.Init(adapter.Metadata{Id: id, Title: title, Type: adapter.TypeOfficial, Version: "2.0.0", Author: "Alice"})
+	return c
+}
+
+func (c *C) SearchSong(k string, o adapter.SearchOptions) ([]model.Song, error) { return nil, nil }
+func (c *C) GetSongByID(id string) (*model.Song, error)                         { return nil, nil }
+func (c *C) GetLyricsByID(id string) (string, error)                            { return "", nil }
+func (c *C) GetAlbumSongsByID(id string) ([]model.Song, error)                  { return nil, nil }
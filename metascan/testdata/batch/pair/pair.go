@@ -0,0 +1,40 @@
+package pair
+
+import (
+	"github.com/meloshub/meloshub/adapter"
+	"github.com/meloshub/meloshub/model"
+)
+
+type A struct {
+	adapter.Base
+}
+
+func init() {
+	RegisterAll([]adapter.Adapter{NewOne(), NewTwo()})
+}
+
+// RegisterAll 包内的批量注册辅助函数
+func RegisterAll(as []adapter.Adapter) {
+	for _, a := range as {
+		if err := adapter.Register(a); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func NewOne() *A {
+	a := &A{}
+	a.Init(adapter.Metadata{Id: "batch-one", Title: "Batch One", Type: adapter.TypeCommunity, Version: "1.0.0", Author: "Bob"})
+	return a
+}
+
+func NewTwo() *A {
+	a := &A{}
+	a.Init(adapter.Metadata{Id: "batch-two", Title: "Batch Two", Type: adapter.TypeCommunity, Version: "1.0.0", Author: "Bob"})
+	return a
+}
+
+func (a *A) SearchSong(k string, o adapter.SearchOptions) ([]model.Song, error) { return nil, nil }
+func (a *A) GetSongByID(id string) (*model.Song, error)                         { return nil, nil }
+func (a *A) GetLyricsByID(id string) (string, error)                            { return "", nil }
+func (a *A) GetAlbumSongsByID(id string) ([]model.Song, error)                  { return nil, nil }
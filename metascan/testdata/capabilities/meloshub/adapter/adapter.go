@@ -0,0 +1,18 @@
+// Package adapter 是声明了功能开关的 adapter 分支，供 Capabilities 字段的扫描测试使用
+package adapter
+
+type Metadata struct {
+	Id, Title, Type, Version, Author, Description string
+	Capabilities                                  map[string]bool
+}
+
+type Adapter interface{ Metadata() Metadata }
+
+type Base struct{ meta Metadata }
+
+func (b *Base) Init(m Metadata)    { b.meta = m }
+func (b *Base) Metadata() Metadata { return b.meta }
+
+var registry []Adapter
+
+func Register(a Adapter) { registry = append(registry, a) }
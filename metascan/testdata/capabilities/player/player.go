@@ -0,0 +1,45 @@
+package player
+
+import "github.com/meloshub/meloshub-tools/metascan/testdata/capabilities/meloshub/adapter"
+
+const (
+	capSearch = "search"
+	enabled   = true
+)
+
+var lossless = map[string]bool{"flac": true, "alac": false}
+
+type Player struct{ adapter.Base }
+
+func NewStreaming() *Player {
+	a := &Player{}
+	a.Init(adapter.Metadata{
+		Id:      "streaming",
+		Title:   "Streaming",
+		Version: "1.0.0",
+		Capabilities: map[string]bool{
+			capSearch: enabled,
+			"stream":  false,
+			"lyrics":  true,
+		},
+	})
+	return a
+}
+
+func NewLossless() *Player {
+	a := &Player{}
+	a.Init(adapter.Metadata{Id: "lossless", Title: "Lossless", Version: "1.0.0", Capabilities: lossless})
+	return a
+}
+
+func NewPlain() *Player {
+	a := &Player{}
+	a.Init(adapter.Metadata{Id: "plain", Title: "Plain", Version: "1.0.0"})
+	return a
+}
+
+func init() {
+	adapter.Register(NewStreaming())
+	adapter.Register(NewLossless())
+	adapter.Register(NewPlain())
+}
@@ -0,0 +1,16 @@
+package closure
+
+import (
+	"github.com/meloshub/meloshub-tools/metascan/testdata/closures/lazy"
+	"github.com/meloshub/meloshub/adapter"
+)
+
+func init() {
+	adapter.Register(makeAdapter())
+}
+
+func makeAdapter() adapter.Adapter {
+	return &lazy.Lazy{Build: func() adapter.Metadata {
+		return adapter.Metadata{Id: "closure", Title: "Closure", Type: adapter.TypeCommunity, Version: "1.0.0", Author: "C"}
+	}}
+}
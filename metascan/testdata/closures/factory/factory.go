@@ -0,0 +1,27 @@
+package factory
+
+import (
+	"github.com/meloshub/meloshub/adapter"
+	"github.com/meloshub/meloshub/model"
+)
+
+type A struct {
+	adapter.Base
+}
+
+func init() {
+	adapter.Register(makeFactory("1.2.0")())
+}
+
+func makeFactory(version string) func() adapter.Adapter {
+	return func() adapter.Adapter {
+		a := &A{}
+		a.Init(adapter.Metadata{Id: "factory", Title: "Factory", Type: adapter.TypeCommunity, Version: version, Author: "F"})
+		return a
+	}
+}
+
+func (a *A) SearchSong(k string, o adapter.SearchOptions) ([]model.Song, error) { return nil, nil }
+func (a *A) GetSongByID(id string) (*model.Song, error)                         { return nil, nil }
+func (a *A) GetLyricsByID(id string) (string, error)                            { return "", nil }
+func (a *A) GetAlbumSongsByID(id string) ([]model.Song, error)                  { return nil, nil }
@@ -0,0 +1,28 @@
+package factoryvar
+
+import (
+	"github.com/meloshub/meloshub/adapter"
+	"github.com/meloshub/meloshub/model"
+)
+
+type A struct {
+	adapter.Base
+}
+
+func init() {
+	build := makeFactory()
+	adapter.Register(build())
+}
+
+func makeFactory() func() adapter.Adapter {
+	return func() adapter.Adapter {
+		a := &A{}
+		a.Init(adapter.Metadata{Id: "factoryvar", Title: "Factory Var", Type: adapter.TypeCommunity, Version: "1.0.0", Author: "F"})
+		return a
+	}
+}
+
+func (a *A) SearchSong(k string, o adapter.SearchOptions) ([]model.Song, error) { return nil, nil }
+func (a *A) GetSongByID(id string) (*model.Song, error)                         { return nil, nil }
+func (a *A) GetLyricsByID(id string) (string, error)                            { return "", nil }
+func (a *A) GetAlbumSongsByID(id string) ([]model.Song, error)                  { return nil, nil }
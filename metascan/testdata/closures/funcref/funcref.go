@@ -0,0 +1,18 @@
+package funcref
+
+import (
+	"github.com/meloshub/meloshub-tools/metascan/testdata/closures/lazy"
+	"github.com/meloshub/meloshub/adapter"
+)
+
+func init() {
+	adapter.Register(makeAdapter())
+}
+
+func makeAdapter() adapter.Adapter {
+	return &lazy.Lazy{Build: buildMetadata}
+}
+
+func buildMetadata() adapter.Metadata {
+	return adapter.Metadata{Id: "funcref", Title: "Func Ref", Type: adapter.TypeCommunity, Version: "1.0.0", Author: "R"}
+}
@@ -0,0 +1,25 @@
+package ifacemethod
+
+import (
+	"github.com/meloshub/meloshub/adapter"
+	"github.com/meloshub/meloshub/model"
+)
+
+type M struct{}
+
+func init() {
+	adapter.Register(makeAdapter())
+}
+
+func makeAdapter() adapter.Adapter {
+	return &M{}
+}
+
+func (m *M) Metadata() adapter.Metadata {
+	return adapter.Metadata{Id: "ifacemethod", Title: "Iface Method", Type: adapter.TypeCommunity, Version: "1.0.0", Author: "M"}
+}
+func (m *M) Id() string                                                         { return "ifacemethod" }
+func (m *M) SearchSong(k string, o adapter.SearchOptions) ([]model.Song, error) { return nil, nil }
+func (m *M) GetSongByID(id string) (*model.Song, error)                         { return nil, nil }
+func (m *M) GetLyricsByID(id string) (string, error)                            { return "", nil }
+func (m *M) GetAlbumSongsByID(id string) ([]model.Song, error)                  { return nil, nil }
@@ -0,0 +1,18 @@
+package lazy
+
+import (
+	"github.com/meloshub/meloshub/adapter"
+	"github.com/meloshub/meloshub/model"
+)
+
+// Lazy builds its metadata on first use.
+type Lazy struct {
+	Build func() adapter.Metadata
+}
+
+func (l *Lazy) Metadata() adapter.Metadata                                         { return l.Build() }
+func (l *Lazy) Id() string                                                         { return l.Build().Id }
+func (l *Lazy) SearchSong(k string, o adapter.SearchOptions) ([]model.Song, error) { return nil, nil }
+func (l *Lazy) GetSongByID(id string) (*model.Song, error)                         { return nil, nil }
+func (l *Lazy) GetLyricsByID(id string) (string, error)                            { return "", nil }
+func (l *Lazy) GetAlbumSongsByID(id string) ([]model.Song, error)                  { return nil, nil }
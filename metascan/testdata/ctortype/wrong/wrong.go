@@ -0,0 +1,18 @@
+// Package wrong 有意无法通过编译：构造函数返回的类型没有实现 adapter.Adapter
+package wrong
+
+import "github.com/meloshub/meloshub/adapter"
+
+type Incomplete struct {
+	adapter.Base
+}
+
+func init() {
+	adapter.Register(New())
+}
+
+func New() *Incomplete {
+	a := &Incomplete{}
+	a.Init(adapter.Metadata{Id: "wrong", Title: "Wrong", Type: adapter.TypeCommunity, Version: "1.0.0", Author: "Bob"})
+	return a
+}
@@ -0,0 +1,34 @@
+package emb
+
+import "github.com/meloshub/meloshub-tools/metascan/testdata/embedded/meloshub/adapter"
+
+var common = adapter.BaseMeta{Author: "Shared Team <team@example.com>", License: "MIT"}
+
+type A struct{ adapter.Base }
+
+func init() {
+	adapter.Register(NewShared())
+	adapter.Register(NewOverride())
+	adapter.Register(NewField())
+}
+
+// NewShared 的作者来自嵌入的包级变量
+func NewShared() *A {
+	a := &A{}
+	a.Init(adapter.Metadata{Id: "shared", Title: "Shared", Version: "1.0.0", BaseMeta: common})
+	return a
+}
+
+// NewOverride 直接声明的字段优先于嵌入的基础结构体
+func NewOverride() *A {
+	a := &A{}
+	a.Init(adapter.Metadata{Id: "override", Title: "Override", Version: "1.0.0", Author: "Direct <d@example.com>", BaseMeta: adapter.BaseMeta{Author: "ignored"}})
+	return a
+}
+
+// NewField 引用包级结构体变量的字段
+func NewField() *A {
+	a := &A{}
+	a.Init(adapter.Metadata{Id: "field", Title: "Field", Version: "1.0.0", Author: common.Author})
+	return a
+}
@@ -0,0 +1,20 @@
+// Package adapter 是元数据嵌入了共享基础结构体的 adapter 分支，供嵌入字段的扫描测试使用
+package adapter
+
+type BaseMeta struct{ Author, License string }
+
+type Metadata struct {
+	BaseMeta
+	Id, Title, Type, Version, Author, Description string
+}
+
+type Adapter interface{ Metadata() Metadata }
+
+type Base struct{ meta Metadata }
+
+func (b *Base) Init(m Metadata)    { b.meta = m }
+func (b *Base) Metadata() Metadata { return b.meta }
+
+var registry []Adapter
+
+func Register(a Adapter) { registry = append(registry, a) }
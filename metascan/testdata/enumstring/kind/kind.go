@@ -0,0 +1,41 @@
+package kind
+
+import "fmt"
+
+type Kind int
+
+const (
+	Official Kind = iota
+	Community
+	Unknown
+)
+
+// String 对接收者 switch，未列出的值落到 switch 之后的 return
+func (k Kind) String() string {
+	switch k {
+	case Official:
+		return "official"
+	case Community:
+		return "community"
+	}
+	return "unknown"
+}
+
+type Level int
+
+const (
+	Low Level = iota
+	High
+	// Top 超出了 levelNames 的范围
+	Top
+)
+
+var levelNames = [...]string{"low", "high"}
+
+func (l Level) String() string { return levelNames[l] }
+
+type Odd int
+
+const Weird Odd = 3
+
+func (o Odd) String() string { return fmt.Sprintf("odd-%d", int(o)) }
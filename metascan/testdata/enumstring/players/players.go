@@ -0,0 +1,53 @@
+package players
+
+import (
+	"github.com/meloshub/meloshub-tools/metascan/testdata/enumstring/kind"
+	"github.com/meloshub/meloshub/adapter"
+	"github.com/meloshub/meloshub/model"
+)
+
+type A struct{ adapter.Base }
+
+func init() {
+	adapter.Register(NewSwitch())
+	adapter.Register(NewTable())
+	adapter.Register(NewParam("p1", kind.Official))
+	adapter.Register(NewParam("p2", kind.Unknown))
+	adapter.Register(NewComplex())
+	adapter.Register(NewOutOfRange())
+}
+
+func NewSwitch() *A {
+	a := &A{}
+	a.Init(adapter.Metadata{Id: "switch", Title: "Switch", Type: adapter.AdapterType(kind.Community.String()), Version: "1.0.0", Author: "A <a@example.com>", Description: "Switch enum."})
+	return a
+}
+
+func NewTable() *A {
+	a := &A{}
+	a.Init(adapter.Metadata{Id: "table", Title: "Table", Type: adapter.TypeCommunity, Version: "1.0.0", Author: "A <a@example.com>", Description: kind.High.String()})
+	return a
+}
+
+func NewParam(id string, k kind.Kind) *A {
+	a := &A{}
+	a.Init(adapter.Metadata{Id: id, Title: "Param", Type: adapter.AdapterType(k.String()), Version: "1.0.0", Author: "A <a@example.com>", Description: "Param."})
+	return a
+}
+
+func NewComplex() *A {
+	a := &A{}
+	a.Init(adapter.Metadata{Id: "complex", Title: "Complex", Type: adapter.AdapterType(kind.Weird.String()), Version: "1.0.0", Author: "A <a@example.com>", Description: "Complex."})
+	return a
+}
+
+func NewOutOfRange() *A {
+	a := &A{}
+	a.Init(adapter.Metadata{Id: "outofrange", Title: "Out of range", Type: adapter.TypeCommunity, Version: "1.0.0", Author: "A <a@example.com>", Description: kind.Top.String()})
+	return a
+}
+
+func (a *A) SearchSong(k string, o adapter.SearchOptions) ([]model.Song, error) { return nil, nil }
+func (a *A) GetSongByID(id string) (*model.Song, error)                         { return nil, nil }
+func (a *A) GetLyricsByID(id string) (string, error)                            { return "", nil }
+func (a *A) GetAlbumSongsByID(id string) ([]model.Song, error)                  { return nil, nil }
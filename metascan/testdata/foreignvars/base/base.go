@@ -0,0 +1,19 @@
+// Package base 定义同一系列适配器共用的变量
+package base
+
+import "strings"
+
+var Version = "2.3.0"
+
+// Author 的初始值引用另一个变量
+var Author = Maintainer
+
+var Maintainer = "Base Team <base@example.com>"
+
+// Build 的初始值是函数调用，无法静态求值
+var Build = strings.ToUpper("beta")
+
+const Prefix = "family"
+
+// Title 含有引号与反斜杠，绑定时需要转义
+var Title = "\"Family\" Member \\ Deluxe"
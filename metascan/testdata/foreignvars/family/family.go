@@ -0,0 +1,33 @@
+package family
+
+import (
+	"github.com/meloshub/meloshub-tools/metascan/testdata/foreignvars/base"
+	"github.com/meloshub/meloshub/adapter"
+	"github.com/meloshub/meloshub/model"
+)
+
+type A struct {
+	adapter.Base
+}
+
+func init() {
+	adapter.Register(New())
+}
+
+func New() *A {
+	a := &A{}
+	a.Init(adapter.Metadata{
+		Id:          base.Prefix + "-member",
+		Title:       base.Title,
+		Type:        adapter.TypeCommunity,
+		Version:     base.Version,
+		Author:      base.Author,
+		Description: base.Build,
+	})
+	return a
+}
+
+func (a *A) SearchSong(k string, o adapter.SearchOptions) ([]model.Song, error) { return nil, nil }
+func (a *A) GetSongByID(id string) (*model.Song, error)                         { return nil, nil }
+func (a *A) GetLyricsByID(id string) (string, error)                            { return "", nil }
+func (a *A) GetAlbumSongsByID(id string) ([]model.Song, error)                  { return nil, nil }
@@ -0,0 +1,62 @@
+package generic
+
+import (
+	"github.com/meloshub/meloshub/adapter"
+	"github.com/meloshub/meloshub/model"
+)
+
+type Provider interface{ Name() string }
+
+type Lastfm struct{}
+
+func (Lastfm) Name() string { return "lastfm" }
+
+type Listenbrainz struct{}
+
+func (Listenbrainz) Name() string { return "listenbrainz" }
+
+type A[T Provider] struct {
+	adapter.Base
+	p T
+}
+
+func init() {
+	adapter.Register(New[Lastfm]("lastfm", "Last.fm"))
+	lb := New[Listenbrainz]("listenbrainz", "ListenBrainz")
+	adapter.Register(lb)
+	adapter.Register(NewPair[Lastfm, Listenbrainz]())
+}
+
+func New[T Provider](id, title string) *A[T] {
+	a := &A[T]{}
+	a.Init(adapter.Metadata{Id: id, Title: title, Type: adapter.TypeCommunity, Version: "1.0.0", Author: "Gen"})
+	return a
+}
+
+func NewPair[T, U Provider]() *A[T] {
+	a := &A[T]{}
+	a.Init(adapter.Metadata{Id: "pair", Title: "Pair", Type: adapter.TypeCommunity, Version: "1.0.0", Author: "Gen"})
+	return a
+}
+
+func (a *A[T]) SearchSong(k string, o adapter.SearchOptions) ([]model.Song, error) { return nil, nil }
+func (a *A[T]) GetSongByID(id string) (*model.Song, error)                         { return nil, nil }
+func (a *A[T]) GetLyricsByID(id string) (string, error)                            { return "", nil }
+func (a *A[T]) GetAlbumSongsByID(id string) ([]model.Song, error)                  { return nil, nil }
+
+type Plain struct{ adapter.Base }
+
+func (p *Plain) SearchSong(k string, o adapter.SearchOptions) ([]model.Song, error) { return nil, nil }
+func (p *Plain) GetSongByID(id string) (*model.Song, error)                         { return nil, nil }
+func (p *Plain) GetLyricsByID(id string) (string, error)                            { return "", nil }
+func (p *Plain) GetAlbumSongsByID(id string) ([]model.Song, error)                  { return nil, nil }
+
+func init() {
+	adapter.Register(Make(&Plain{}, "made"))
+}
+
+// Make 类型实参由实参推断
+func Make[T interface{ Init(adapter.Metadata) }](a T, id string) T {
+	a.Init(adapter.Metadata{Id: id, Title: "Made", Type: adapter.TypeCommunity, Version: "1.0.0", Author: "Gen"})
+	return a
+}
@@ -0,0 +1,23 @@
+package genmethod
+
+import (
+	"github.com/meloshub/meloshub/adapter"
+	"github.com/meloshub/meloshub/model"
+)
+
+type B[T any] struct {
+	adapter.Base
+	v T
+}
+
+func init() {
+	adapter.Register(&B[int]{})
+}
+
+func (b *B[T]) Metadata() adapter.Metadata {
+	return adapter.Metadata{Id: "genmethod", Title: "Gen Method", Type: adapter.TypeCommunity, Version: "1.0.0", Author: "Gen"}
+}
+func (b *B[T]) SearchSong(k string, o adapter.SearchOptions) ([]model.Song, error) { return nil, nil }
+func (b *B[T]) GetSongByID(id string) (*model.Song, error)                         { return nil, nil }
+func (b *B[T]) GetLyricsByID(id string) (string, error)                            { return "", nil }
+func (b *B[T]) GetAlbumSongsByID(id string) ([]model.Song, error)                  { return nil, nil }
@@ -0,0 +1,17 @@
+package forked
+
+import "github.com/meloshub/meloshub-tools/metascan/testdata/metadatatype/meloshub/adapter"
+
+type F struct {
+	info adapter.AdapterInfo
+}
+
+func init() {
+	adapter.Register(New())
+}
+
+func New() *F {
+	return &F{info: adapter.AdapterInfo{Id: "forked", Title: "Forked", Type: "community", Version: "0.1.0", Author: "Fork <f@example.com>"}}
+}
+
+func (f *F) Info() adapter.AdapterInfo { return f.info }
@@ -0,0 +1,12 @@
+// Package adapter 是将 Metadata 重命名为 AdapterInfo 的 adapter 分支，供 --metadata-type 的扫描测试使用
+package adapter
+
+type AdapterInfo struct {
+	Id, Title, Type, Version, Author, Description string
+}
+
+type Adapter interface{ Info() AdapterInfo }
+
+var registry []Adapter
+
+func Register(a Adapter) { registry = append(registry, a) }
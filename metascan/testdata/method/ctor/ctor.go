@@ -0,0 +1,30 @@
+package ctor
+
+import (
+	"github.com/meloshub/meloshub/adapter"
+	"github.com/meloshub/meloshub/model"
+)
+
+type Ctor struct {
+	adapter.Base
+	region string
+}
+
+// New 不创建元数据，元数据由 Metadata 方法返回
+func New(region string) *Ctor {
+	return &Ctor{region: region}
+}
+
+func init() {
+	a := New("eu")
+	adapter.Register(a)
+}
+
+func (a Ctor) Metadata() adapter.Metadata {
+	return adapter.Metadata{Id: "ctor", Title: "Ctor", Type: adapter.TypeOfficial, Version: "1.2.0", Author: "Alice"}
+}
+
+func (a *Ctor) SearchSong(k string, o adapter.SearchOptions) ([]model.Song, error) { return nil, nil }
+func (a *Ctor) GetSongByID(id string) (*model.Song, error)                         { return nil, nil }
+func (a *Ctor) GetLyricsByID(id string) (string, error)                            { return "", nil }
+func (a *Ctor) GetAlbumSongsByID(id string) ([]model.Song, error)                  { return nil, nil }
@@ -0,0 +1,21 @@
+package literal
+
+import (
+	"github.com/meloshub/meloshub/adapter"
+	"github.com/meloshub/meloshub/model"
+)
+
+type Literal struct {
+	adapter.Base
+}
+
+func init() {
+	adapter.Register(&Literal{})
+}
+
+func (a *Literal) SearchSong(k string, o adapter.SearchOptions) ([]model.Song, error) {
+	return nil, nil
+}
+func (a *Literal) GetSongByID(id string) (*model.Song, error)        { return nil, nil }
+func (a *Literal) GetLyricsByID(id string) (string, error)           { return "", nil }
+func (a *Literal) GetAlbumSongsByID(id string) ([]model.Song, error) { return nil, nil }
@@ -0,0 +1,13 @@
+package literal
+
+import "github.com/meloshub/meloshub/adapter"
+
+func (a *Literal) Metadata() adapter.Metadata {
+	return adapter.Metadata{
+		Id:      "literal",
+		Title:   "Literal",
+		Type:    adapter.TypeCommunity,
+		Version: "1.0.0",
+		Author:  "Bob",
+	}
+}
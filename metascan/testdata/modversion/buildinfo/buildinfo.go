@@ -0,0 +1,35 @@
+package buildinfo
+
+import (
+	"runtime/debug"
+
+	"github.com/meloshub/meloshub/adapter"
+	"github.com/meloshub/meloshub/model"
+)
+
+// version 在构建时由模块信息决定，无法静态求值
+var version = func() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		return info.Main.Version
+	}
+	return ""
+}()
+
+type A struct {
+	adapter.Base
+}
+
+func init() {
+	adapter.Register(New())
+}
+
+func New() *A {
+	a := &A{}
+	a.Init(adapter.Metadata{Id: "buildinfo", Title: "Build Info", Type: adapter.TypeCommunity, Version: version, Author: "Bob"})
+	return a
+}
+
+func (a *A) SearchSong(k string, o adapter.SearchOptions) ([]model.Song, error) { return nil, nil }
+func (a *A) GetSongByID(id string) (*model.Song, error)                         { return nil, nil }
+func (a *A) GetLyricsByID(id string) (string, error)                            { return "", nil }
+func (a *A) GetAlbumSongsByID(id string) ([]model.Song, error)                  { return nil, nil }
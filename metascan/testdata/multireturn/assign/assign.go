@@ -0,0 +1,40 @@
+package assign
+
+import (
+	"errors"
+
+	"github.com/meloshub/meloshub/adapter"
+	"github.com/meloshub/meloshub/model"
+)
+
+type Assign struct {
+	adapter.Base
+}
+
+func New(token string) (*Assign, error) {
+	if token == "" {
+		return nil, errors.New("missing token")
+	}
+	a := &Assign{}
+	a.Init(adapter.Metadata{
+		Id:      "assign",
+		Title:   "Assign",
+		Type:    adapter.TypeCommunity,
+		Version: "1.0.0",
+		Author:  "Bob",
+	})
+	return a, nil
+}
+
+func init() {
+	a, err := New("token")
+	if err != nil {
+		panic(err)
+	}
+	adapter.Register(a)
+}
+
+func (a *Assign) SearchSong(k string, o adapter.SearchOptions) ([]model.Song, error) { return nil, nil }
+func (a *Assign) GetSongByID(id string) (*model.Song, error)                         { return nil, nil }
+func (a *Assign) GetLyricsByID(id string) (string, error)                            { return "", nil }
+func (a *Assign) GetAlbumSongsByID(id string) ([]model.Song, error)                  { return nil, nil }
@@ -0,0 +1,34 @@
+package varspec
+
+import (
+	"github.com/meloshub/meloshub/adapter"
+	"github.com/meloshub/meloshub/model"
+)
+
+type VarSpec struct {
+	adapter.Base
+}
+
+func New() (*VarSpec, error) {
+	a := &VarSpec{}
+	a.Init(adapter.Metadata{
+		Id:      "varspec",
+		Title:   "Var Spec",
+		Type:    adapter.TypeOfficial,
+		Version: "2.0.0",
+		Author:  "Alice",
+	})
+	return a, nil
+}
+
+func init() {
+	var a, _ = New()
+	adapter.Register(a)
+}
+
+func (a *VarSpec) SearchSong(k string, o adapter.SearchOptions) ([]model.Song, error) {
+	return nil, nil
+}
+func (a *VarSpec) GetSongByID(id string) (*model.Song, error)        { return nil, nil }
+func (a *VarSpec) GetLyricsByID(id string) (string, error)           { return "", nil }
+func (a *VarSpec) GetAlbumSongsByID(id string) ([]model.Song, error) { return nil, nil }
@@ -0,0 +1,50 @@
+package srctype
+
+import (
+	"github.com/meloshub/meloshub/adapter"
+	"github.com/meloshub/meloshub/model"
+)
+
+const SourceType adapter.AdapterType = "source"
+
+type Kind string
+
+const Official Kind = "official"
+
+// Label 的底层类型经由另一个具名类型才是 string
+type Label Kind
+
+const Beta Label = "beta"
+
+type A struct {
+	adapter.Base
+}
+
+func init() {
+	adapter.Register(NewSource())
+	adapter.Register(NewConverted())
+	adapter.Register(NewBuiltin())
+}
+
+func NewSource() *A {
+	a := &A{}
+	a.Init(adapter.Metadata{Id: "source", Title: "Source", Type: SourceType, Version: "1.0.0", Author: "Bob"})
+	return a
+}
+
+func NewConverted() *A {
+	a := &A{}
+	a.Init(adapter.Metadata{Id: "converted", Title: string(Beta), Type: adapter.AdapterType(Official), Version: "1.0.0", Author: "Bob"})
+	return a
+}
+
+func NewBuiltin() *A {
+	a := &A{}
+	a.Init(adapter.Metadata{Id: "builtin", Title: "Builtin", Type: adapter.TypeOfficial, Version: "1.0.0", Author: "Bob"})
+	return a
+}
+
+func (a *A) SearchSong(k string, o adapter.SearchOptions) ([]model.Song, error) { return nil, nil }
+func (a *A) GetSongByID(id string) (*model.Song, error)                         { return nil, nil }
+func (a *A) GetLyricsByID(id string) (string, error)                            { return "", nil }
+func (a *A) GetAlbumSongsByID(id string) ([]model.Song, error)                  { return nil, nil }
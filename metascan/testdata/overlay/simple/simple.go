@@ -0,0 +1,31 @@
+package simple
+
+import (
+	"github.com/meloshub/meloshub/adapter"
+	"github.com/meloshub/meloshub/model"
+)
+
+type Simple struct {
+	adapter.Base
+}
+
+func New() *Simple {
+	a := &Simple{}
+	a.Init(adapter.Metadata{
+		Id:      "simple",
+		Title:   "Simple",
+		Type:    adapter.TypeCommunity,
+		Version: "1.0.0",
+		Author:  "Bob",
+	})
+	return a
+}
+
+func init() {
+	adapter.Register(New())
+}
+
+func (a *Simple) SearchSong(k string, o adapter.SearchOptions) ([]model.Song, error) { return nil, nil }
+func (a *Simple) GetSongByID(id string) (*model.Song, error)                         { return nil, nil }
+func (a *Simple) GetLyricsByID(id string) (string, error)                            { return "", nil }
+func (a *Simple) GetAlbumSongsByID(id string) ([]model.Song, error)                  { return nil, nil }
@@ -0,0 +1,36 @@
+package shared
+
+import (
+	"github.com/meloshub/meloshub/adapter"
+	"github.com/meloshub/meloshub/model"
+)
+
+const author = "Streaming Team"
+
+type Shared struct {
+	adapter.Base
+}
+
+func New(id, title string, typ adapter.AdapterType) *Shared {
+	a := &Shared{}
+	a.Init(adapter.Metadata{
+		Id:      id,
+		Title:   title,
+		Type:    typ,
+		Version: "1.0.0",
+		Author:  author,
+	})
+	return a
+}
+
+func init() {
+	adapter.Register(New("spotify", "Spotify", adapter.TypeOfficial))
+	adapter.Register(New("deezer", "Deezer", adapter.TypeCommunity))
+	tidal := New("tidal", "Tidal", adapter.TypeCommunity)
+	adapter.Register(tidal)
+}
+
+func (a *Shared) SearchSong(k string, o adapter.SearchOptions) ([]model.Song, error) { return nil, nil }
+func (a *Shared) GetSongByID(id string) (*model.Song, error)                         { return nil, nil }
+func (a *Shared) GetLyricsByID(id string) (string, error)                            { return "", nil }
+func (a *Shared) GetAlbumSongsByID(id string) ([]model.Song, error)                  { return nil, nil }
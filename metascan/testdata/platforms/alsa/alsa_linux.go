@@ -0,0 +1,27 @@
+//go:build linux
+
+package alsa
+
+import (
+	"github.com/meloshub/meloshub/adapter"
+	"github.com/meloshub/meloshub/model"
+)
+
+type A struct {
+	adapter.Base
+}
+
+func init() {
+	adapter.Register(New())
+}
+
+func New() *A {
+	a := &A{}
+	a.Init(adapter.Metadata{Id: "alsa", Title: "alsa", Type: adapter.TypeCommunity, Version: "1.0.0", Author: "Bob"})
+	return a
+}
+
+func (a *A) SearchSong(k string, o adapter.SearchOptions) ([]model.Song, error) { return nil, nil }
+func (a *A) GetSongByID(id string) (*model.Song, error)                         { return nil, nil }
+func (a *A) GetLyricsByID(id string) (string, error)                            { return "", nil }
+func (a *A) GetAlbumSongsByID(id string) ([]model.Song, error)                  { return nil, nil }
@@ -0,0 +1,2 @@
+// Package alsa 只在 linux 上注册适配器
+package alsa
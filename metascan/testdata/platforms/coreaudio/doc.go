@@ -0,0 +1,2 @@
+// Package coreaudio 只在 darwin 上注册适配器
+package coreaudio
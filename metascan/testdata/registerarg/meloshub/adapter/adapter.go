@@ -0,0 +1,17 @@
+// Package adapter 是 Register 接收名称与构造函数的 adapter 分支，供 RegisterArgIndex 的扫描测试使用
+package adapter
+
+type Metadata struct {
+	Id, Title, Type, Version, Author, Description string
+}
+
+type Adapter interface{ Metadata() Metadata }
+
+type Base struct{ meta Metadata }
+
+func (b *Base) Init(m Metadata)    { b.meta = m }
+func (b *Base) Metadata() Metadata { return b.meta }
+
+var registry = map[string]func() Adapter{}
+
+func Register(name string, ctor func() Adapter) { registry[name] = ctor }
@@ -0,0 +1,13 @@
+package one
+
+import "github.com/meloshub/meloshub-tools/metascan/testdata/registerarg/meloshub/adapter"
+
+type A struct{ adapter.Base }
+
+func init() { adapter.Register("one", New) }
+
+func New() adapter.Adapter {
+	a := &A{}
+	a.Init(adapter.Metadata{Id: "one", Title: "One", Type: "community", Version: "1.0.0", Author: "O <o@example.com>", Description: "One."})
+	return a
+}
@@ -0,0 +1,5 @@
+package three
+
+import "github.com/meloshub/meloshub-tools/metascan/testdata/registerarg/meloshub/adapter"
+
+func init() { adapter.Register("three", nil) }
@@ -0,0 +1,15 @@
+package two
+
+import "github.com/meloshub/meloshub-tools/metascan/testdata/registerarg/meloshub/adapter"
+
+type A struct{ adapter.Base }
+
+func init() {
+	adapter.Register("two", func() adapter.Adapter { return New("2.0.0") })
+}
+
+func New(version string) *A {
+	a := &A{}
+	a.Init(adapter.Metadata{Id: "two", Title: "Two", Type: "community", Version: version, Author: "T <t@example.com>", Description: "Two."})
+	return a
+}
@@ -0,0 +1,16 @@
+package real_test
+
+import (
+	"github.com/meloshub/meloshub-tools/metascan/testdata/testfiles/real"
+	"github.com/meloshub/meloshub/adapter"
+)
+
+func init() {
+	adapter.Register(newExternal())
+}
+
+func newExternal() *real.A {
+	a := &real.A{}
+	a.Init(adapter.Metadata{Id: "external", Title: "External", Type: adapter.TypeCommunity, Version: "1.0.0", Author: "Bob"})
+	return a
+}
@@ -0,0 +1,28 @@
+package real
+
+import (
+	"github.com/meloshub/meloshub/adapter"
+	"github.com/meloshub/meloshub/model"
+)
+
+// Version 与测试文件中的适配器共用
+const Version = "1.0.0"
+
+type A struct {
+	adapter.Base
+}
+
+func init() {
+	adapter.Register(New("real", "Real"))
+}
+
+func New(id, title string) *A {
+	a := &A{}
+	a.Init(adapter.Metadata{Id: id, Title: title, Type: adapter.TypeCommunity, Version: Version, Author: "Bob"})
+	return a
+}
+
+func (a *A) SearchSong(k string, o adapter.SearchOptions) ([]model.Song, error) { return nil, nil }
+func (a *A) GetSongByID(id string) (*model.Song, error)                         { return nil, nil }
+func (a *A) GetLyricsByID(id string) (string, error)                            { return "", nil }
+func (a *A) GetAlbumSongsByID(id string) ([]model.Song, error)                  { return nil, nil }
@@ -0,0 +1,13 @@
+package real
+
+import "github.com/meloshub/meloshub/adapter"
+
+func init() {
+	adapter.Register(newFake())
+}
+
+func newFake() *A {
+	a := &A{}
+	a.Init(adapter.Metadata{Id: "real-fake", Title: "Real Fake", Type: adapter.TypeCommunity, Version: Version, Author: "Bob"})
+	return a
+}
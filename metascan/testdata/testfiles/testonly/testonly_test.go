@@ -0,0 +1,16 @@
+package testonly
+
+import "github.com/meloshub/meloshub/adapter"
+
+// title 只在测试文件中定义
+const title = "Test Only"
+
+func init() {
+	adapter.Register(New())
+}
+
+func New() *A {
+	a := &A{}
+	a.Init(adapter.Metadata{Id: "testonly", Title: title, Type: adapter.TypeCommunity, Version: "1.0.0", Author: "Bob"})
+	return a
+}
@@ -0,0 +1,51 @@
+package localized
+
+import "github.com/meloshub/meloshub-tools/metascan/testdata/titles/meloshub/adapter"
+
+const (
+	localeZh = "zh"
+	titleZh  = "搜索"
+)
+
+var searchTitles = map[string]string{"en": "Search", localeZh: titleZh, "ja": "検索"}
+
+type Player struct{ adapter.Base }
+
+func NewSearch() *Player {
+	a := &Player{}
+	a.Init(adapter.Metadata{Id: "search", Version: "1.0.0", Titles: searchTitles})
+	return a
+}
+
+func NewNamed() *Player {
+	a := &Player{}
+	a.Init(adapter.Metadata{
+		Id:      "named",
+		Title:   "Named",
+		Version: "1.0.0",
+		Titles: map[string]string{
+			localeZh: "命名",
+			"en":     "Named in English",
+		},
+	})
+	return a
+}
+
+func NewChinese() *Player {
+	a := &Player{}
+	a.Init(adapter.Metadata{Id: "chinese", Version: "1.0.0", Titles: map[string]string{localeZh: "中文"}})
+	return a
+}
+
+func NewPlain() *Player {
+	a := &Player{}
+	a.Init(adapter.Metadata{Id: "plain", Title: "Plain", Version: "1.0.0"})
+	return a
+}
+
+func init() {
+	adapter.Register(NewSearch())
+	adapter.Register(NewNamed())
+	adapter.Register(NewChinese())
+	adapter.Register(NewPlain())
+}
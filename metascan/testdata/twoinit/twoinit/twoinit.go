@@ -0,0 +1,34 @@
+package twoinit
+
+import (
+	"github.com/meloshub/meloshub/adapter"
+	"github.com/meloshub/meloshub/model"
+)
+
+type A struct {
+	adapter.Base
+}
+
+func init() {
+	adapter.Register(New("twoinit-one", "Two Init One"))
+}
+
+func init() {
+	adapter.Register(New("twoinit-two", "Two Init Two"))
+}
+
+// init 方法不会在导入时执行，其中的注册不算
+func (a *A) init() {
+	adapter.Register(New("method", "Method"))
+}
+
+func New(id, title string) *A {
+	a := &A{}
+	a.Init(adapter.Metadata{Id: id, Title: title, Type: adapter.TypeCommunity, Version: "1.0.0", Author: "Bob"})
+	return a
+}
+
+func (a *A) SearchSong(k string, o adapter.SearchOptions) ([]model.Song, error) { return nil, nil }
+func (a *A) GetSongByID(id string) (*model.Song, error)                         { return nil, nil }
+func (a *A) GetLyricsByID(id string) (string, error)                            { return "", nil }
+func (a *A) GetAlbumSongsByID(id string) ([]model.Song, error)                  { return nil, nil }
@@ -0,0 +1,45 @@
+package unres
+
+import (
+	"os"
+	"strings"
+
+	"github.com/meloshub/meloshub/adapter"
+	"github.com/meloshub/meloshub/model"
+)
+
+type A struct{ adapter.Base }
+
+var descs = []string{"first description here"}
+
+var runtimeVersion = os.Getenv("VERSION")
+
+func init() {
+	adapter.Register(New())
+	adapter.Register(NewEmpty())
+}
+
+func New() *A {
+	a := &A{}
+	a.Init(adapter.Metadata{
+		Id:          "unres",
+		Title:       strings.ToUpper("unresolved"),
+		Type:        adapter.TypeCommunity,
+		Version:     runtimeVersion,
+		Author:      "Un" + os.Getenv("USER"),
+		Description: descs[0],
+	})
+	return a
+}
+
+// NewEmpty 有意写出的空字符串不算无法求值
+func NewEmpty() *A {
+	a := &A{}
+	a.Init(adapter.Metadata{Id: "empty-ok", Title: "Empty", Type: adapter.TypeCommunity, Version: "1.0.0", Author: "Bob", Description: ""})
+	return a
+}
+
+func (a *A) SearchSong(k string, o adapter.SearchOptions) ([]model.Song, error) { return nil, nil }
+func (a *A) GetSongByID(id string) (*model.Song, error)                         { return nil, nil }
+func (a *A) GetLyricsByID(id string) (string, error)                            { return "", nil }
+func (a *A) GetAlbumSongsByID(id string) ([]model.Song, error)                  { return nil, nil }
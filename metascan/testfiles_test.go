@@ -0,0 +1,23 @@
+package metascan
+
+import "testing"
+
+func TestScanExcludesTestFiles(t *testing.T) {
+	results, entries := scanFixture(t, "testfiles", Options{})
+	wantNoDiagnostics(t, entries)
+	wantIds(t, results, "real")
+}
+
+func TestScanIncludeTests(t *testing.T) {
+	results, entries := scanFixture(t, "testfiles", Options{IncludeTests: true})
+	wantNoDiagnostics(t, entries)
+	// 测试变体替换普通包，real 只出现一次
+	wantIds(t, results, "real", "real-fake", "testonly", "external")
+	byId := resultsById(t, results)
+	if got := byId["testonly"].Metadata.Title; got != "Test Only" {
+		t.Errorf("testonly Title = %q, want the constant from the _test.go file", got)
+	}
+	if got := byId["real-fake"].Metadata.Version; got != "1.0.0" {
+		t.Errorf("real-fake Version = %q, want the constant shared with real.go", got)
+	}
+}
@@ -0,0 +1,51 @@
+package metascan
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/meloshub/meloshub-tools/catalog"
+)
+
+func TestScanTitles(t *testing.T) {
+	results, entries := scanFixture(t, "titles", Options{})
+	wantNoDiagnostics(t, entries)
+	wantIds(t, results, "search", "named", "chinese", "plain")
+
+	byId := resultsById(t, results)
+	tests := []struct {
+		id   string
+		want map[string]string
+	}{
+		// 引用包级变量的 map 字面量，键与值可以是常量
+		{"search", map[string]string{"en": "Search", "zh": "搜索", "ja": "検索"}},
+		{"named", map[string]string{"zh": "命名", "en": "Named in English"}},
+		{"chinese", map[string]string{"zh": "中文"}},
+		{"plain", nil},
+	}
+	for _, tt := range tests {
+		if got := byId[tt.id].Titles; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s Titles = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+// TestScanTitlesDefaultLocale 检查 Entries 带上了 Titles，使 --default-locale 能够填充空的 Title
+func TestScanTitlesDefaultLocale(t *testing.T) {
+	results, _ := scanFixture(t, "titles", Options{})
+	got := Entries(results)
+	missing := catalog.ApplyDefaultLocale(got, "en")
+
+	titles := make(map[string]string, len(got))
+	for _, e := range got {
+		titles[e.Id] = e.Title
+	}
+	// 已声明的 Title 不会被覆盖，缺少该语言的条目保持为空
+	want := map[string]string{"search": "Search", "named": "Named", "chinese": "", "plain": "Plain"}
+	if !reflect.DeepEqual(titles, want) {
+		t.Errorf("titles after ApplyDefaultLocale = %v, want %v", titles, want)
+	}
+	if len(missing) != 1 || got[missing[0]].Id != "chinese" {
+		t.Errorf("missing = %v, want only the chinese adapter", missing)
+	}
+}
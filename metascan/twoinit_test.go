@@ -0,0 +1,14 @@
+package metascan
+
+import "testing"
+
+func TestScanMultipleInitFunctions(t *testing.T) {
+	results, entries := scanFixture(t, "twoinit", Options{})
+	wantNoDiagnostics(t, entries)
+	wantIds(t, results, "twoinit-one", "twoinit-two")
+
+	// 扫描结果按 init 函数在文件中的顺序排列
+	if len(results) == 2 && results[0].Metadata.Id != "twoinit-one" {
+		t.Errorf("results are out of source order: %s, %s", results[0].Metadata.Id, results[1].Metadata.Id)
+	}
+}
@@ -0,0 +1,42 @@
+package metascan
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScanUnresolvedFields(t *testing.T) {
+	results, entries := scanFixture(t, "unresolved", Options{})
+	wantNoDiagnostics(t, entries)
+	wantIds(t, results, "unres", "empty-ok")
+
+	byId := resultsById(t, results)
+	if got := byId["empty-ok"].Unresolved; len(got) != 0 {
+		t.Errorf("empty-ok has unresolved fields %+v, want none", got)
+	}
+
+	type field struct{ Field, Expr, Reason string }
+	var got []field
+	for _, u := range byId["unres"].Unresolved {
+		got = append(got, field{u.Field, u.Expr, u.Reason})
+		if u.Pos.Line == 0 {
+			t.Errorf("field %s has no source position", u.Field)
+		}
+	}
+	want := []field{
+		{"Title", `strings.ToUpper("unresolved")`, "a function call, which is only evaluated at run time"},
+		{"Version", "runtimeVersion", "a variable without a statically known string value"},
+		{"Author", `"Un" + os.Getenv("USER")`, "a non-constant expression"},
+		{"Description", "descs[0]", "an index expression"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unresolved fields = %+v, want %+v", got, want)
+	}
+
+	// 扫描之后补上的值不再计入 PendingFields
+	result := byId["unres"]
+	result.Metadata.Version = "1.0.0"
+	if pending := result.PendingFields(); len(pending) != 3 {
+		t.Errorf("pending fields after setting Version = %+v, want 3", pending)
+	}
+}
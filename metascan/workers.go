@@ -0,0 +1,140 @@
+package metascan
+
+import (
+	"log"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// memoryCheckInterval 开启 Options.MaxMemory 时读取内存统计的间隔
+const memoryCheckInterval = 100 * time.Millisecond
+
+// workerPool 同时运行的扫描 goroutine 数量上限，可以在扫描过程中调整
+type workerPool struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	// size 配置的 goroutine 数量，limit 当前允许同时运行的数量，active 正在运行的数量
+	size, limit, active int
+}
+
+func newWorkerPool(size int) *workerPool {
+	p := &workerPool{size: size, limit: size}
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+// acquire 等待直到正在运行的数量低于当前上限
+func (p *workerPool) acquire() {
+	p.mu.Lock()
+	for p.active >= p.limit {
+		p.cond.Wait()
+	}
+	p.active++
+	p.mu.Unlock()
+}
+
+func (p *workerPool) release() {
+	p.mu.Lock()
+	p.active--
+	p.mu.Unlock()
+	p.cond.Broadcast()
+}
+
+// setLimit 调整同时运行的上限，限制在 1 到 size 之间，返回调整后的值
+// 降低上限不会打断正在运行的扫描，只是让后续的包等待
+func (p *workerPool) setLimit(limit int) int {
+	limit = max(1, min(limit, p.size))
+	p.mu.Lock()
+	p.limit = limit
+	p.mu.Unlock()
+	p.cond.Broadcast()
+	return limit
+}
+
+func (p *workerPool) currentLimit() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.limit
+}
+
+// throttle 根据堆内存占用调整上限：达到 maxMemory 时减半，回落到 maxMemory 的四分之三以下时加倍，
+// 返回调整后的上限以及是否发生了变化
+func (p *workerPool) throttle(heapAlloc, maxMemory uint64) (int, bool) {
+	limit := p.currentLimit()
+	switch {
+	case heapAlloc >= maxMemory && limit > 1:
+		return p.setLimit(limit / 2), true
+	case heapAlloc < maxMemory/4*3 && limit < p.size:
+		return p.setLimit(limit * 2), true
+	}
+	return limit, false
+}
+
+// forEach 以最多 workers 个 goroutine 对 0 到 n-1 调用 fn，全部完成后返回
+// maxMemory 大于 0 时每隔 memoryCheckInterval 读取一次堆内存占用，接近上限时减少同时运行的 goroutine
+func forEach(n, workers int, maxMemory uint64, fn func(i int)) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, n)
+	if workers <= 1 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	pool := newWorkerPool(workers)
+	done := make(chan struct{})
+	if maxMemory > 0 {
+		go governMemory(pool, maxMemory, done)
+	}
+
+	var wg sync.WaitGroup
+	next := make(chan int)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				pool.acquire()
+				fn(i)
+				pool.release()
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	close(done)
+}
+
+// governMemory 在 done 关闭之前定期检查堆内存占用并调整 pool 的上限，每次调整都会写入日志
+// 限流会改变扫描的耗时，不属于 Options.Quiet 丢弃的信息性日志
+func governMemory(pool *workerPool, maxMemory uint64, done <-chan struct{}) {
+	ticker := time.NewTicker(memoryCheckInterval)
+	defer ticker.Stop()
+	var stats runtime.MemStats
+	for {
+		runtime.ReadMemStats(&stats)
+		if limit, changed := pool.throttle(stats.HeapAlloc, maxMemory); changed {
+			if stats.HeapAlloc >= maxMemory {
+				log.Printf("Heap use of %.1f MiB reached the memory cap of %.1f MiB, throttling the scan to %d of %d workers.", mebibytes(stats.HeapAlloc), mebibytes(maxMemory), limit, pool.size)
+			} else {
+				log.Printf("Heap use fell to %.1f MiB, raising the scan to %d of %d workers.", mebibytes(stats.HeapAlloc), limit, pool.size)
+			}
+		}
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func mebibytes(n uint64) float64 {
+	return float64(n) / (1 << 20)
+}
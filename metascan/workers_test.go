@@ -0,0 +1,124 @@
+package metascan
+
+import (
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWorkerPoolThrottle(t *testing.T) {
+	pool := newWorkerPool(8)
+	steps := []struct {
+		heap    uint64
+		limit   int
+		changed bool
+	}{
+		{100, 4, true},
+		{100, 2, true},
+		{100, 1, true},
+		{100, 1, false},
+		// 介于上限的四分之三与上限之间时保持不变
+		{80, 1, false},
+		{74, 2, true},
+		{10, 4, true},
+		{10, 8, true},
+		{10, 8, false},
+	}
+	for i, step := range steps {
+		limit, changed := pool.throttle(step.heap, 100)
+		if limit != step.limit || changed != step.changed {
+			t.Errorf("step %d: throttle(%d) = %d, %v, want %d, %v", i, step.heap, limit, changed, step.limit, step.changed)
+		}
+	}
+}
+
+func TestForEachRespectsLimit(t *testing.T) {
+	const n = 50
+	var running, peak atomic.Int32
+	var mu sync.Mutex
+	seen := make(map[int]int)
+	forEach(n, 4, 0, func(i int) {
+		cur := running.Add(1)
+		for {
+			old := peak.Load()
+			if cur <= old || peak.CompareAndSwap(old, cur) {
+				break
+			}
+		}
+		mu.Lock()
+		seen[i]++
+		mu.Unlock()
+		running.Add(-1)
+	})
+	if len(seen) != n {
+		t.Fatalf("visited %d indexes, want %d", len(seen), n)
+	}
+	for i, count := range seen {
+		if count != 1 {
+			t.Errorf("index %d visited %d times", i, count)
+		}
+	}
+	if p := peak.Load(); p > 4 {
+		t.Errorf("%d calls ran at once, want at most 4", p)
+	}
+}
+
+func TestWorkerPoolLowerLimitBlocks(t *testing.T) {
+	pool := newWorkerPool(2)
+	pool.setLimit(1)
+	pool.acquire()
+	acquired := make(chan struct{})
+	go func() {
+		pool.acquire()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("second acquire succeeded while the limit was 1")
+	default:
+	}
+	pool.setLimit(2)
+	<-acquired
+}
+
+// TestGovernMemoryLogs 检查限流的日志不受 Options.Quiet 影响，总是写入标准日志
+func TestGovernMemoryLogs(t *testing.T) {
+	var buf strings.Builder
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	pool := newWorkerPool(4)
+	done := make(chan struct{})
+	close(done)
+	governMemory(pool, 1, done)
+	if got := pool.currentLimit(); got != 2 {
+		t.Errorf("limit = %d, want 2", got)
+	}
+	if !strings.Contains(buf.String(), "throttling the scan to 2 of 4 workers") {
+		t.Errorf("log = %q, want the throttling message", buf.String())
+	}
+}
+
+func TestScanWorkersKeepOrder(t *testing.T) {
+	sequential, seqDiags := scanFixture(t, "generic", Options{Workers: 1, Trace: true})
+	parallel, parDiags := scanFixture(t, "generic", Options{Workers: 4, MaxMemory: 1 << 40, Trace: true})
+	if got, want := resultIds(parallel), resultIds(sequential); !reflect.DeepEqual(got, want) {
+		t.Errorf("parallel scan found %v, want %v in the same order", got, want)
+	}
+	if !reflect.DeepEqual(parDiags, seqDiags) {
+		t.Errorf("parallel scan reported %v, want %v", parDiags, seqDiags)
+	}
+}
+
+// resultIds 按扫描结果的顺序返回 Id
+func resultIds(results []Result) []string {
+	ids := make([]string, 0, len(results))
+	for _, result := range results {
+		ids = append(ids, result.Metadata.Id)
+	}
+	return ids
+}